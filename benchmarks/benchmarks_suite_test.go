@@ -1,17 +1,23 @@
 package benchmarks_test
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/observatorium/loki-benchmarks/internal/config"
+	"github.com/observatorium/loki-benchmarks/internal/loadclient"
 	"github.com/observatorium/loki-benchmarks/internal/metrics"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
@@ -21,6 +27,7 @@ import (
 var (
 	benchCfg      *config.Benchmark
 	k8sClient     client.Client
+	k8sClientset  kubernetes.Interface
 	metricsClient *metrics.Client
 
 	defaultRetry   = 5 * time.Second
@@ -46,6 +53,16 @@ func init() {
 		panic(fmt.Sprintf("Failed to marshal benchmark configuration file %s with errors %v", filename, err))
 	}
 
+	err = benchCfg.Metrics.ResolveJobs()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to resolve metrics jobs: %v", err))
+	}
+
+	err = benchCfg.Validate()
+	if err != nil {
+		panic(fmt.Sprintf("Invalid benchmark configuration: %v", err))
+	}
+
 	// Create K8s Client
 	cfg := k8sconfig.GetConfigOrDie()
 	mapper, err := apiutil.NewDynamicRESTMapper(cfg)
@@ -59,6 +76,12 @@ func init() {
 		panic("Failed to create new k8s client")
 	}
 
+	// The clientset proxies requests to the pods through the API server.
+	k8sClientset, err = kubernetes.NewForConfig(cfg)
+	if err != nil {
+		panic("Failed to create new k8s clientset")
+	}
+
 	// Create Metrics Client
 	promToken := os.Getenv("PROMETHEUS_TOKEN")
 	metricsClient, err = metrics.NewClient(
@@ -74,6 +97,76 @@ func init() {
 	fmt.Printf("\nUsing benchmark configuration:\n===============================\n%s\n", yamlFile)
 }
 
+// deployPodMonitors scrapes the client side metrics of the load clients in
+// the given namespaces. It is a no-op on clusters without the
+// prometheus-operator API.
+func deployPodMonitors(namespaces ...string) {
+	seen := map[string]bool{}
+
+	for _, namespace := range namespaces {
+		if seen[namespace] {
+			continue
+		}
+		seen[namespace] = true
+
+		pm := loadclient.NewPodMonitor(namespace)
+
+		err := k8sClient.Create(context.TODO(), pm, &client.CreateOptions{})
+		if meta.IsNoMatchError(err) {
+			return
+		}
+		if !apierrors.IsAlreadyExists(err) {
+			Expect(err).Should(Succeed(), "Failed to create pod monitor for load clients")
+		}
+
+		DeferCleanup(func() {
+			err := k8sClient.Delete(context.TODO(), pm, &client.DeleteOptions{})
+			Expect(client.IgnoreNotFound(err)).Should(Succeed(), "Failed to delete pod monitor")
+		})
+	}
+}
+
+// lokiHTTPPort is the HTTP port of the Loki components.
+const lokiHTTPPort = "3100"
+
+// runningPods returns the running pods matching the selector.
+func runningPods(namespace string, selector map[string]string) []corev1.Pod {
+	pods := &corev1.PodList{}
+	err := k8sClient.List(context.TODO(), pods, client.InNamespace(namespace), client.MatchingLabels(selector))
+	Expect(err).Should(Succeed(), fmt.Sprintf("Failed to list pods in %s", namespace))
+
+	var running []corev1.Pod
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodRunning {
+			running = append(running, pod)
+		}
+	}
+
+	return running
+}
+
+// terminationMessage returns the message a pod of the job wrote to its
+// termination log.
+func terminationMessage(job client.Object) ([]byte, error) {
+	pods := &corev1.PodList{}
+	err := k8sClient.List(context.TODO(), pods, client.InNamespace(job.GetNamespace()), client.MatchingLabels{"job-name": job.GetName()})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Terminated == nil || status.State.Terminated.Message == "" {
+				continue
+			}
+
+			return []byte(status.State.Terminated.Message), nil
+		}
+	}
+
+	return nil, fmt.Errorf("no termination message found for job %s", job.GetName())
+}
+
 func TestBenchmarks(t *testing.T) {
 	RegisterFailHandler(Fail)
 
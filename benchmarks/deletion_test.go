@@ -0,0 +1,183 @@
+package benchmarks_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+	"github.com/observatorium/loki-benchmarks/internal/deletion"
+	"github.com/observatorium/loki-benchmarks/internal/loadclient"
+	"github.com/observatorium/loki-benchmarks/internal/metrics"
+	"github.com/observatorium/loki-benchmarks/internal/querier"
+	"github.com/observatorium/loki-benchmarks/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gmeasure"
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Deletion", func() {
+	var (
+		deletionTest  *config.Deletion
+		samplingCfg   gmeasure.SamplingConfig
+		samplingRange model.Duration
+	)
+
+	BeforeEach(func() {
+		if !benchCfg.Scenarios.IsDeletionTestEnabled() {
+			Skip("Deletion Benchmarks not enabled")
+		}
+		deletionTest = benchCfg.Scenarios.Deletion
+
+		Expect(benchCfg.Metrics.Jobs.Compactor).ShouldNot(BeEmpty(), "Deletion Benchmarks require the compactor job")
+
+		window, err := deletionTest.BackfillRange()
+		Expect(err).Should(Succeed(), "Failed to compute backfill range")
+
+		backfillJob := loadclient.CreateBackfill(deletionTest.Backfill, window, nil, benchCfg.Generator)
+
+		err = k8sClient.Create(context.TODO(), backfillJob, &client.CreateOptions{})
+		Expect(err).Should(Succeed(), "Failed to deploy backfill job")
+
+		DeferCleanup(func() {
+			propagation := client.PropagationPolicy(metav1.DeletePropagationBackground)
+			err := k8sClient.Delete(context.TODO(), backfillJob, propagation)
+			Expect(err).Should(Succeed(), "Failed to delete backfill job")
+		})
+
+		err = utils.WaitForCompletedJob(k8sClient, backfillJob, defaultRetry, deletionTest.Backfill.WaitTimeout())
+		Expect(err).Should(Succeed(), "Failed to wait for completed backfill job")
+
+		if deletionTest.Readers == nil {
+			return
+		}
+
+		deployPodMonitors(benchCfg.Querier.Namespace)
+
+		querierObjs, err := querier.CreateQueriers(deletionTest.Readers, benchCfg.Querier)
+		Expect(err).Should(Succeed(), "Failed to create queriers")
+
+		for _, obj := range querierObjs {
+			err := k8sClient.Create(context.TODO(), obj, &client.CreateOptions{})
+			Expect(err).Should(Succeed(), "Failed to deploy querier")
+
+			obj := obj
+			DeferCleanup(func() {
+				err := k8sClient.Delete(context.TODO(), obj, &client.DeleteOptions{})
+				Expect(err).Should(Succeed(), "Failed to delete querier")
+			})
+
+			if _, ok := obj.(*appsv1.Deployment); !ok {
+				continue
+			}
+
+			err = utils.WaitForReadyDeployment(k8sClient, obj, defaultRetry, defaultTimeout)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed to wait for ready querier deployment: %s", obj.GetName()))
+		}
+	})
+
+	sample := func(e *gmeasure.Experiment) {
+		e.Sample(func(idx int) {
+			// Named Queries
+			if deletionTest.Readers != nil {
+				for _, query := range deletionTest.Readers.QueryNames() {
+					err := metricsClient.MeasureNamedQueryMetrics(e, query, samplingRange)
+					Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+				}
+			}
+
+			// Query Frontend
+			job := benchCfg.Metrics.Jobs.QueryFrontend
+			annotation := metrics.QueryFrontendAnnotation
+
+			err := metricsClient.MeasureHTTPRequestMetrics(e, metrics.ReadRequestPath, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			err = metricsClient.MeasureQueryMetrics(e, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+
+			// Compactor
+			job = benchCfg.Metrics.Jobs.Compactor
+			annotation = metrics.CompactorAnnotation
+
+			err = metricsClient.MeasureResourceUsageMetrics(e, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			err = metricsClient.MeasureCompactorMetrics(e, job, benchCfg.Metrics.Jobs.Ingester, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+		}, samplingCfg)
+	}
+
+	Describe("Deleting logs through the delete API", func() {
+		It("samples the query path and the compactor before and during the deletion", func() {
+			samplingCfg, samplingRange = deletionTest.SamplingConfiguration()
+
+			// Sleeping for the first interval so that the data is accurate for the new workload.
+			time.Sleep(samplingCfg.MinSamplingInterval)
+
+			baseline := gmeasure.NewExperiment(fmt.Sprintf("%s - baseline", deletionTest.Description))
+			AddReportEntry(baseline.Name, baseline)
+
+			sample(baseline)
+
+			deletionJob := loadclient.CreateDeletion(deletionTest, benchCfg.Generator, benchCfg.Querier)
+
+			err := k8sClient.Create(context.TODO(), deletionJob, &client.CreateOptions{})
+			Expect(err).Should(Succeed(), "Failed to deploy deletion job")
+
+			DeferCleanup(func() {
+				propagation := client.PropagationPolicy(metav1.DeletePropagationBackground)
+				err := k8sClient.Delete(context.TODO(), deletionJob, propagation)
+				Expect(err).Should(Succeed(), "Failed to delete deletion job")
+			})
+
+			deleting := gmeasure.NewExperiment(fmt.Sprintf("%s - deleting", deletionTest.Description))
+			AddReportEntry(deleting.Name, deleting)
+
+			sample(deleting)
+
+			delta := gmeasure.NewExperiment(fmt.Sprintf("%s - deletion delta", deletionTest.Description))
+			AddReportEntry(delta.Name, delta)
+
+			metrics.RecordMedianDeltas(delta, baseline, deleting, metrics.QueryFrontendAnnotation)
+			metrics.RecordMedianDeltas(delta, baseline, deleting, metrics.CompactorAnnotation)
+			if deletionTest.Readers != nil {
+				for _, query := range deletionTest.Readers.QueryNames() {
+					metrics.RecordMedianDeltas(delta, baseline, deleting, gmeasure.Annotation(query))
+				}
+			}
+
+			// The job verifies the deleted ranges after the requests are processed.
+			err = utils.WaitForCompletedJob(k8sClient, deletionJob, defaultRetry, deletionTest.WaitTimeout()+defaultTimeout)
+			Expect(err).Should(Succeed(), "Failed to wait for completed deletion job")
+
+			msg, err := terminationMessage(deletionJob)
+			Expect(err).Should(Succeed(), "Failed to read deletion summary")
+
+			summary := &deletion.Summary{}
+			err = json.Unmarshal(msg, summary)
+			Expect(err).Should(Succeed(), "Failed to decode deletion summary")
+
+			requests := gmeasure.NewExperiment(fmt.Sprintf("%s - delete requests", deletionTest.Description))
+			AddReportEntry(requests.Name, requests)
+
+			requests.RecordValue("Deleted lines", float64(summary.Lines), metrics.LinesUnit, metrics.DeletionAnnotation)
+			requests.RecordValue("Remaining lines", float64(summary.Remaining), metrics.LinesUnit, metrics.DeletionAnnotation)
+			for _, res := range summary.Results {
+				annotation := gmeasure.Annotation(res.Name)
+				requests.RecordValue("Time to processed", res.Duration, metrics.SecondsUnit, annotation)
+				requests.RecordValue("Deleted lines per request", float64(res.Lines), metrics.LinesUnit, annotation)
+				requests.RecordValue("Remaining lines per request", float64(res.Remaining), metrics.LinesUnit, annotation)
+			}
+
+			Expect(summary.Pass).Should(BeTrue(), fmt.Sprintf(
+				"Deletion failed: %d of %d requests processed, %d of %d lines remaining",
+				summary.Processed, summary.Requests, summary.Remaining, summary.Lines,
+			))
+		})
+	})
+})
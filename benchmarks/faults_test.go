@@ -0,0 +1,90 @@
+package benchmarks_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/chaos"
+	"github.com/observatorium/loki-benchmarks/internal/config"
+	"github.com/observatorium/loki-benchmarks/internal/metrics"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gmeasure"
+	"github.com/prometheus/common/model"
+)
+
+// faultRecoveryTimeout is the time a workload has to become ready again
+// after a fault.
+const faultRecoveryTimeout = 10 * time.Minute
+
+// faultRun injects the faults of a scenario while it is sampled and keeps
+// the time of every sample.
+type faultRun struct {
+	faults  []*config.Fault
+	samples []time.Time
+	events  chan []chaos.Event
+}
+
+// startFaults injects the faults at their offset from now, which should be
+// the start of the sampling.
+func startFaults(faults []*config.Fault) *faultRun {
+	run := &faultRun{faults: faults, events: make(chan []chaos.Event, 1)}
+	if len(faults) == 0 {
+		return run
+	}
+
+	injector := chaos.NewInjector(k8sClient, defaultRetry, faultRecoveryTimeout)
+
+	// Faults still injected when the spec ends, e.g. because it failed, are
+	// reverted once the injection stopped.
+	DeferCleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+		defer cancel()
+
+		err := injector.Revert(ctx)
+		Expect(err).Should(Succeed(), "Failed to revert faults")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	DeferCleanup(cancel)
+
+	start := time.Now()
+
+	go func() {
+		run.events <- injector.Run(ctx, start, faults)
+	}()
+
+	return run
+}
+
+// mark records the time of a sample. It is a no-op without faults.
+func (r *faultRun) mark() {
+	if r == nil || len(r.faults) == 0 {
+		return
+	}
+	r.samples = append(r.samples, time.Now())
+}
+
+// record waits until all workloads recovered and reports for every fault
+// its recovery time and the medians of the measurements of e before, during
+// and after the fault.
+func (r *faultRun) record(e *gmeasure.Experiment, window model.Duration) {
+	if r == nil || len(r.faults) == 0 {
+		return
+	}
+
+	events := <-r.events
+	for _, event := range events {
+		Expect(event.Err).ShouldNot(HaveOccurred(), fmt.Sprintf("Fault %s failed", event.Name))
+
+		fe := gmeasure.NewExperiment(fmt.Sprintf("%s - fault %s", e.Name, event.Name))
+		AddReportEntry(fe.Name, fe)
+
+		fe.RecordValue("Fault duration", event.End.Sub(event.Start).Seconds(), metrics.SecondsUnit, metrics.FaultAnnotation)
+		fe.RecordValue("Recovery time", event.Recovery.Seconds(), metrics.SecondsUnit, metrics.FaultAnnotation)
+
+		metrics.RecordPhaseMedians(fe, e, event.Phases(r.samples, time.Duration(window)))
+	}
+}
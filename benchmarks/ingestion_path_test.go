@@ -2,9 +2,11 @@ package benchmarks_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/observatorium/loki-benchmarks/internal/audit"
 	"github.com/observatorium/loki-benchmarks/internal/config"
 	"github.com/observatorium/loki-benchmarks/internal/loadclient"
 	"github.com/observatorium/loki-benchmarks/internal/metrics"
@@ -14,13 +16,14 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gmeasure"
 	"github.com/prometheus/common/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var _ = Describe("Ingestion Path", func() {
 	var (
 		ingestionTest *config.IngestionPath
-		generatorDpl  client.Object
+		generatorDpls []client.Object
 		samplingCfg   gmeasure.SamplingConfig
 		samplingRange model.Duration
 	)
@@ -32,23 +35,105 @@ var _ = Describe("Ingestion Path", func() {
 		ingestionTest = benchCfg.Scenarios.IngestionPath
 	})
 
-	Describe("Forwarding logs to Loki service", func() {
-		BeforeEach(func() {
-			generatorDpl = loadclient.CreateGenerator(ingestionTest.Writers, benchCfg.Generator)
+	// runAudit stops the generators, so that they push their reports, and
+	// reconciles the lines stored since start with the reports.
+	runAudit := func(start time.Time) {
+		for _, dpl := range generatorDpls {
+			propagation := client.PropagationPolicy(metav1.DeletePropagationForeground)
+			err := k8sClient.Delete(context.TODO(), dpl, propagation)
+			Expect(err).Should(Succeed(), "Failed to delete logger deployment")
+
+			err = utils.WaitForDeletedObject(k8sClient, dpl, defaultRetry, defaultTimeout)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed to wait for deleted logger deployment: %s", dpl.GetName()))
+		}
+
+		auditJob := loadclient.CreateAudit(ingestionTest.Audit, start, time.Now().Add(time.Second), benchCfg.Generator, benchCfg.Querier)
+
+		err := k8sClient.Create(context.TODO(), auditJob, &client.CreateOptions{})
+		Expect(err).Should(Succeed(), "Failed to deploy audit job")
+
+		DeferCleanup(func() {
+			propagation := client.PropagationPolicy(metav1.DeletePropagationBackground)
+			err := k8sClient.Delete(context.TODO(), auditJob, propagation)
+			Expect(err).Should(Succeed(), "Failed to delete audit job")
+		})
+
+		err = utils.WaitForCompletedJob(k8sClient, auditJob, defaultRetry, ingestionTest.Audit.WaitTimeout())
+		Expect(err).Should(Succeed(), "Failed to wait for completed audit job")
+
+		summary, err := auditSummary(auditJob)
+		Expect(err).Should(Succeed(), "Failed to read audit summary")
+
+		e := gmeasure.NewExperiment(fmt.Sprintf("%s - audit", ingestionTest.Description))
+		AddReportEntry(e.Name, e)
 
-			err := k8sClient.Create(context.TODO(), generatorDpl, &client.CreateOptions{})
-			Expect(err).Should(Succeed(), "Failed to deploy logger")
+		e.RecordValue("Expected lines", float64(summary.Expected), metrics.LinesUnit, metrics.AuditAnnotation)
+		e.RecordValue("Missing lines", float64(summary.Missing), metrics.LinesUnit, metrics.AuditAnnotation)
+		e.RecordValue("Duplicated lines", float64(summary.Duplicated), metrics.LinesUnit, metrics.AuditAnnotation)
+		e.RecordValue("Out of order lines", float64(summary.OutOfOrder), metrics.LinesUnit, metrics.AuditAnnotation)
+		for _, res := range summary.Results {
+			annotation := gmeasure.Annotation(res.Stream)
+			e.RecordValue("Missing lines per stream", float64(res.Missing), metrics.LinesUnit, annotation)
+			e.RecordValue("Duplicated lines per stream", float64(res.Duplicated), metrics.LinesUnit, annotation)
+			e.RecordValue("Out of order lines per stream", float64(res.OutOfOrder), metrics.LinesUnit, annotation)
+		}
 
-			err = utils.WaitForReadyDeployment(k8sClient, generatorDpl, defaultRetry, defaultTimeout)
-			Expect(err).Should(Succeed(), "Failed to wait for ready logger deployment")
+		Expect(summary.Streams).Should(BeNumerically(">", 0), "No generator reported its lines")
+		Expect(summary.Pass).Should(BeTrue(), fmt.Sprintf(
+			"Audit failed: %d of %d lines missing, duplicated or out of order, tolerance %g",
+			summary.Errors(), summary.Expected, summary.Tolerance,
+		))
+	}
 
-			DeferCleanup(func() {
-				err := k8sClient.Delete(context.TODO(), generatorDpl, &client.DeleteOptions{})
-				Expect(err).Should(Succeed(), "Failed to delete logger deployment")
-			})
+	Describe("Forwarding logs to Loki service", func() {
+		BeforeEach(func() {
+			if ingestionTest.IsMultiTenant() {
+				generatorDpls = loadclient.CreateTenantGenerators(ingestionTest.Tenants, ingestionTest.StructuredMetadata, benchCfg.Generator)
+			} else {
+				writers := *ingestionTest.Writers
+				writers.Audit = ingestionTest.IsAuditEnabled()
+
+				generatorDpls = []client.Object{
+					loadclient.CreateGenerator(&writers, ingestionTest.StructuredMetadata, benchCfg.Generator),
+				}
+			}
+
+			// The deletion of every generator is registered before creating it,
+			// so that the generators created before a failure are deleted too.
+			for _, dpl := range generatorDpls {
+				DeferCleanup(func(dpl client.Object) {
+					// The audit deletes the generators before the cleanup.
+					err := k8sClient.Delete(context.TODO(), dpl, &client.DeleteOptions{})
+					Expect(client.IgnoreNotFound(err)).Should(Succeed(), "Failed to delete logger deployment")
+				}, dpl)
+
+				err := k8sClient.Create(context.TODO(), dpl, &client.CreateOptions{})
+				Expect(err).Should(Succeed(), "Failed to deploy logger")
+
+				err = utils.WaitForReadyDeployment(k8sClient, dpl, defaultRetry, defaultTimeout)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed to wait for ready logger deployment: %s", dpl.GetName()))
+			}
+
+			if ingestionTest.IsProbeEnabled() {
+				deployPodMonitors(benchCfg.Generator.Namespace)
+
+				probeDpl := loadclient.CreateProbe(ingestionTest.Probe, benchCfg.Generator, benchCfg.Querier)
+
+				err := k8sClient.Create(context.TODO(), probeDpl, &client.CreateOptions{})
+				Expect(err).Should(Succeed(), "Failed to deploy probe")
+
+				DeferCleanup(func() {
+					err := k8sClient.Delete(context.TODO(), probeDpl, &client.DeleteOptions{})
+					Expect(err).Should(Succeed(), "Failed to delete probe deployment")
+				})
+
+				err = utils.WaitForReadyDeployment(k8sClient, probeDpl, defaultRetry, defaultTimeout)
+				Expect(err).Should(Succeed(), "Failed to wait for ready probe deployment")
+			}
 		})
 
 		It("samples metric data from ingestion path related components", func() {
+			start := time.Now()
 			samplingCfg, samplingRange = ingestionTest.SamplingConfiguration()
 
 			// Sleeping for the first interval so that the data is accurate for the new workload.
@@ -57,11 +142,30 @@ var _ = Describe("Ingestion Path", func() {
 			e := gmeasure.NewExperiment(ingestionTest.Description)
 			AddReportEntry(e.Name, e)
 
+			faults := startFaults(ingestionTest.Faults)
+
 			e.Sample(func(idx int) {
+				faults.mark()
+
 				// Load Generation
-				err := metricsClient.MeasureIngestionVerificationMetrics(e, generatorDpl.GetName(), samplingRange)
+				err := metricsClient.MeasureIngestionVerificationMetrics(e, loadclient.DeploymentName, samplingRange)
 				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
 
+				for _, tenant := range ingestionTest.Tenants {
+					err = metricsClient.MeasureTenantMetrics(e, tenant.Name, samplingRange)
+					Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+				}
+
+				if ingestionTest.IsProbeEnabled() {
+					err = metricsClient.MeasureProbeMetrics(e, samplingRange)
+					Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+				}
+
+				if ingestionTest.StructuredMetadata != nil {
+					err = metricsClient.MeasureStructuredMetadataMetrics(e, samplingRange)
+					Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+				}
+
 				// Distributors
 				job := benchCfg.Metrics.Jobs.Distributor
 				annotation := metrics.DistributorAnnotation
@@ -82,6 +186,27 @@ var _ = Describe("Ingestion Path", func() {
 				err = metricsClient.MeasureBoltDBShipperRequestMetrics(e, metrics.WriteRequestPath, job, samplingRange)
 				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
 			}, samplingCfg)
+
+			faults.record(e, samplingRange)
+
+			if ingestionTest.IsAuditEnabled() {
+				runAudit(start)
+			}
 		})
 	})
 })
+
+// auditSummary reads the summary the audit job wrote to the termination
+// message of its pod.
+func auditSummary(job client.Object) (*audit.Summary, error) {
+	msg, err := terminationMessage(job)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &audit.Summary{}
+	if err := json.Unmarshal(msg, summary); err != nil {
+		return nil, fmt.Errorf("failed decoding audit summary: %w", err)
+	}
+	return summary, nil
+}
@@ -0,0 +1,143 @@
+package benchmarks_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+	"github.com/observatorium/loki-benchmarks/internal/loadclient"
+	"github.com/observatorium/loki-benchmarks/internal/metrics"
+	"github.com/observatorium/loki-benchmarks/internal/querier"
+	"github.com/observatorium/loki-benchmarks/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gmeasure"
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Noisy Neighbor", func() {
+	var (
+		neighborTest  *config.NoisyNeighbor
+		samplingCfg   gmeasure.SamplingConfig
+		samplingRange model.Duration
+	)
+
+	BeforeEach(func() {
+		if !benchCfg.Scenarios.IsNoisyNeighborTestEnabled() {
+			Skip("Noisy Neighbor Benchmarks not enabled")
+		}
+		neighborTest = benchCfg.Scenarios.NoisyNeighbor
+	})
+
+	deployLoad := func(load *config.NeighborLoad) {
+		var objs []client.Object
+
+		if load.Writers != nil {
+			objs = append(objs, loadclient.CreateTenantGenerators([]*config.Tenant{load.GeneratorTenant()}, nil, benchCfg.Generator)...)
+		}
+		if load.Readers != nil {
+			queriers, err := querier.CreateQueriers(load.Readers, load.QuerierConfig(benchCfg.Querier))
+			Expect(err).Should(Succeed(), "Failed to create queriers")
+			objs = append(objs, queriers...)
+		}
+
+		// The deletion of every object is registered before creating it, so
+		// that the objects created before a failure are deleted too.
+		for _, obj := range objs {
+			DeferCleanup(func(obj client.Object) {
+				err := k8sClient.Delete(context.TODO(), obj, &client.DeleteOptions{})
+				Expect(client.IgnoreNotFound(err)).Should(Succeed(), fmt.Sprintf("Failed to delete %s", obj.GetName()))
+			}, obj)
+
+			err := k8sClient.Create(context.TODO(), obj, &client.CreateOptions{})
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed to deploy %s", obj.GetName()))
+
+			if _, ok := obj.(*appsv1.Deployment); !ok {
+				continue
+			}
+
+			err = utils.WaitForReadyDeployment(k8sClient, obj, defaultRetry, defaultTimeout)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed to wait for ready deployment: %s", obj.GetName()))
+		}
+	}
+
+	sample := func(e *gmeasure.Experiment, withAggressor bool) {
+		victim := neighborTest.Victim
+		aggressor := neighborTest.Aggressor
+
+		e.Sample(func(idx int) {
+			// Victim
+			if victim.Writers != nil {
+				err := metricsClient.MeasureClientHTTPRequestMetrics(e, metrics.WriteRequestPath, victim.Tenant, samplingRange, metrics.VictimAnnotation)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			}
+			if victim.Readers != nil {
+				err := metricsClient.MeasureClientHTTPRequestMetrics(e, metrics.ReadRequestPath, victim.Tenant, samplingRange, metrics.VictimAnnotation)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			}
+
+			err := metricsClient.MeasureTenantMetrics(e, victim.Tenant, samplingRange)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+
+			// Aggressor
+			if withAggressor {
+				err = metricsClient.MeasureTenantMetrics(e, aggressor.Tenant, samplingRange)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			}
+
+			// Distributors
+			job := benchCfg.Metrics.Jobs.Distributor
+			annotation := metrics.DistributorAnnotation
+
+			err = metricsClient.MeasureHTTPRequestMetrics(e, metrics.WriteRequestPath, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+
+			// Query Frontend
+			job = benchCfg.Metrics.Jobs.QueryFrontend
+			annotation = metrics.QueryFrontendAnnotation
+
+			err = metricsClient.MeasureHTTPRequestMetrics(e, metrics.ReadRequestPath, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			err = metricsClient.MeasureQueryMetrics(e, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+		}, samplingCfg)
+	}
+
+	Describe("Isolating a victim tenant from an aggressor tenant", func() {
+		BeforeEach(func() {
+			deployPodMonitors(benchCfg.Generator.Namespace, benchCfg.Querier.Namespace)
+
+			deployLoad(neighborTest.Victim)
+		})
+
+		It("samples the victim tenant before and during the aggressor load", func() {
+			samplingCfg, samplingRange = neighborTest.SamplingConfiguration()
+
+			// Sleeping for the first interval so that the data is accurate for the new workload.
+			time.Sleep(samplingCfg.MinSamplingInterval)
+
+			baseline := gmeasure.NewExperiment(fmt.Sprintf("%s - baseline", neighborTest.Description))
+			AddReportEntry(baseline.Name, baseline)
+
+			sample(baseline, false)
+
+			deployLoad(neighborTest.Aggressor)
+			time.Sleep(samplingCfg.MinSamplingInterval)
+
+			contention := gmeasure.NewExperiment(fmt.Sprintf("%s - aggressor", neighborTest.Description))
+			AddReportEntry(contention.Name, contention)
+
+			sample(contention, true)
+
+			delta := gmeasure.NewExperiment(fmt.Sprintf("%s - victim delta", neighborTest.Description))
+			AddReportEntry(delta.Name, delta)
+
+			metrics.RecordMedianDeltas(delta, baseline, contention, metrics.VictimAnnotation)
+			metrics.RecordMedianDeltas(delta, baseline, contention, gmeasure.Annotation(neighborTest.Victim.Tenant))
+		})
+	})
+})
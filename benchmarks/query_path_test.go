@@ -7,22 +7,31 @@ import (
 
 	"github.com/observatorium/loki-benchmarks/internal/config"
 	"github.com/observatorium/loki-benchmarks/internal/loadclient"
+	"github.com/observatorium/loki-benchmarks/internal/loki"
 	"github.com/observatorium/loki-benchmarks/internal/metrics"
 	"github.com/observatorium/loki-benchmarks/internal/querier"
+	"github.com/observatorium/loki-benchmarks/internal/querygen"
 	"github.com/observatorium/loki-benchmarks/internal/utils"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gmeasure"
 	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// backfillTolerance is the fraction of the backfill volume that must be
+// received by the distributors before the queriers start.
+const backfillTolerance = 0.95
+
 var _ = Describe("Query Path", func() {
 	var (
 		queryTest     *config.QueryPath
 		generatorDpl  client.Object
-		querierDpls   []client.Object
 		samplingCfg   gmeasure.SamplingConfig
 		samplingRange model.Duration
 	)
@@ -34,37 +43,292 @@ var _ = Describe("Query Path", func() {
 		queryTest = benchCfg.Scenarios.QueryPath
 	})
 
-	Describe("Querying logs from Loki service", func() {
-		BeforeEach(func() {
-			querierDpls = querier.CreateQueriers(queryTest.Readers, benchCfg.Querier)
-			generatorDpl = loadclient.CreateGenerator(queryTest.LogGenerator(), benchCfg.Generator)
+	backfill := func() {
+		window, err := queryTest.BackfillRange()
+		Expect(err).Should(Succeed(), "Failed to compute backfill range")
 
-			err := k8sClient.Create(context.TODO(), generatorDpl, &client.CreateOptions{})
-			Expect(err).Should(Succeed(), "Failed to deploy logger")
+		backfillJob := loadclient.CreateBackfill(queryTest.Backfill, window, queryTest.StructuredMetadata, benchCfg.Generator)
+		start := time.Now()
 
-			err = utils.WaitForReadyDeployment(k8sClient, generatorDpl, defaultRetry, defaultTimeout)
-			Expect(err).Should(Succeed(), "Failed to wait for ready logger deployment")
+		err = k8sClient.Create(context.TODO(), backfillJob, &client.CreateOptions{})
+		Expect(err).Should(Succeed(), "Failed to deploy backfill job")
 
-			// Begin loading data into the Loki service so there is something to query for.
-			time.Sleep(time.Minute * 5)
+		DeferCleanup(func() {
+			propagation := client.PropagationPolicy(metav1.DeletePropagationBackground)
+			err := k8sClient.Delete(context.TODO(), backfillJob, propagation)
+			Expect(err).Should(Succeed(), "Failed to delete backfill job")
+		})
 
-			for _, dpl := range querierDpls {
-				err := k8sClient.Create(context.TODO(), dpl, &client.CreateOptions{})
-				Expect(err).Should(Succeed(), "Failed to deploy querier")
+		err = utils.WaitForCompletedJob(k8sClient, backfillJob, defaultRetry, queryTest.Backfill.WaitTimeout())
+		Expect(err).Should(Succeed(), "Failed to wait for completed backfill job")
 
-				err = utils.WaitForReadyDeployment(k8sClient, dpl, defaultRetry, defaultTimeout)
-				Expect(err).Should(Succeed(), fmt.Sprintf("Failed to wait for ready querier deployment: %s", dpl.GetName()))
-			}
+		// The distributor counts the uncompressed bytes of every accepted line,
+		// allow for the extrapolation of increase() and late scrapes.
+		target := queryTest.Backfill.VolumeGB * backfillTolerance
+		Eventually(func() (float64, error) {
+			since := model.Duration(time.Since(start).Round(time.Second))
+			return metricsClient.Value(metrics.DistributorBytesReceivedTotal(since))
+		}, 2*time.Minute, defaultRetry).Should(BeNumerically(">=", target), "Backfill volume did not land in Loki")
+	}
+
+	deployVerification := func() {
+		deployPodMonitors(benchCfg.Querier.Namespace)
+
+		deploy := func(obj client.Object) {
+			err := k8sClient.Create(context.TODO(), obj, &client.CreateOptions{})
+			Expect(err).Should(Succeed(), "Failed to deploy verification")
 
 			DeferCleanup(func() {
-				err = k8sClient.Delete(context.TODO(), generatorDpl, &client.DeleteOptions{})
-				Expect(err).Should(Succeed(), "Failed to delete logger deployment")
+				err := k8sClient.Delete(context.TODO(), obj, &client.DeleteOptions{})
+				Expect(err).Should(Succeed(), "Failed to delete verification")
+			})
+
+			err = utils.WaitForReadyDeployment(k8sClient, obj, defaultRetry, defaultTimeout)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed to wait for ready verification deployment: %s", obj.GetName()))
+		}
+
+		deploy(loadclient.CreateDatasetGenerator(queryTest.Verification, benchCfg.Generator))
+
+		// The dataset generator pushes the lines from its start on, i.e. the
+		// dataset is complete from the time it is ready.
+		deploy(querier.CreateVerifier(queryTest.Verification, benchCfg.Querier, time.Now()))
+	}
+
+	deployGenerator := func() {
+		if queryTest.IsBackfillEnabled() {
+			backfill()
+		}
+
+		generatorDpl = loadclient.CreateGenerator(queryTest.LogGenerator(), queryTest.StructuredMetadata, benchCfg.Generator)
+
+		err := k8sClient.Create(context.TODO(), generatorDpl, &client.CreateOptions{})
+		Expect(err).Should(Succeed(), "Failed to deploy logger")
+
+		err = utils.WaitForReadyDeployment(k8sClient, generatorDpl, defaultRetry, defaultTimeout)
+		Expect(err).Should(Succeed(), "Failed to wait for ready logger deployment")
+
+		DeferCleanup(func() {
+			err := k8sClient.Delete(context.TODO(), generatorDpl, &client.DeleteOptions{})
+			Expect(err).Should(Succeed(), "Failed to delete logger deployment")
+		})
+
+		if queryTest.IsVerificationEnabled() {
+			deployVerification()
+		}
+
+		// Begin loading data into the Loki service so there is something to query for.
+		time.Sleep(time.Minute * 5)
+	}
+
+	deleteQueriers := func(objs []client.Object) {
+		for _, obj := range objs {
+			err := k8sClient.Delete(context.TODO(), obj, &client.DeleteOptions{})
+			Expect(client.IgnoreNotFound(err)).Should(Succeed(), "Failed to delete querier")
+		}
+	}
+
+	// deployQueriers registers the deletion of the queriers before creating
+	// them, so that they are deleted when a spec fails. Specs running several
+	// phases delete them earlier to free the path for the next phase.
+	deployQueriers := func(reader *config.Reader) []client.Object {
+		deployPodMonitors(benchCfg.Querier.Namespace)
+
+		querierObjs, err := querier.CreateQueriers(reader, benchCfg.Querier)
+		Expect(err).Should(Succeed(), "Failed to create queriers")
+
+		DeferCleanup(deleteQueriers, querierObjs)
+
+		for _, obj := range querierObjs {
+			err := k8sClient.Create(context.TODO(), obj, &client.CreateOptions{})
+			Expect(err).Should(Succeed(), "Failed to deploy querier")
+
+			if _, ok := obj.(*appsv1.Deployment); !ok {
+				continue
+			}
+
+			err = utils.WaitForReadyDeployment(k8sClient, obj, defaultRetry, defaultTimeout)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed to wait for ready querier deployment: %s", obj.GetName()))
+		}
+
+		return querierObjs
+	}
+
+	// sample measures the query path every sampling interval. The optional
+	// beforeEach runs before every sample, e.g. to flush the caches, and
+	// afterEach after every sample.
+	sample := func(e *gmeasure.Experiment, reader *config.Reader, beforeEach, afterEach func()) {
+		cfg := samplingCfg
+		if beforeEach != nil {
+			// The hook delays every sample, only the number of samples counts.
+			cfg.Duration = 0
+		}
+
+		e.Sample(func(idx int) {
+			if beforeEach != nil {
+				beforeEach()
+			}
+
+			// Load Generation
+			err := metricsClient.MeasureLoadQuerierMetrics(e, samplingRange)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			err = metricsClient.MeasureIngestionVerificationMetrics(e, generatorDpl.GetName(), samplingRange)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
 
-				for _, dpl := range querierDpls {
-					err := k8sClient.Delete(context.TODO(), dpl, &client.DeleteOptions{})
-					Expect(err).Should(Succeed(), "Failed to delete querier deployment")
+			// Correctness
+			if queryTest.IsVerificationEnabled() {
+				err = metricsClient.MeasureVerificationMetrics(e, querygen.Checks, samplingRange)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			}
+
+			// Named Queries
+			for _, query := range reader.QueryNames() {
+				err = metricsClient.MeasureNamedQueryMetrics(e, query, samplingRange)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			}
+
+			if stats := queryTest.QueryStats; stats != nil {
+				summaries := queryLogs(stats.Namespace, stats.Selector, samplingRange)
+				for _, query := range reader.QueryNames() {
+					metrics.RecordQueryLogMetrics(e, query, summaries[loki.QueryTag(query)], samplingRange)
 				}
+			}
+
+			// Query Frontend
+			job := benchCfg.Metrics.Jobs.QueryFrontend
+			annotation := metrics.QueryFrontendAnnotation
+
+			err = metricsClient.MeasureResourceUsageMetrics(e, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			err = metricsClient.MeasureHTTPRequestMetrics(e, metrics.ReadRequestPath, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			err = metricsClient.MeasureQueryMetrics(e, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			err = metricsClient.MeasureQueryMetricsByType(e, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			err = metricsClient.MeasureQuerySplittingMetrics(e, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+
+			if reader.UsesReadAPI() {
+				err = metricsClient.MeasureReadAPIMetrics(e, job, samplingRange, annotation)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+				err = metricsClient.MeasureClientReadAPIMetrics(e, benchCfg.Querier.Tenant, samplingRange, metrics.LogQLAnnotation)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			}
+
+			// Query Scheduler
+			if job = benchCfg.Metrics.Jobs.QueryScheduler; job != "" {
+				annotation = metrics.QuerySchedulerAnnotation
+
+				err = metricsClient.MeasureResourceUsageMetrics(e, job, samplingRange, annotation)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+				err = metricsClient.MeasureQuerySchedulerMetrics(e, job, samplingRange, annotation)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			}
+
+			// Querier
+			job = benchCfg.Metrics.Jobs.Querier
+			annotation = metrics.QuerierAnnotation
+
+			err = metricsClient.MeasureResourceUsageMetrics(e, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			err = metricsClient.MeasureHTTPRequestMetrics(e, metrics.ReadRequestPath, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			err = metricsClient.MeasureQueryMetrics(e, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			err = metricsClient.MeasureQueryMetricsByType(e, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+
+			// Index Gateway
+			job = benchCfg.Metrics.Jobs.IndexGateway
+			annotation = metrics.IndexGatewayAnnotation
+
+			err = metricsClient.MeasureResourceUsageMetrics(e, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			err = metricsClient.MeasureVolumeUsageMetrics(e, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+
+			// Ingesters
+			job = benchCfg.Metrics.Jobs.Ingester
+			annotation = metrics.IngesterAnnotation
+
+			err = metricsClient.MeasureResourceUsageMetrics(e, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			err = metricsClient.MeasureGRPCRequestMetrics(e, metrics.ReadRequestPath, job, samplingRange, annotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			err = metricsClient.MeasureBoltDBShipperRequestMetrics(e, metrics.ReadRequestPath, job, samplingRange)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+
+			if afterEach != nil {
+				afterEach()
+			}
+		}, cfg)
+	}
+
+	// scaleQueriers scales the querier deployments to zero, or back to the
+	// replicas they were created with.
+	scaleQueriers := func(objs []client.Object, up bool) {
+		for _, obj := range objs {
+			dpl, ok := obj.(*appsv1.Deployment)
+			if !ok {
+				continue
+			}
+
+			var replicas int32
+			if up {
+				replicas = *dpl.Spec.Replicas
+			}
+
+			err := utils.ScaleDeployment(k8sClient, dpl, replicas, defaultRetry, defaultTimeout)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed to scale querier deployment: %s", dpl.GetName()))
+		}
+	}
+
+	flushCaches := func() {
+		cache := queryTest.Cache
+		err := utils.RestartPods(k8sClient, cache.Namespace, cache.Selector, defaultRetry, defaultTimeout)
+		Expect(err).Should(Succeed(), "Failed to restart cache pods")
+	}
+
+	// primeCaches sends every query of the reader once and waits until all
+	// of them returned.
+	primeCaches := func(reader *config.Reader) {
+		primerObjs, err := querier.CreatePrimers(reader, benchCfg.Querier)
+		Expect(err).Should(Succeed(), "Failed to create primers")
+
+		for _, obj := range primerObjs {
+			obj := obj
+
+			err := k8sClient.Create(context.TODO(), obj, &client.CreateOptions{})
+			Expect(err).Should(Succeed(), "Failed to deploy primer")
+
+			DeferCleanup(func() {
+				propagation := client.PropagationPolicy(metav1.DeletePropagationBackground)
+				err := k8sClient.Delete(context.TODO(), obj, propagation)
+				Expect(err).Should(Succeed(), "Failed to delete primer")
 			})
+		}
+
+		for _, obj := range primerObjs {
+			if _, ok := obj.(*batchv1.Job); !ok {
+				continue
+			}
+
+			err = utils.WaitForCompletedJob(k8sClient, obj, defaultRetry, queryTest.Cache.PrimingWaitTimeout())
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed to wait for completed primer job: %s", obj.GetName()))
+		}
+	}
+
+	Describe("Querying logs from Loki service", func() {
+		BeforeEach(func() {
+			if queryTest.IsStructuredMetadataEnabled() {
+				Skip("Covered by the structured metadata filter comparison")
+			}
+			if queryTest.IsCacheComparisonEnabled() {
+				Skip("Covered by the cold and warm cache comparison")
+			}
+
+			deployGenerator()
+			deployQueriers(queryTest.Readers)
 		})
 
 		It("samples metric data from query path related components", func() {
@@ -76,55 +340,169 @@ var _ = Describe("Query Path", func() {
 			e := gmeasure.NewExperiment(queryTest.Description)
 			AddReportEntry(e.Name, e)
 
-			e.Sample(func(idx int) {
-				// Load Generation
-				err := metricsClient.MeasureLoadQuerierMetrics(e, samplingRange)
-				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
-				err = metricsClient.MeasureIngestionVerificationMetrics(e, generatorDpl.GetName(), samplingRange)
-				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			faults := startFaults(queryTest.Faults)
+			sample(e, queryTest.Readers, nil, faults.mark)
+			faults.record(e, samplingRange)
+		})
+	})
 
-				// Query Frontend
-				job := benchCfg.Metrics.Jobs.QueryFrontend
-				annotation := metrics.QueryFrontendAnnotation
+	Describe("Filtering logs on structured metadata", func() {
+		BeforeEach(func() {
+			if !queryTest.IsStructuredMetadataEnabled() {
+				Skip("Structured metadata filters not configured")
+			}
 
-				err = metricsClient.MeasureResourceUsageMetrics(e, job, samplingRange, annotation)
-				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
-				err = metricsClient.MeasureHTTPRequestMetrics(e, metrics.ReadRequestPath, job, samplingRange, annotation)
-				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
-				err = metricsClient.MeasureQueryMetrics(e, job, samplingRange, annotation)
-				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			deployGenerator()
+		})
 
-				// Querier
-				job = benchCfg.Metrics.Jobs.Querier
-				annotation = metrics.QuerierAnnotation
+		It("samples line filters and structured metadata filters one after the other", func() {
+			samplingCfg, samplingRange = queryTest.SamplingConfiguration()
 
-				err = metricsClient.MeasureResourceUsageMetrics(e, job, samplingRange, annotation)
-				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
-				err = metricsClient.MeasureHTTPRequestMetrics(e, metrics.ReadRequestPath, job, samplingRange, annotation)
-				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
-				err = metricsClient.MeasureQueryMetrics(e, job, samplingRange, annotation)
-				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			// The same load shape, but only the structured metadata filters.
+			metadataReader := *queryTest.Readers
+			metadataReader.Queries = queryTest.StructuredMetadata.Queries
+			metadataReader.Mix = nil
 
-				// Index Gateway
-				job = benchCfg.Metrics.Jobs.IndexGateway
-				annotation = metrics.IndexGatewayAnnotation
+			phases := []struct {
+				name   string
+				reader *config.Reader
+			}{
+				{
+					name:   "line filters",
+					reader: queryTest.Readers,
+				},
+				{
+					name:   "structured metadata filters",
+					reader: &metadataReader,
+				},
+			}
 
-				err = metricsClient.MeasureResourceUsageMetrics(e, job, samplingRange, annotation)
-				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
-				err = metricsClient.MeasureVolumeUsageMetrics(e, job, samplingRange, annotation)
-				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			experiments := map[string]*gmeasure.Experiment{}
+			for _, phase := range phases {
+				querierObjs := deployQueriers(phase.reader)
 
-				// Ingesters
-				job = benchCfg.Metrics.Jobs.Ingester
-				annotation = metrics.IngesterAnnotation
+				// Sleeping for the first interval so that the data is accurate for the new workload.
+				time.Sleep(samplingCfg.MinSamplingInterval)
 
-				err = metricsClient.MeasureResourceUsageMetrics(e, job, samplingRange, annotation)
-				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
-				err = metricsClient.MeasureGRPCRequestMetrics(e, metrics.ReadRequestPath, job, samplingRange, annotation)
-				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
-				err = metricsClient.MeasureBoltDBShipperRequestMetrics(e, metrics.ReadRequestPath, job, samplingRange)
-				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
-			}, samplingCfg)
+				e := gmeasure.NewExperiment(fmt.Sprintf("%s - %s", queryTest.Description, phase.name))
+				AddReportEntry(e.Name, e)
+
+				sample(e, phase.reader, nil, nil)
+				deleteQueriers(querierObjs)
+
+				experiments[phase.name] = e
+			}
+
+			// Both phases name a query by the same id.
+			side := gmeasure.NewExperiment(fmt.Sprintf("%s - filters", queryTest.Description))
+			AddReportEntry(side.Name, side)
+
+			for _, query := range metadataReader.QueryNames() {
+				for _, phase := range phases {
+					metrics.RecordMedians(side, experiments[phase.name], gmeasure.Annotation(query), phase.name)
+				}
+			}
+		})
+	})
+
+	Describe("Comparing cold and warm caches", func() {
+		BeforeEach(func() {
+			if !queryTest.IsCacheComparisonEnabled() {
+				Skip("Cache modes not configured")
+			}
+
+			deployGenerator()
+		})
+
+		It("samples the queries with cold and with warm caches", func() {
+			samplingCfg, samplingRange = queryTest.SamplingConfiguration()
+			cache := queryTest.Cache
+
+			experiments := map[string]*gmeasure.Experiment{}
+			for _, mode := range cache.CacheModes() {
+				reader := *queryTest.Readers
+
+				var (
+					querierObjs []client.Object
+					beforeEach  func()
+				)
+				switch mode {
+				case config.CacheModeCold:
+					if cache.Bust == config.CacheBustJitter {
+						reader.Jitter = cache.JitterRange()
+					} else {
+						Expect(cache.Selector).ShouldNot(BeEmpty(), "Restarting the caches requires a pod selector")
+
+						// Every sample measures a full interval after a flush, the
+						// restart of the caches stays out of the measured window.
+						// The queriers stop across the flush, so that no query
+						// refills the caches before the interval starts.
+						beforeEach = func() {
+							scaleQueriers(querierObjs, false)
+							flushCaches()
+							scaleQueriers(querierObjs, true)
+							time.Sleep(samplingCfg.MinSamplingInterval)
+						}
+					}
+				case config.CacheModeWarm:
+					primeCaches(&reader)
+				default:
+					Fail(fmt.Sprintf("Unsupported cache mode: %s", mode))
+				}
+
+				querierObjs = deployQueriers(&reader)
+
+				if beforeEach == nil {
+					// Sleeping for the first interval so that the data is accurate for the new workload.
+					time.Sleep(samplingCfg.MinSamplingInterval)
+				}
+
+				e := gmeasure.NewExperiment(fmt.Sprintf("%s - %s cache", queryTest.Description, mode))
+				AddReportEntry(e.Name, e)
+
+				sample(e, &reader, beforeEach, nil)
+				deleteQueriers(querierObjs)
+
+				experiments[mode] = e
+			}
+
+			side := gmeasure.NewExperiment(fmt.Sprintf("%s - cache modes", queryTest.Description))
+			AddReportEntry(side.Name, side)
+
+			for _, query := range queryTest.Readers.QueryNames() {
+				for _, mode := range cache.CacheModes() {
+					metrics.RecordMedians(side, experiments[mode], gmeasure.Annotation(query), mode)
+				}
+			}
 		})
 	})
 })
+
+// queryLogs aggregates the query statistics the query frontend pods logged
+// over the sample range by the source of the queries.
+func queryLogs(namespace string, selector map[string]string, sampleRange model.Duration) map[string]*loki.QueryLogSummary {
+	pods := runningPods(namespace, selector)
+	Expect(pods).ShouldNot(BeEmpty(), fmt.Sprintf("No running query frontend pod in %s", namespace))
+
+	since := int64(time.Duration(sampleRange).Seconds())
+	summaries := map[string]*loki.QueryLogSummary{}
+
+	for _, pod := range pods {
+		logs, err := k8sClientset.CoreV1().Pods(namespace).GetLogs(pod.Name, &corev1.PodLogOptions{SinceSeconds: &since}).Stream(context.TODO())
+		Expect(err).Should(Succeed(), fmt.Sprintf("Failed to get the logs of %s", pod.Name))
+
+		podSummaries, err := loki.SummarizeQueryLogs(logs)
+		logs.Close()
+		Expect(err).Should(Succeed(), fmt.Sprintf("Failed to read the logs of %s", pod.Name))
+
+		for source, summary := range podSummaries {
+			if s, ok := summaries[source]; ok {
+				s.Merge(summary)
+				continue
+			}
+			summaries[source] = summary
+		}
+	}
+
+	return summaries
+}
@@ -0,0 +1,159 @@
+package benchmarks_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+	"github.com/observatorium/loki-benchmarks/internal/loadclient"
+	"github.com/observatorium/loki-benchmarks/internal/metrics"
+	"github.com/observatorium/loki-benchmarks/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gmeasure"
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// restartScrapeDelay leaves Prometheus time to scrape the restarted
+// ingesters before the restart window is measured.
+const restartScrapeDelay = time.Minute
+
+var _ = Describe("Ingester Restart", func() {
+	var (
+		restartTest   *config.Restart
+		generatorDpl  client.Object
+		samplingCfg   gmeasure.SamplingConfig
+		samplingRange model.Duration
+	)
+
+	BeforeEach(func() {
+		if !benchCfg.Scenarios.IsRestartTestEnabled() {
+			Skip("Ingester Restart Benchmarks not enabled")
+		}
+		restartTest = benchCfg.Scenarios.Restart
+
+		generatorDpl = loadclient.CreateGenerator(restartTest.Writers, nil, benchCfg.Generator)
+
+		err := k8sClient.Create(context.TODO(), generatorDpl, &client.CreateOptions{})
+		Expect(err).Should(Succeed(), "Failed to deploy logger")
+
+		DeferCleanup(func() {
+			err := k8sClient.Delete(context.TODO(), generatorDpl, &client.DeleteOptions{})
+			Expect(err).Should(Succeed(), "Failed to delete logger deployment")
+		})
+
+		err = utils.WaitForReadyDeployment(k8sClient, generatorDpl, defaultRetry, defaultTimeout)
+		Expect(err).Should(Succeed(), "Failed to wait for ready logger deployment")
+	})
+
+	// measurePush records the push latency and errors of the distributors
+	// and the streams and resource usage of the ingesters.
+	measurePush := func(e *gmeasure.Experiment, sampleRange model.Duration) {
+		// Distributors
+		job := benchCfg.Metrics.Jobs.Distributor
+		annotation := metrics.DistributorAnnotation
+
+		err := metricsClient.MeasureHTTPRequestMetrics(e, metrics.WriteRequestPath, job, sampleRange, annotation)
+		Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+		err = metricsClient.MeasurePushErrorMetrics(e, job, sampleRange, annotation)
+		Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+
+		// Ingesters
+		job = benchCfg.Metrics.Jobs.Ingester
+		annotation = metrics.IngesterAnnotation
+
+		err = metricsClient.MeasureResourceUsageMetrics(e, job, sampleRange, annotation)
+		Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+		err = metricsClient.Measure(e, metrics.IngesterStreams(job, annotation))
+		Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+	}
+
+	sample := func(e *gmeasure.Experiment) {
+		e.Sample(func(idx int) {
+			// Load Generation
+			err := metricsClient.MeasureIngestionVerificationMetrics(e, generatorDpl.GetName(), samplingRange)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+
+			measurePush(e, samplingRange)
+		}, samplingCfg)
+	}
+
+	// restart restarts the ingesters and returns once all of them are ready.
+	restart := func(sts *appsv1.StatefulSet) {
+		err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(sts), sts)
+		Expect(err).Should(Succeed(), "Failed to get ingester statefulset")
+
+		switch strategy := restartTest.RestartStrategy(); strategy {
+		case config.RestartRolling:
+			err = utils.RolloutRestart(k8sClient, sts)
+			Expect(err).Should(Succeed(), "Failed to restart ingesters")
+		case config.RestartAllAtOnce:
+			err = utils.RestartPods(k8sClient, sts.Namespace, sts.Spec.Selector.MatchLabels, defaultRetry, restartTest.WaitTimeout())
+			Expect(err).Should(Succeed(), "Failed to restart ingesters")
+		default:
+			Fail(fmt.Sprintf("Unsupported restart strategy: %s", strategy))
+		}
+
+		err = utils.WaitForReadyDeployment(k8sClient, sts, defaultRetry, restartTest.WaitTimeout())
+		Expect(err).Should(Succeed(), "Failed to wait for ready ingester statefulset")
+	}
+
+	Describe("Restarting the ingesters", func() {
+		It("samples the write path before, during and after the restart", func() {
+			samplingCfg, samplingRange = restartTest.SamplingConfiguration()
+
+			// Sleeping for the first interval so that the data is accurate for the new workload.
+			time.Sleep(samplingCfg.MinSamplingInterval)
+
+			before := gmeasure.NewExperiment(fmt.Sprintf("%s - before restart", restartTest.Description))
+			AddReportEntry(before.Name, before)
+
+			sample(before)
+
+			sts := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      restartTest.StatefulSet,
+					Namespace: restartTest.Namespace,
+				},
+			}
+
+			start := time.Now()
+			restart(sts)
+			readyAt := time.Now()
+			ready := readyAt.Sub(start)
+
+			e := gmeasure.NewExperiment(fmt.Sprintf("%s - restart", restartTest.Description))
+			AddReportEntry(e.Name, e)
+
+			e.RecordValue("Time to ready", ready.Seconds(), metrics.SecondsUnit, metrics.RestartAnnotation)
+
+			// The restart window covers the restart up to now.
+			time.Sleep(restartScrapeDelay)
+			window := model.Duration(time.Since(start).Round(time.Second))
+
+			measurePush(e, window)
+
+			// The replay metrics reset with the restart, only the samples since
+			// all ingesters are ready belong to the restarted processes.
+			replayed := model.Duration(time.Since(readyAt).Round(time.Second))
+			err := metricsClient.MeasureWALReplayMetrics(e, benchCfg.Metrics.Jobs.Ingester, replayed, metrics.IngesterAnnotation)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+
+			after := gmeasure.NewExperiment(fmt.Sprintf("%s - after restart", restartTest.Description))
+			AddReportEntry(after.Name, after)
+
+			sample(after)
+
+			delta := gmeasure.NewExperiment(fmt.Sprintf("%s - restart delta", restartTest.Description))
+			AddReportEntry(delta.Name, delta)
+
+			metrics.RecordMedianDeltas(delta, before, after, metrics.DistributorAnnotation)
+			metrics.RecordMedianDeltas(delta, before, after, metrics.IngesterAnnotation)
+		})
+	})
+})
@@ -0,0 +1,137 @@
+package benchmarks_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+	"github.com/observatorium/loki-benchmarks/internal/loadclient"
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+	"github.com/observatorium/loki-benchmarks/internal/metrics"
+	"github.com/observatorium/loki-benchmarks/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gmeasure"
+	"github.com/prometheus/common/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Retention", func() {
+	var (
+		retentionTest *config.Retention
+		generatorDpl  client.Object
+		samplingCfg   gmeasure.SamplingConfig
+		samplingRange model.Duration
+	)
+
+	BeforeEach(func() {
+		if !benchCfg.Scenarios.IsRetentionTestEnabled() {
+			Skip("Retention Benchmarks not enabled")
+		}
+		retentionTest = benchCfg.Scenarios.Retention
+
+		Expect(benchCfg.Metrics.Jobs.Compactor).ShouldNot(BeEmpty(), "Retention Benchmarks require the compactor job")
+
+		// Only lines older than the retention of Loki are expired, the
+		// backfill is spread around Period.
+		retention := compactorRetention(retentionTest.Namespace, retentionTest.Selector, benchCfg.Generator.Tenant)
+		Expect(retention.Enabled).Should(BeTrue(), "Retention Benchmarks require retention_enabled on the compactor")
+		Expect(retention.Period).Should(Equal(retentionTest.Period), fmt.Sprintf(
+			"Loki applies a retention period of %s to tenant %s, the scenario expects %s",
+			retention.Period, benchCfg.Generator.Tenant, retentionTest.Period,
+		))
+
+		window, err := retentionTest.BackfillRange()
+		Expect(err).Should(Succeed(), "Failed to compute backfill range")
+
+		// The lines older than the retention period are expired as soon as
+		// the compactor applies retention to their index tables.
+		backfillJob := loadclient.CreateBackfill(retentionTest.Backfill, window, nil, benchCfg.Generator)
+
+		err = k8sClient.Create(context.TODO(), backfillJob, &client.CreateOptions{})
+		Expect(err).Should(Succeed(), "Failed to deploy backfill job")
+
+		DeferCleanup(func() {
+			propagation := client.PropagationPolicy(metav1.DeletePropagationBackground)
+			err := k8sClient.Delete(context.TODO(), backfillJob, propagation)
+			Expect(err).Should(Succeed(), "Failed to delete backfill job")
+		})
+
+		err = utils.WaitForCompletedJob(k8sClient, backfillJob, defaultRetry, retentionTest.Backfill.WaitTimeout())
+		Expect(err).Should(Succeed(), "Failed to wait for completed backfill job")
+
+		generatorDpl = loadclient.CreateGenerator(retentionTest.Writers, nil, benchCfg.Generator)
+
+		err = k8sClient.Create(context.TODO(), generatorDpl, &client.CreateOptions{})
+		Expect(err).Should(Succeed(), "Failed to deploy logger")
+
+		DeferCleanup(func() {
+			err := k8sClient.Delete(context.TODO(), generatorDpl, &client.DeleteOptions{})
+			Expect(err).Should(Succeed(), "Failed to delete logger deployment")
+		})
+
+		err = utils.WaitForReadyDeployment(k8sClient, generatorDpl, defaultRetry, defaultTimeout)
+		Expect(err).Should(Succeed(), "Failed to wait for ready logger deployment")
+	})
+
+	Describe("Expiring logs with the compactor", func() {
+		It("samples metric data from the compactor while it applies retention", func() {
+			samplingCfg, samplingRange = retentionTest.SamplingConfiguration()
+
+			// Sleeping for the first interval so that the data is accurate for the new workload.
+			time.Sleep(samplingCfg.MinSamplingInterval)
+
+			e := gmeasure.NewExperiment(retentionTest.Description)
+			AddReportEntry(e.Name, e)
+
+			e.Sample(func(idx int) {
+				// Load Generation
+				err := metricsClient.MeasureIngestionVerificationMetrics(e, generatorDpl.GetName(), samplingRange)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+
+				// Compactor
+				job := benchCfg.Metrics.Jobs.Compactor
+				annotation := metrics.CompactorAnnotation
+
+				err = metricsClient.MeasureResourceUsageMetrics(e, job, samplingRange, annotation)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+				err = metricsClient.MeasureCompactorMetrics(e, job, benchCfg.Metrics.Jobs.Ingester, samplingRange, annotation)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+
+				if query := retentionTest.ObjectStorageQuery; query != "" {
+					err = metricsClient.Measure(e, metrics.ObjectStorageUsedBytes(query, annotation))
+					Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+				}
+
+				// Ingesters
+				job = benchCfg.Metrics.Jobs.Ingester
+				annotation = metrics.IngesterAnnotation
+
+				err = metricsClient.MeasureResourceUsageMetrics(e, job, samplingRange, annotation)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			}, samplingCfg)
+		})
+	})
+})
+
+// compactorRetention reads the retention of the tenant from the config
+// endpoints of a compactor pod, through the API server.
+func compactorRetention(namespace string, selector map[string]string, tenant string) *loki.Retention {
+	pods := runningPods(namespace, selector)
+	Expect(pods).ShouldNot(BeEmpty(), fmt.Sprintf("No running compactor pod in %s", namespace))
+	pod := pods[0].Name
+
+	get := func(path string) []byte {
+		data, err := k8sClientset.CoreV1().Pods(namespace).ProxyGet("http", pod, lokiHTTPPort, path, nil).DoRaw(context.TODO())
+		Expect(err).Should(Succeed(), fmt.Sprintf("Failed to get %s of %s", path, pod))
+		return data
+	}
+
+	retention, err := loki.ParseRetention(get(loki.ConfigPath), get(loki.RuntimeConfigPath), tenant)
+	Expect(err).Should(Succeed(), "Failed to read the retention of the compactor")
+
+	return retention
+}
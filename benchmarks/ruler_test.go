@@ -0,0 +1,110 @@
+package benchmarks_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+	"github.com/observatorium/loki-benchmarks/internal/loadclient"
+	"github.com/observatorium/loki-benchmarks/internal/metrics"
+	"github.com/observatorium/loki-benchmarks/internal/querier"
+	"github.com/observatorium/loki-benchmarks/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gmeasure"
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Ruler", func() {
+	var (
+		rulerTest     *config.Ruler
+		generatorDpl  client.Object
+		samplingCfg   gmeasure.SamplingConfig
+		samplingRange model.Duration
+	)
+
+	BeforeEach(func() {
+		if !benchCfg.Scenarios.IsRulerTestEnabled() {
+			Skip("Ruler Benchmarks not enabled")
+		}
+		rulerTest = benchCfg.Scenarios.Ruler
+
+		Expect(benchCfg.Metrics.Jobs.Ruler).ShouldNot(BeEmpty(), "Ruler Benchmarks require the ruler job")
+
+		generatorDpl = loadclient.CreateGenerator(rulerTest.LogGenerator(), nil, benchCfg.Generator)
+
+		err := k8sClient.Create(context.TODO(), generatorDpl, &client.CreateOptions{})
+		Expect(err).Should(Succeed(), "Failed to deploy logger")
+
+		DeferCleanup(func() {
+			err := k8sClient.Delete(context.TODO(), generatorDpl, &client.DeleteOptions{})
+			Expect(err).Should(Succeed(), "Failed to delete logger deployment")
+		})
+
+		err = utils.WaitForReadyDeployment(k8sClient, generatorDpl, defaultRetry, defaultTimeout)
+		Expect(err).Should(Succeed(), "Failed to wait for ready logger deployment")
+
+		// The rule templates are filled in with the label values of the
+		// generated streams.
+		time.Sleep(time.Minute)
+
+		loaderObjs, err := querier.CreateRuleLoader(rulerTest, benchCfg.Querier)
+		Expect(err).Should(Succeed(), "Failed to create rule loader")
+
+		for _, obj := range loaderObjs {
+			err := k8sClient.Create(context.TODO(), obj, &client.CreateOptions{})
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed to deploy %s", obj.GetName()))
+
+			obj := obj
+			DeferCleanup(func() {
+				err := k8sClient.Delete(context.TODO(), obj, &client.DeleteOptions{})
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed to delete %s", obj.GetName()))
+			})
+
+			if _, ok := obj.(*appsv1.Deployment); !ok {
+				continue
+			}
+
+			err = utils.WaitForReadyDeployment(k8sClient, obj, defaultRetry, defaultTimeout)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed to wait for ready rule loader deployment: %s", obj.GetName()))
+		}
+	})
+
+	Describe("Evaluating rules with the ruler", func() {
+		It("samples metric data from the ruler", func() {
+			samplingCfg, samplingRange = rulerTest.SamplingConfiguration()
+
+			// Sleeping for the first interval so that the data is accurate for the new workload.
+			time.Sleep(samplingCfg.MinSamplingInterval)
+
+			e := gmeasure.NewExperiment(rulerTest.Description)
+			AddReportEntry(e.Name, e)
+
+			e.Sample(func(idx int) {
+				// Load Generation
+				err := metricsClient.MeasureIngestionVerificationMetrics(e, generatorDpl.GetName(), samplingRange)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+
+				// Ruler
+				job := benchCfg.Metrics.Jobs.Ruler
+				annotation := metrics.RulerAnnotation
+
+				err = metricsClient.MeasureResourceUsageMetrics(e, job, samplingRange, annotation)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+				err = metricsClient.MeasureRulerMetrics(e, job, samplingRange, annotation)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+
+				// Ingesters
+				job = benchCfg.Metrics.Jobs.Ingester
+				annotation = metrics.IngesterAnnotation
+
+				err = metricsClient.MeasureResourceUsageMetrics(e, job, samplingRange, annotation)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			}, samplingCfg)
+		})
+	})
+})
@@ -0,0 +1,113 @@
+package benchmarks_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+	"github.com/observatorium/loki-benchmarks/internal/loadclient"
+	"github.com/observatorium/loki-benchmarks/internal/metrics"
+	"github.com/observatorium/loki-benchmarks/internal/querier"
+	"github.com/observatorium/loki-benchmarks/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gmeasure"
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Tail Path", func() {
+	var (
+		tailTest      *config.TailPath
+		generatorDpl  client.Object
+		samplingCfg   gmeasure.SamplingConfig
+		samplingRange model.Duration
+	)
+
+	BeforeEach(func() {
+		if !benchCfg.Scenarios.IsTailTestEnabled() {
+			Skip("Tail Path Benchmarks not enabled")
+		}
+		tailTest = benchCfg.Scenarios.TailPath
+
+		generatorDpl = loadclient.CreateGenerator(tailTest.LogGenerator(), nil, benchCfg.Generator)
+
+		err := k8sClient.Create(context.TODO(), generatorDpl, &client.CreateOptions{})
+		Expect(err).Should(Succeed(), "Failed to deploy logger")
+
+		DeferCleanup(func() {
+			err := k8sClient.Delete(context.TODO(), generatorDpl, &client.DeleteOptions{})
+			Expect(err).Should(Succeed(), "Failed to delete logger deployment")
+		})
+
+		err = utils.WaitForReadyDeployment(k8sClient, generatorDpl, defaultRetry, defaultTimeout)
+		Expect(err).Should(Succeed(), "Failed to wait for ready logger deployment")
+
+		deployPodMonitors(benchCfg.Querier.Namespace)
+
+		tailerObjs, err := querier.CreateTailers(tailTest.Tailers, benchCfg.Querier)
+		Expect(err).Should(Succeed(), "Failed to create tailers")
+
+		for _, obj := range tailerObjs {
+			err := k8sClient.Create(context.TODO(), obj, &client.CreateOptions{})
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed to deploy %s", obj.GetName()))
+
+			obj := obj
+			DeferCleanup(func() {
+				err := k8sClient.Delete(context.TODO(), obj, &client.DeleteOptions{})
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed to delete %s", obj.GetName()))
+			})
+
+			if _, ok := obj.(*appsv1.Deployment); !ok {
+				continue
+			}
+
+			err = utils.WaitForReadyDeployment(k8sClient, obj, defaultRetry, defaultTimeout)
+			Expect(err).Should(Succeed(), fmt.Sprintf("Failed to wait for ready tailer deployment: %s", obj.GetName()))
+		}
+	})
+
+	Describe("Tailing logs from Loki service", func() {
+		It("samples metric data from tail path related components", func() {
+			samplingCfg, samplingRange = tailTest.SamplingConfiguration()
+
+			// Sleeping for the first interval so that the data is accurate for the new workload.
+			time.Sleep(samplingCfg.MinSamplingInterval)
+
+			e := gmeasure.NewExperiment(tailTest.Description)
+			AddReportEntry(e.Name, e)
+
+			e.Sample(func(idx int) {
+				// Load Generation
+				err := metricsClient.MeasureTailMetrics(e, samplingRange)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+				err = metricsClient.MeasureIngestionVerificationMetrics(e, generatorDpl.GetName(), samplingRange)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+
+				// Query Frontend
+				job := benchCfg.Metrics.Jobs.QueryFrontend
+				annotation := metrics.QueryFrontendAnnotation
+
+				err = metricsClient.MeasureResourceUsageMetrics(e, job, samplingRange, annotation)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+
+				// Querier
+				job = benchCfg.Metrics.Jobs.Querier
+				annotation = metrics.QuerierAnnotation
+
+				err = metricsClient.MeasureResourceUsageMetrics(e, job, samplingRange, annotation)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+
+				// Ingesters
+				job = benchCfg.Metrics.Jobs.Ingester
+				annotation = metrics.IngesterAnnotation
+
+				err = metricsClient.MeasureResourceUsageMetrics(e, job, samplingRange, annotation)
+				Expect(err).Should(Succeed(), fmt.Sprintf("Failed - %v", err))
+			}, samplingCfg)
+		})
+	})
+})
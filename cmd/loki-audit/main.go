@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/audit"
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+)
+
+// maxTerminationMessage is the size limit of the termination message of a
+// Kubernetes container.
+const maxTerminationMessage = 4096
+
+type options struct {
+	url                string
+	tenant             string
+	bearerTokenFile    string
+	start              string
+	end                string
+	tolerance          float64
+	timeout            time.Duration
+	terminationLogPath string
+}
+
+func main() {
+	opts := options{}
+
+	flag.StringVar(&opts.url, "url", "", "Loki URL, e.g. http://query-frontend:3100.")
+	flag.StringVar(&opts.tenant, "tenant", "", "Tenant ID sent with every query.")
+	flag.StringVar(&opts.bearerTokenFile, "bearer-token-file", "", "File containing the bearer token sent with every query.")
+	flag.StringVar(&opts.start, "start", "", "Start of the audited window in RFC3339.")
+	flag.StringVar(&opts.end, "end", "", "End of the audited window in RFC3339. Defaults to now.")
+	flag.Float64Var(&opts.tolerance, "tolerance", 0, "Fraction of the expected lines allowed to be missing, duplicated or out of order.")
+	flag.DurationVar(&opts.timeout, "timeout", 5*time.Minute, "Timeout of a single query.")
+	flag.StringVar(&opts.terminationLogPath, "termination-log", "/dev/termination-log", "File the summary is written to. Empty disables it.")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(opts options) error {
+	if opts.url == "" {
+		return fmt.Errorf("missing Loki URL")
+	}
+
+	start, err := time.Parse(time.RFC3339, opts.start)
+	if err != nil {
+		return fmt.Errorf("invalid start: %w", err)
+	}
+
+	end := time.Now()
+	if opts.end != "" {
+		end, err = time.Parse(time.RFC3339, opts.end)
+		if err != nil {
+			return fmt.Errorf("invalid end: %w", err)
+		}
+	}
+
+	client, err := loki.NewClient(opts.url, opts.tenant, opts.bearerTokenFile, opts.timeout)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	summary, err := audit.NewAuditor(client).Run(ctx, start, end, opts.tolerance)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed encoding audit summary: %w", err)
+	}
+	fmt.Println(string(out))
+
+	if opts.terminationLogPath == "" {
+		return nil
+	}
+
+	// Keep the totals if the stream results do not fit.
+	if len(out) > maxTerminationMessage {
+		summary.Results = nil
+		out, err = json.Marshal(summary)
+		if err != nil {
+			return fmt.Errorf("failed encoding audit summary: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(opts.terminationLogPath, out, 0o644); err != nil { //nolint:gosec
+		return fmt.Errorf("failed writing termination log: %w", err)
+	}
+
+	return nil
+}
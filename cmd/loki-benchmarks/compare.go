@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/observatorium/loki-benchmarks/internal/report"
+)
+
+func runCompare(_ context.Context, args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	maxChange := fs.Float64("max-change", 0, "Exit with code 3 if the median of a measurement changed by more than this percentage. 0 disables the check.")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: loki-benchmarks compare [flags] <baseline report> <candidate report>")
+		fs.PrintDefaults()
+	}
+
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return usageError{msg: "expected a baseline and a candidate report"}
+	}
+
+	baseline, err := report.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	candidate, err := report.Load(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	deltas := report.Compare(baseline, candidate)
+	if err := report.WriteDeltas(os.Stdout, deltas); err != nil {
+		return err
+	}
+
+	if *maxChange <= 0 {
+		return nil
+	}
+
+	changed := 0
+	for _, d := range deltas {
+		if math.Abs(d.Change()) > *maxChange {
+			changed++
+		}
+	}
+	if changed > 0 {
+		return fmt.Errorf("%d of %d measurements changed by more than %g%%: %w", changed, len(deltas), *maxChange, errChanged)
+	}
+
+	return nil
+}
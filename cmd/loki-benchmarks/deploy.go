@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/observatorium/loki-benchmarks/internal/environment"
+)
+
+func runDeploy(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	opts := environmentFlags(fs)
+
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := validateEnvironment(fs, opts); err != nil {
+		return err
+	}
+
+	env := environment.New(*opts)
+	if err := env.Create(ctx); err != nil {
+		return err
+	}
+
+	return env.Deploy(ctx)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+	"github.com/observatorium/loki-benchmarks/internal/environment"
+)
+
+// environmentFlags registers the flags describing the environment, shared
+// by deploy, run and teardown.
+func environmentFlags(fs *flag.FlagSet) *environment.Options {
+	opts := &environment.Options{}
+
+	fs.StringVar(&opts.Namespace, "namespace", "observatorium", "Namespace Loki is deployed into. Defaults to openshift-logging for the operator on OpenShift.")
+	fs.StringVar(&opts.Flavor, "flavor", environment.FlavorObservatorium, "How Loki is deployed: observatorium, rhobs or operator.")
+	fs.StringVar(&opts.ComponentPrefix, "component-prefix", "observatorium-xyz-loki", "Name prefix of the Loki components, the LokiStack name for the operator.")
+	fs.StringVar(&opts.DeploymentMode, "deployment-mode", config.DeploymentModeMicroservices, "Loki deployment mode: microservices, simple-scalable or monolithic.")
+	fs.BoolVar(&opts.OpenShift, "openshift", false, "Deploy onto OpenShift with S3 storage and user workload monitoring.")
+	fs.BoolVar(&opts.Cadvisor, "cadvisor", false, "Deploy cAdvisor to measure the container resources.")
+	fs.StringVar(&opts.StorageBucket, "storage-bucket", "loki-benchmark-storage", "S3 bucket created on OpenShift.")
+	fs.BoolVar(&opts.Kind, "kind", false, "Create a kind cluster for the environment and delete it on teardown.")
+	fs.StringVar(&opts.RHOBSDeploymentFile, "rhobs-deployment-file", "/tmp/rhobs-loki-deployment.yaml", "Processed RHOBS template deployed by the rhobs flavor.")
+	fs.StringVar(&opts.OperatorRegistry, "operator-registry", "openshift-logging", "quay.io organization the operator images are pushed to.")
+	fs.StringVar(&opts.ObservatoriumDir, "observatorium-dir", "../observatorium", "Checkout of the observatorium repository.")
+	fs.StringVar(&opts.OperatorDir, "operator-dir", "../loki/operator", "Checkout of the Loki operator.")
+	fs.StringVar(&opts.CadvisorDir, "cadvisor-dir", "../cadvisor", "Checkout of the cAdvisor repository.")
+	fs.StringVar(&opts.KindBinary, "kind-binary", "kind", "kind binary.")
+	fs.StringVar(&opts.KustomizeBinary, "kustomize-binary", "kustomize", "kustomize binary.")
+	fs.DurationVar(&opts.Timeout, "ready-timeout", 10*time.Minute, "Time to wait for every Loki component to be ready.")
+
+	return opts
+}
+
+// validateEnvironment checks the environment flags after parsing.
+func validateEnvironment(fs *flag.FlagSet, opts *environment.Options) error {
+	switch opts.Flavor {
+	case environment.FlavorObservatorium, environment.FlavorRHOBS, environment.FlavorOperator:
+	default:
+		return usageError{msg: "unsupported flavor: " + opts.Flavor}
+	}
+
+	if _, err := config.TargetJobs(opts.DeploymentMode, opts.ComponentPrefix); err != nil {
+		return usageError{msg: err.Error()}
+	}
+
+	if opts.Flavor == environment.FlavorOperator && opts.OpenShift && !isSet(fs, "namespace") {
+		opts.Namespace = environment.OpenShiftOperatorNamespace
+	}
+
+	return nil
+}
+
+func isSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Exit codes of the commands.
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+	exitChanged = 3
+)
+
+// errChanged is returned by compare if a measurement changed by more than
+// the allowed percentage.
+var errChanged = errors.New("measurements changed beyond the limit")
+
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+type subcommand struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+var subcommands = []subcommand{
+	{"deploy", "Create the environment and deploy Loki", runDeploy},
+	{"run", "Run the benchmark scenarios against the deployed Loki", runBenchmarks},
+	{"report", "Extract the measurements of report directories", runReport},
+	{"teardown", "Destroy the environment", runTeardown},
+	{"compare", "Compare the measurements of two reports", runCompare},
+}
+
+func main() {
+	log.SetFlags(log.LstdFlags)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := dispatch(ctx, os.Args[1:])
+	cancel()
+
+	os.Exit(code)
+}
+
+func dispatch(ctx context.Context, args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage()
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+
+	for _, cmd := range subcommands {
+		if cmd.name != args[0] {
+			continue
+		}
+
+		err := cmd.run(ctx, args[1:])
+
+		var uerr usageError
+		switch {
+		case err == nil:
+			return exitOK
+		case errors.Is(err, flag.ErrHelp):
+			return exitOK
+		case errors.As(err, &uerr):
+			fmt.Fprintf(os.Stderr, "%s %s: %v\n", os.Args[0], cmd.name, err)
+			return exitUsage
+		case errors.Is(err, errChanged):
+			log.Print(err)
+			return exitChanged
+		default:
+			log.Printf("%s failed: %v", cmd.name, err)
+			return exitFailure
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+	usage()
+	return exitUsage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s <command> [flags]\n\ncommands:\n", os.Args[0])
+	for _, cmd := range subcommands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -h' for the flags of a command.\n", os.Args[0])
+}
+
+// parse parses the flags of a subcommand and wraps parse errors as usage
+// errors.
+func parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError{msg: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"testing"
+)
+
+func TestDispatchExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", want: exitOK},
+		{name: "help", err: flag.ErrHelp, want: exitOK},
+		{name: "usage error", err: usageError{msg: "missing flag"}, want: exitUsage},
+		{name: "wrapped usage error", err: fmt.Errorf("run: %w", usageError{msg: "missing flag"}), want: exitUsage},
+		{name: "changed measurements", err: errChanged, want: exitChanged},
+		{name: "wrapped changed measurements", err: fmt.Errorf("compare: %w", errChanged), want: exitChanged},
+		{name: "failure", err: errors.New("deploy failed"), want: exitFailure},
+	}
+
+	saved := subcommands
+	t.Cleanup(func() { subcommands = saved })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subcommands = []subcommand{{
+				name: "test",
+				run:  func(context.Context, []string) error { return tt.err },
+			}}
+
+			if got := dispatch(context.Background(), []string{"test"}); got != tt.want {
+				t.Errorf("got exit code %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatchCommandLine(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "without command", want: exitUsage},
+		{name: "help command", args: []string{"help"}, want: exitOK},
+		{name: "help flag", args: []string{"--help"}, want: exitOK},
+		{name: "unknown command", args: []string{"unknown"}, want: exitUsage},
+		{name: "invalid flag", args: []string{"compare", "--unknown"}, want: exitUsage},
+		{name: "missing arguments", args: []string{"compare"}, want: exitUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dispatch(context.Background(), tt.args); got != tt.want {
+				t.Errorf("got exit code %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
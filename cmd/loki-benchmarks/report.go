@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/observatorium/loki-benchmarks/internal/report"
+)
+
+func runReport(_ context.Context, args []string) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: loki-benchmarks report <report directory>...")
+	}
+
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return usageError{msg: "missing report directory"}
+	}
+
+	for _, dir := range fs.Args() {
+		if err := report.WriteMeasurements(dir); err != nil {
+			return err
+		}
+		log.Printf("wrote measurements of %s", dir)
+	}
+
+	return nil
+}
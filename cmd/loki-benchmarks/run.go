@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+	"github.com/observatorium/loki-benchmarks/internal/environment"
+	"github.com/observatorium/loki-benchmarks/internal/report"
+	"github.com/observatorium/loki-benchmarks/internal/scrape"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	benchmarksDir     = "config/benchmarks"
+	benchmarkFileName = "benchmark.yaml"
+	suiteDir          = "./benchmarks"
+)
+
+type runOptions struct {
+	scenarios         string
+	configDir         string
+	outputDir         string
+	redeploy          bool
+	prometheusURL     string
+	prometheusBinary  string
+	prometheusTmpl    string
+	prometheusConfig  string
+	ginkgoBinary      string
+	ginkgoTimeout     time.Duration
+	monitoringTimeout time.Duration
+}
+
+func runBenchmarks(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	envOpts := environmentFlags(fs)
+
+	opts := runOptions{}
+	fs.StringVar(&opts.scenarios, "scenarios", "config/benchmarks/scenarios/benchmarks", "Scenario file or directory of scenario files to run.")
+	fs.StringVar(&opts.configDir, "config-dir", "observatorium", "Directory below config/benchmarks with the generator and querier configuration.")
+	fs.StringVar(&opts.outputDir, "output-dir", filepath.Join("reports", time.Now().Format("2006-01-02-15-04-05")), "Directory the reports are written to, one subdirectory per scenario.")
+	fs.BoolVar(&opts.redeploy, "redeploy", false, "Deploy a fresh environment for every scenario and tear it down afterwards.")
+	fs.StringVar(&opts.prometheusURL, "prometheus-url", "http://127.0.0.1:9090", "URL of the local Prometheus. Ignored on OpenShift.")
+	fs.StringVar(&opts.prometheusBinary, "prometheus-binary", "prometheus", "Prometheus binary scraping the Loki components. Ignored on OpenShift.")
+	fs.StringVar(&opts.prometheusTmpl, "prometheus-template", "config/prometheus/config.template", "Prometheus configuration template.")
+	fs.StringVar(&opts.prometheusConfig, "prometheus-config", "config/prometheus/config.yaml", "Rendered Prometheus configuration.")
+	fs.StringVar(&opts.ginkgoBinary, "ginkgo-binary", "ginkgo", "Ginkgo binary running the benchmark suite.")
+	fs.DurationVar(&opts.ginkgoTimeout, "timeout", 4*time.Hour, "Timeout of the benchmark suite per scenario.")
+	fs.DurationVar(&opts.monitoringTimeout, "monitoring-timeout", 5*time.Minute, "Time to wait for the OpenShift user workload monitoring.")
+
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := validateEnvironment(fs, envOpts); err != nil {
+		return err
+	}
+
+	scenarios, err := scenarioFiles(opts.scenarios)
+	if err != nil {
+		return err
+	}
+
+	env := environment.New(*envOpts)
+	for _, scenario := range scenarios {
+		if err := runScenario(ctx, env, envOpts, opts, scenario); err != nil {
+			return fmt.Errorf("scenario %s: %w", scenario, err)
+		}
+	}
+
+	return nil
+}
+
+// scenarioFiles returns the file or the YAML files in the directory.
+func scenarioFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, usageError{msg: err.Error()}
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(path, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, usageError{msg: "no scenarios found in " + path}
+	}
+
+	return files, nil
+}
+
+func runScenario(ctx context.Context, env *environment.Environment, envOpts *environment.Options, opts runOptions, scenario string) (err error) {
+	if opts.redeploy {
+		defer func() {
+			if derr := env.Destroy(context.Background()); err == nil {
+				err = derr
+			}
+		}()
+
+		if err := env.Create(ctx); err != nil {
+			return err
+		}
+		if err := env.Deploy(ctx); err != nil {
+			return err
+		}
+	}
+
+	c, err := env.Client()
+	if err != nil {
+		return err
+	}
+
+	url, token := opts.prometheusURL, ""
+	if envOpts.OpenShift {
+		if err := environment.EnableUserWorkloadMonitoring(ctx, c); err != nil {
+			return err
+		}
+
+		// The monitoring stack takes a moment to apply the configuration.
+		err = wait.PollImmediateWithContext(ctx, 5*time.Second, opts.monitoringTimeout, func(ctx context.Context) (bool, error) {
+			url, token, err = scrape.OpenShift(ctx, c)
+			if err != nil {
+				log.Printf("waiting for user workload monitoring: %v", err)
+			}
+			return err == nil, nil
+		})
+		if err != nil {
+			return fmt.Errorf("user workload monitoring not ready: %w", err)
+		}
+	} else {
+		local, err := scrape.StartLocal(ctx, c, scrape.LocalOptions{
+			Namespace:        envOpts.Namespace,
+			PrometheusBinary: opts.prometheusBinary,
+			TemplateFile:     opts.prometheusTmpl,
+			ConfigFile:       opts.prometheusConfig,
+			DeploymentMode:   envOpts.DeploymentMode,
+		})
+		if err != nil {
+			return err
+		}
+		defer local.Stop()
+	}
+
+	reportDir := filepath.Join(opts.outputDir, strings.TrimSuffix(filepath.Base(scenario), filepath.Ext(scenario)))
+	if err := os.MkdirAll(reportDir, 0o755); err != nil {
+		return fmt.Errorf("failed creating report directory: %w", err)
+	}
+
+	metricsCfg := &config.Metrics{
+		URL:                   url,
+		EnableCadvisorMetrics: envOpts.OpenShift,
+		DeploymentMode:        envOpts.DeploymentMode,
+	}
+	metricsCfg.Jobs, err = config.TargetJobs(envOpts.DeploymentMode, envOpts.ComponentPrefix)
+	if err != nil {
+		return err
+	}
+
+	benchmarkFile := filepath.Join(benchmarksDir, opts.configDir, benchmarkFileName)
+	if err := writeBenchmarkFile(benchmarkFile, filepath.Join(benchmarksDir, opts.configDir), scenario, metricsCfg); err != nil {
+		return err
+	}
+
+	suiteErr := runSuite(ctx, opts, reportDir, token)
+
+	if err := os.Rename(benchmarkFile, filepath.Join(reportDir, benchmarkFileName)); err != nil {
+		return fmt.Errorf("failed moving benchmark file to report directory: %w", err)
+	}
+	if err := report.WriteMeasurements(reportDir); err != nil {
+		log.Printf("failed writing measurements: %v", err)
+	}
+
+	return suiteErr
+}
+
+// writeBenchmarkFile concatenates the generator and querier configuration,
+// the metrics configuration and the scenario into the benchmark file the
+// suite reads.
+func writeBenchmarkFile(path, configDir, scenario string, metricsCfg *config.Metrics) error {
+	var buf bytes.Buffer
+
+	for _, file := range []string{filepath.Join(configDir, "generator.yaml"), filepath.Join(configDir, "querier.yaml")} {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed reading configuration: %w", err)
+		}
+		buf.Write(data)
+		if !bytes.HasSuffix(data, []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+	}
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	err := enc.Encode(struct {
+		Metrics *config.Metrics `yaml:"metrics"`
+	}{metricsCfg})
+	if err != nil {
+		return fmt.Errorf("failed encoding metrics configuration: %w", err)
+	}
+
+	data, err := os.ReadFile(scenario)
+	if err != nil {
+		return fmt.Errorf("failed reading scenario: %w", err)
+	}
+	buf.Write(data)
+
+	// Fail before the suite does.
+	cfg := &config.Benchmark{}
+	if err := yaml.Unmarshal(buf.Bytes(), cfg); err != nil {
+		return fmt.Errorf("invalid benchmark configuration: %w", err)
+	}
+	if err := cfg.Metrics.ResolveJobs(); err != nil {
+		return fmt.Errorf("invalid benchmark configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid benchmark configuration: %w", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed writing benchmark file: %w", err)
+	}
+	return nil
+}
+
+// runSuite runs the Ginkgo benchmark suite, which reads the benchmark file
+// of the configuration directory.
+func runSuite(ctx context.Context, opts runOptions, reportDir, token string) error {
+	cmd := exec.CommandContext(ctx, opts.ginkgoBinary,
+		"--output-dir="+reportDir,
+		"--json-report="+report.ReportFile,
+		"--timeout="+opts.ginkgoTimeout.String(),
+		suiteDir,
+	)
+	cmd.Env = append(os.Environ(), "BENCHMARKING_CONFIGURATION_DIRECTORY="+opts.configDir)
+	if token != "" {
+		cmd.Env = append(cmd.Env, "PROMETHEUS_TOKEN="+token)
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	log.Printf("running benchmark suite, reporting to %s", reportDir)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("benchmark suite failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	testGenerator = "generator:\n  namespace: observatorium\n  tenant: test-oidc"
+	testQuerier   = "querier:\n  namespace: observatorium\n  tenant: test-oidc\n"
+	testScenario  = "scenarios:\n  ingestionPath:\n    enabled: true\n"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWriteBenchmarkFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"generator.yaml": testGenerator,
+		"querier.yaml":   testQuerier,
+		"scenario.yaml":  testScenario,
+	})
+
+	file := filepath.Join(dir, benchmarkFileName)
+	metrics := &config.Metrics{
+		URL:            "http://localhost:9090",
+		Jobs:           &config.Jobs{Read: "loki-read", Write: "loki-write"},
+		DeploymentMode: config.DeploymentModeSimpleScalable,
+	}
+
+	if err := writeBenchmarkFile(file, dir, filepath.Join(dir, "scenario.yaml"), metrics); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Benchmark{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		t.Fatalf("invalid benchmark file: %v", err)
+	}
+
+	switch {
+	case cfg.Generator.Tenant != "test-oidc":
+		t.Errorf("got generator tenant %q", cfg.Generator.Tenant)
+	case cfg.Querier.Namespace != "observatorium":
+		t.Errorf("got querier namespace %q", cfg.Querier.Namespace)
+	case cfg.Metrics.URL != "http://localhost:9090" || cfg.Metrics.Jobs.Read != "loki-read":
+		t.Errorf("got metrics %+v", cfg.Metrics)
+	case cfg.Scenarios.IngestionPath == nil:
+		t.Error("missing ingestion path scenario")
+	}
+}
+
+func TestWriteBenchmarkFileErrors(t *testing.T) {
+	jobs := func() *config.Jobs {
+		return &config.Jobs{Read: "loki-read", Write: "loki-write"}
+	}
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		metrics *config.Metrics
+		err     string
+	}{
+		{
+			name:    "missing generator configuration",
+			files:   map[string]string{"querier.yaml": testQuerier, "scenario.yaml": testScenario},
+			metrics: &config.Metrics{Jobs: jobs()},
+			err:     "failed reading configuration",
+		},
+		{
+			name:    "missing querier configuration",
+			files:   map[string]string{"generator.yaml": testGenerator, "scenario.yaml": testScenario},
+			metrics: &config.Metrics{Jobs: jobs()},
+			err:     "failed reading configuration",
+		},
+		{
+			name:    "missing scenario",
+			files:   map[string]string{"generator.yaml": testGenerator, "querier.yaml": testQuerier},
+			metrics: &config.Metrics{Jobs: jobs()},
+			err:     "failed reading scenario",
+		},
+		{
+			name:    "invalid scenario",
+			files:   map[string]string{"generator.yaml": testGenerator, "querier.yaml": testQuerier, "scenario.yaml": "scenarios: ["},
+			metrics: &config.Metrics{Jobs: jobs()},
+			err:     "invalid benchmark configuration",
+		},
+		{
+			name:    "missing metrics jobs",
+			files:   map[string]string{"generator.yaml": testGenerator, "querier.yaml": testQuerier, "scenario.yaml": testScenario},
+			metrics: &config.Metrics{},
+			err:     "missing metrics jobs",
+		},
+		{
+			name:    "incomplete jobs of the deployment mode",
+			files:   map[string]string{"generator.yaml": testGenerator, "querier.yaml": testQuerier, "scenario.yaml": testScenario},
+			metrics: &config.Metrics{Jobs: jobs(), DeploymentMode: config.DeploymentModeMonolithic},
+			err:     "monolithic mode requires the singleBinary job",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			file := filepath.Join(dir, benchmarkFileName)
+			err := writeBenchmarkFile(file, dir, filepath.Join(dir, "scenario.yaml"), tt.metrics)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+
+			if _, err := os.Stat(file); !os.IsNotExist(err) {
+				t.Errorf("benchmark file written despite the error")
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/observatorium/loki-benchmarks/internal/environment"
+)
+
+func runTeardown(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("teardown", flag.ContinueOnError)
+	opts := environmentFlags(fs)
+
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := validateEnvironment(fs, opts); err != nil {
+		return err
+	}
+
+	return environment.New(*opts).Destroy(ctx)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/deletion"
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+)
+
+// maxTerminationMessage is the size limit of the termination message of a
+// Kubernetes container.
+const maxTerminationMessage = 4096
+
+type requestsFlag []deletion.Request
+
+func (r *requestsFlag) String() string {
+	reqs := make([]string, 0, len(*r))
+	for _, req := range *r {
+		reqs = append(reqs, req.String())
+	}
+	return strings.Join(reqs, ",")
+}
+
+func (r *requestsFlag) Set(value string) error {
+	req, err := deletion.ParseRequest(value)
+	if err != nil {
+		return err
+	}
+
+	*r = append(*r, req)
+	return nil
+}
+
+type options struct {
+	url                string
+	tenant             string
+	bearerTokenFile    string
+	requests           requestsFlag
+	pollInterval       time.Duration
+	processTimeout     time.Duration
+	timeout            time.Duration
+	terminationLogPath string
+}
+
+func main() {
+	opts := options{}
+
+	flag.StringVar(&opts.url, "url", "", "Loki URL, e.g. http://query-frontend:3100.")
+	flag.StringVar(&opts.tenant, "tenant", "", "Tenant ID sent with every request.")
+	flag.StringVar(&opts.bearerTokenFile, "bearer-token-file", "", "File containing the bearer token sent with every request.")
+	flag.Var(&opts.requests, "request", "Delete request as name:offset:range:query. Can be repeated.")
+	flag.DurationVar(&opts.pollInterval, "poll-interval", 10*time.Second, "Interval between two listings of the delete requests.")
+	flag.DurationVar(&opts.processTimeout, "process-timeout", time.Hour, "Time to wait for all delete requests to be processed.")
+	flag.DurationVar(&opts.timeout, "timeout", 5*time.Minute, "Timeout of a single request.")
+	flag.StringVar(&opts.terminationLogPath, "termination-log", "/dev/termination-log", "File the summary is written to. Empty disables it.")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(opts options) error {
+	if opts.url == "" {
+		return fmt.Errorf("missing Loki URL")
+	}
+	if len(opts.requests) == 0 {
+		return fmt.Errorf("missing delete requests")
+	}
+
+	client, err := loki.NewClient(opts.url, opts.tenant, opts.bearerTokenFile, opts.timeout)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	summary, err := deletion.NewDeleter(client).Run(ctx, opts.requests, opts.pollInterval, opts.processTimeout)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed encoding deletion summary: %w", err)
+	}
+	fmt.Println(string(out))
+
+	if opts.terminationLogPath == "" {
+		return nil
+	}
+
+	// Keep the totals if the request results do not fit.
+	if len(out) > maxTerminationMessage {
+		summary.Results = nil
+		out, err = json.Marshal(summary)
+		if err != nil {
+			return fmt.Errorf("failed encoding deletion summary: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(opts.terminationLogPath, out, 0o644); err != nil { //nolint:gosec
+		return fmt.Errorf("failed writing termination log: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+)
+
+const (
+	minBackoff = 500 * time.Millisecond
+	maxBackoff = 30 * time.Second
+)
+
+// backfill pushes approximately totalBytes of log lines with timestamps
+// evenly spread over the window ending now. Batches are pushed in order and
+// retried until accepted, so that the stream never goes out of order.
+// Batches rejected as invalid, e.g. out of order or too old, are counted and
+// skipped. The backfill only fails when every batch was rejected.
+func (g *generator) backfill(ctx context.Context, window time.Duration, totalBytes int64, batchSize int) error {
+	end := time.Now()
+	start := end.Add(-window)
+
+	total := int(totalBytes / int64(entrySize(g.entry(start))))
+	if total < 1 {
+		total = 1
+	}
+	step := window / time.Duration(total)
+
+	log.Printf("backfilling %d log lines between %s and %s", total, start.Format(time.RFC3339), end.Format(time.RFC3339))
+
+	var rejected int
+	backoff := minBackoff
+	for sent := 0; sent < total; {
+		n := batchSize
+		if total-sent < n {
+			n = total - sent
+		}
+
+		batchStart := start.Add(time.Duration(sent) * step)
+		req := &loki.PushRequest{
+			Streams: []loki.Stream{
+				{Labels: g.labels, Entries: g.entries(batchStart, step*time.Duration(n), n)},
+			},
+		}
+
+		err := g.client.Push(ctx, req)
+		if err != nil {
+			var statusErr *loki.StatusError
+			if errors.As(err, &statusErr) {
+				switch {
+				case statusErr.Code == http.StatusBadRequest:
+					log.Printf("skipping %d rejected log lines: %v", n, err)
+					rejected += n
+					sent += n
+					continue
+				case !statusErr.Retryable():
+					return fmt.Errorf("failed backfilling log lines: %w", err)
+				}
+			}
+
+			log.Printf("retrying rejected batch in %s: %v", backoff, err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+			continue
+		}
+
+		backoff = minBackoff
+		sent += n
+
+		if sent%(batchSize*100) == 0 || sent == total {
+			log.Printf("backfilled %d/%d log lines", sent, total)
+		}
+	}
+
+	if rejected == total {
+		return fmt.Errorf("failed backfilling log lines: all %d log lines rejected", total)
+	}
+	if rejected > 0 {
+		log.Printf("skipped %d/%d rejected log lines", rejected, total)
+	}
+
+	return nil
+}
+
+// entrySize returns the number of bytes accounted by the distributor for
+// the entry, i.e. the line and its structured metadata.
+func entrySize(e loki.Entry) int {
+	size := len(e.Line)
+	for k, v := range e.StructuredMetadata {
+		size += len(k) + len(v)
+	}
+	return size
+}
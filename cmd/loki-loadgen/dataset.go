@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/dataset"
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+)
+
+// pushDataset pushes the lines of the deterministic dataset up to now every
+// interval. Rejected batches are retried until accepted, so that every line
+// of the dataset lands in Loki and the query results can be verified.
+func pushDataset(ctx context.Context, client *loki.Client, d *dataset.Dataset, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("pushing dataset %d with %d streams at %d logs per second each", d.Seed, d.Streams, d.LinesPerSecond)
+
+	last := time.Now()
+	backoff := minBackoff
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		req := &loki.PushRequest{}
+		for i := 0; i < d.Streams; i++ {
+			req.Streams = append(req.Streams, loki.Stream{Labels: d.Labels(i), Entries: d.Entries(i, last, now)})
+		}
+
+		for {
+			err := client.Push(ctx, req)
+			if err == nil {
+				backoff = minBackoff
+				break
+			}
+
+			var statusErr *loki.StatusError
+			if errors.As(err, &statusErr) && !statusErr.Retryable() {
+				// Not retryable, the verification reports the lines as missing.
+				log.Printf("failed pushing dataset batch: %v", err)
+				break
+			}
+
+			log.Printf("retrying rejected dataset batch in %s: %v", backoff, err)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+
+		last = now
+	}
+}
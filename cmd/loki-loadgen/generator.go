@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/audit"
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+)
+
+const (
+	// reportTimeout stays below the default termination grace period of 30s.
+	reportTimeout = 20 * time.Second
+
+	payloadCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+var (
+	levels     = []string{"info", "info", "info", "debug", "warn", "error"}
+	components = []string{"api", "auth", "billing", "cache", "scheduler"}
+)
+
+type metadataField struct {
+	name        string
+	cardinality int
+	size        int
+}
+
+// value returns the value for the i-th distinct value of the field.
+// Values are zero padded hexadecimal numbers so that queries can select a
+// known fraction of the generated lines.
+func (f metadataField) value(i int) string {
+	return fmt.Sprintf("%0*x", f.size, i%f.cardinality)
+}
+
+type metadataFlag []metadataField
+
+func (m *metadataFlag) String() string {
+	fields := make([]string, 0, len(*m))
+	for _, f := range *m {
+		fields = append(fields, fmt.Sprintf("%s:%d:%d", f.name, f.cardinality, f.size))
+	}
+	return strings.Join(fields, ",")
+}
+
+func (m *metadataFlag) Set(value string) error {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid structured metadata field %q: expected name:cardinality:size", value)
+	}
+
+	cardinality, err := strconv.Atoi(parts[1])
+	if err != nil || cardinality <= 0 {
+		return fmt.Errorf("invalid cardinality for structured metadata field %q", value)
+	}
+
+	size, err := strconv.Atoi(parts[2])
+	if err != nil || size <= 0 {
+		return fmt.Errorf("invalid size for structured metadata field %q", value)
+	}
+
+	*m = append(*m, metadataField{name: parts[0], cardinality: cardinality, size: size})
+	return nil
+}
+
+type generator struct {
+	client      *loki.Client
+	logType     string
+	labels      map[string]string
+	payloadSize int
+	metadata    []metadataField
+	inline      bool
+	rnd         *rand.Rand
+
+	// report is set in audit mode and tracks the accepted sequence numbers.
+	report *audit.Report
+}
+
+func newGenerator(
+	client *loki.Client,
+	logType, labelType string,
+	payloadSize int,
+	metadata []metadataField,
+	inline bool,
+	auditLines bool,
+) (*generator, error) {
+	host, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading hostname: %w", err)
+	}
+
+	labels := map[string]string{}
+	switch labelType {
+	case "client":
+		labels["client"] = "promtail"
+	case "host":
+		labels["host"] = host
+	case "client-host":
+		labels["client"] = "promtail"
+		labels["host"] = host
+	default:
+		return nil, fmt.Errorf("unsupported label type: %s", labelType)
+	}
+
+	if logType != "synthetic" && logType != "application" {
+		return nil, fmt.Errorf("unsupported log type: %s", logType)
+	}
+
+	g := &generator{
+		client:      client,
+		logType:     logType,
+		labels:      labels,
+		payloadSize: payloadSize,
+		metadata:    metadata,
+		inline:      inline,
+		rnd:         rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec
+	}
+
+	if auditLines {
+		g.report = &audit.Report{Stream: host, Selector: selector(labels)}
+	}
+
+	return g, nil
+}
+
+// selector returns the stream selector matching exactly the labels.
+func selector(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	matchers := make([]string, 0, len(names))
+	for _, name := range names {
+		matchers = append(matchers, fmt.Sprintf("%s=%q", name, labels[name]))
+	}
+	return "{" + strings.Join(matchers, ", ") + "}"
+}
+
+func (g *generator) run(ctx context.Context, logsPerSecond int, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	perBatch := int(float64(logsPerSecond) * interval.Seconds())
+	if perBatch < 1 {
+		perBatch = 1
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if g.report != nil {
+				return g.pushReport()
+			}
+			return nil
+		case now := <-ticker.C:
+			var first int64
+			if g.report != nil {
+				first = g.report.Next
+			}
+
+			entries := g.entries(now.Add(-interval), interval, perBatch)
+			req := &loki.PushRequest{
+				Streams: []loki.Stream{
+					{Labels: g.labels, Entries: entries},
+				},
+			}
+
+			err := g.client.Push(ctx, req)
+			if err != nil {
+				log.Printf("failed pushing %d log lines: %v", perBatch, err)
+			}
+
+			if g.report != nil {
+				g.record(first, entries, err)
+			}
+		}
+	}
+}
+
+// record adds a pushed batch to the audit report.
+func (g *generator) record(first int64, entries []loki.Entry, err error) {
+	if g.report.Start.IsZero() {
+		g.report.Start = entries[0].Timestamp
+	}
+	g.report.End = entries[len(entries)-1].Timestamp
+
+	if err != nil {
+		g.report.Reject(first, g.report.Next)
+		return
+	}
+	g.report.Accepted += int64(len(entries))
+}
+
+// pushReport pushes the audit report once the generator stopped. It retries
+// until the termination grace period of the pod is nearly used up.
+func (g *generator) pushReport() error {
+	line, err := g.report.Marshal()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+	defer cancel()
+
+	req := &loki.PushRequest{
+		Streams: []loki.Stream{
+			{Labels: audit.ReportLabels(), Entries: []loki.Entry{{Timestamp: time.Now(), Line: line}}},
+		},
+	}
+
+	backoff := minBackoff
+	for {
+		err := g.client.Push(ctx, req)
+		if err == nil {
+			log.Printf("pushed audit report of %d accepted log lines", g.report.Accepted)
+			return nil
+		}
+
+		log.Printf("retrying audit report in %s: %v", backoff, err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed pushing audit report: %w", err)
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// entries returns n log lines with timestamps evenly spread over the window
+// starting at start.
+func (g *generator) entries(start time.Time, window time.Duration, n int) []loki.Entry {
+	entries := make([]loki.Entry, 0, n)
+	step := window / time.Duration(n)
+
+	for i := 0; i < n; i++ {
+		ts := start.Add(time.Duration(i) * step)
+		entries = append(entries, g.entry(ts))
+	}
+
+	return entries
+}
+
+func (g *generator) entry(ts time.Time) loki.Entry {
+	var metadata map[string]string
+	if len(g.metadata) > 0 {
+		metadata = make(map[string]string, len(g.metadata))
+		for _, f := range g.metadata {
+			metadata[f.name] = f.value(g.rnd.Intn(f.cardinality))
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "ts=%s level=%s component=%s",
+		ts.UTC().Format(time.RFC3339Nano),
+		levels[g.rnd.Intn(len(levels))],
+		components[g.rnd.Intn(len(components))],
+	)
+
+	if g.inline {
+		for _, f := range g.metadata {
+			fmt.Fprintf(&b, " %s=%s", f.name, metadata[f.name])
+		}
+	}
+
+	if g.report != nil {
+		fmt.Fprintf(&b, " %s=%s %s=%d", audit.StreamKey, g.report.Stream, audit.SeqKey, g.report.Next)
+		g.report.Next++
+	}
+
+	fmt.Fprintf(&b, " msg=%q", g.message())
+
+	return loki.Entry{
+		Timestamp:          ts,
+		Line:               b.String(),
+		StructuredMetadata: metadata,
+	}
+}
+
+func (g *generator) message() string {
+	if g.logType == "application" {
+		return "request completed successfully"
+	}
+
+	payload := make([]byte, g.payloadSize)
+	for i := range payload {
+		payload[i] = payloadCharset[g.rnd.Intn(len(payloadCharset))]
+	}
+	return string(payload)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/dataset"
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+	"github.com/observatorium/loki-benchmarks/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The flags mirror the subset of the cluster-logging-load-client flags used
+// by the benchmarks, so that this binary is a drop-in replacement for it.
+type options struct {
+	command              string
+	destination          string
+	url                  string
+	tenant               string
+	bearerTokenFile      string
+	logType              string
+	labelType            string
+	source               string
+	logsPerSecond        int
+	syntheticPayloadSize int
+	batchInterval        time.Duration
+	structuredMetadata   metadataFlag
+	inlineMetadata       bool
+	backfillRange        time.Duration
+	backfillBytes        int64
+	backfillBatchSize    int
+	replayFile           string
+	replayFormat         string
+	replaySpeedUp        float64
+	replayLogsPerSecond  int
+	datasetSeed          int64
+	datasetStreams       int
+	datasetLinesPerSec   int
+	audit                bool
+	metricsAddr          string
+}
+
+func main() {
+	opts := options{}
+
+	flag.StringVar(&opts.command, "command", "generate", "Command to run, only generate is supported.")
+	flag.StringVar(&opts.destination, "destination", "loki", "Destination of the generated logs, only loki is supported.")
+	flag.StringVar(&opts.url, "url", "", "Loki push URL.")
+	flag.StringVar(&opts.tenant, "tenant", "", "Tenant ID sent with every push request.")
+	flag.StringVar(&opts.bearerTokenFile, "bearer-token-file", "", "File containing the bearer token sent with every push request.")
+	flag.StringVar(&opts.logType, "log-type", "synthetic", "Type of generated log lines: synthetic or application.")
+	flag.StringVar(&opts.labelType, "label-type", "client", "Stream labels attached to the log lines: client, host or client-host.")
+	flag.StringVar(&opts.source, "source", "", "Alias of log-type kept for compatibility.")
+	flag.IntVar(&opts.logsPerSecond, "logs-per-second", 1, "Number of log lines generated per second.")
+	flag.IntVar(&opts.syntheticPayloadSize, "synthetic-payload-size", 100, "Size in bytes of the synthetic payload of every log line.")
+	flag.DurationVar(&opts.batchInterval, "batch-interval", time.Second, "Interval between two push requests.")
+	flag.Var(&opts.structuredMetadata, "structured-metadata", "Structured metadata field as name:cardinality:size. Can be repeated.")
+	flag.BoolVar(&opts.inlineMetadata, "structured-metadata-inline", false, "Also write the structured metadata as key=value pairs into the log line.")
+	flag.DurationVar(&opts.backfillRange, "backfill-range", 0, "If set, push backfill-bytes of log lines spread over this range ending now and exit.")
+	flag.Int64Var(&opts.backfillBytes, "backfill-bytes", 0, "Number of bytes pushed when backfilling.")
+	flag.IntVar(&opts.backfillBatchSize, "backfill-batch-size", 1000, "Number of log lines per push request when backfilling.")
+	flag.StringVar(&opts.replayFile, "replay-file", "", "If set, replay the log corpus in this file instead of generating synthetic lines.")
+	flag.StringVar(&opts.replayFormat, "replay-format", "jsonl", "Format of the log corpus: jsonl (logcli --output=jsonl) or loki (query_range response or push request).")
+	flag.Float64Var(&opts.replaySpeedUp, "replay-speed-up", 1, "Factor the gaps between the corpus timestamps are divided by.")
+	flag.IntVar(&opts.replayLogsPerSecond, "replay-logs-per-second", 0, "If set, replay the corpus at this fixed rate instead of its original pace.")
+	flag.Int64Var(&opts.datasetSeed, "dataset-seed", 1, "Seed of the deterministic dataset.")
+	flag.IntVar(&opts.datasetStreams, "dataset-streams", 0, "If set, push this many streams of the deterministic dataset instead of generating log lines.")
+	flag.IntVar(&opts.datasetLinesPerSec, "dataset-lines-per-second", 10, "Number of log lines per second and stream of the deterministic dataset.")
+	flag.BoolVar(&opts.audit, "audit", false, "Tag every log line with a sequence number and push an audit report of the accepted lines on shutdown.")
+	flag.StringVar(&opts.metricsAddr, "metrics-addr", ":8080", "Address serving the client metrics. Empty disables the metrics server.")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(opts options) error {
+	if opts.command != "generate" {
+		return fmt.Errorf("unsupported command: %s", opts.command)
+	}
+	if opts.destination != "loki" {
+		return fmt.Errorf("unsupported destination: %s", opts.destination)
+	}
+	if opts.url == "" {
+		return fmt.Errorf("missing push URL")
+	}
+
+	logType := opts.logType
+	if opts.source != "" {
+		logType = opts.source
+	}
+
+	client, err := loki.NewClient(strings.TrimSuffix(opts.url, loki.PushPath), opts.tenant, opts.bearerTokenFile, 30*time.Second)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if opts.metricsAddr != "" {
+		reg := prometheus.NewRegistry()
+		if err := loki.Register(reg); err != nil {
+			return fmt.Errorf("failed registering client metrics: %w", err)
+		}
+
+		utils.ServeMetrics(opts.metricsAddr, reg)
+	}
+
+	if opts.replayFile != "" {
+		return replay(ctx, client, opts)
+	}
+
+	if opts.datasetStreams > 0 {
+		d := &dataset.Dataset{Seed: opts.datasetSeed, Streams: opts.datasetStreams, LinesPerSecond: opts.datasetLinesPerSec}
+		if err := d.Validate(); err != nil {
+			return err
+		}
+		return pushDataset(ctx, client, d, opts.batchInterval)
+	}
+
+	gen, err := newGenerator(client, logType, opts.labelType, opts.syntheticPayloadSize, opts.structuredMetadata, opts.inlineMetadata, opts.audit)
+	if err != nil {
+		return err
+	}
+
+	if opts.backfillRange > 0 {
+		if opts.audit {
+			return fmt.Errorf("audit is not supported when backfilling")
+		}
+		return gen.backfill(ctx, opts.backfillRange, opts.backfillBytes, opts.backfillBatchSize)
+	}
+
+	log.Printf("generating %d logs per second to %s", opts.logsPerSecond, opts.url)
+	return gen.run(ctx, opts.logsPerSecond, opts.batchInterval)
+}
+
+func replay(ctx context.Context, client *loki.Client, opts options) error {
+	r, err := newReplayer(client, opts.replayFile, opts.replayFormat)
+	if err != nil {
+		return err
+	}
+
+	if opts.replayLogsPerSecond > 0 {
+		return r.replayAtRate(ctx, opts.replayLogsPerSecond, opts.batchInterval)
+	}
+
+	if opts.replaySpeedUp <= 0 {
+		return fmt.Errorf("invalid replay speed-up: %v", opts.replaySpeedUp)
+	}
+
+	return r.replayAtSpeed(ctx, opts.replaySpeedUp, opts.batchInterval)
+}
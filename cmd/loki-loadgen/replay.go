@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+)
+
+const (
+	replayFormatJSONL = "jsonl"
+	replayFormatLoki  = "loki"
+
+	// replicaLabel keeps the streams of every generator replica apart when
+	// all replicas replay the same corpus.
+	replicaLabel = "replica"
+)
+
+type record struct {
+	labels    map[string]string
+	timestamp time.Time
+	line      string
+	metadata  map[string]string
+}
+
+// jsonlRecord matches the output of "logcli query --output=jsonl".
+type jsonlRecord struct {
+	Labels    map[string]string `json:"labels"`
+	Timestamp string            `json:"timestamp"`
+	Line      string            `json:"line"`
+}
+
+// lokiExport matches both a query_range response and a push request body.
+type lokiExport struct {
+	Streams []lokiExportStream `json:"streams"`
+	Data    struct {
+		Result []lokiExportStream `json:"result"`
+	} `json:"data"`
+}
+
+type lokiExportStream struct {
+	Stream map[string]string   `json:"stream"`
+	Values [][]json.RawMessage `json:"values"`
+}
+
+func readCorpus(path, format string) ([]record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed opening corpus: %w", err)
+	}
+	defer f.Close()
+
+	var records []record
+	switch format {
+	case replayFormatJSONL:
+		records, err = readJSONL(bufio.NewReader(f))
+	case replayFormatLoki:
+		records, err = readLokiExport(json.NewDecoder(f))
+	default:
+		return nil, fmt.Errorf("unsupported corpus format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("corpus %s contains no log lines", path)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].timestamp.Before(records[j].timestamp)
+	})
+
+	return records, nil
+}
+
+func readJSONL(r *bufio.Reader) ([]record, error) {
+	var records []record
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for n := 1; scanner.Scan(); n++ {
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" {
+			continue
+		}
+
+		var rec jsonlRecord
+		if err := json.Unmarshal([]byte(raw), &rec); err != nil {
+			return nil, fmt.Errorf("failed decoding corpus line %d: %w", n, err)
+		}
+
+		ts, err := parseTimestamp(rec.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing timestamp on corpus line %d: %w", n, err)
+		}
+
+		records = append(records, record{labels: rec.Labels, timestamp: ts, line: rec.Line})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading corpus: %w", err)
+	}
+
+	return records, nil
+}
+
+func readLokiExport(dec *json.Decoder) ([]record, error) {
+	var records []record
+
+	for dec.More() {
+		var export lokiExport
+		if err := dec.Decode(&export); err != nil {
+			return nil, fmt.Errorf("failed decoding corpus: %w", err)
+		}
+
+		for _, stream := range append(export.Streams, export.Data.Result...) {
+			for _, value := range stream.Values {
+				rec, err := decodeValue(stream.Stream, value)
+				if err != nil {
+					return nil, err
+				}
+				records = append(records, rec)
+			}
+		}
+	}
+
+	return records, nil
+}
+
+func decodeValue(labels map[string]string, value []json.RawMessage) (record, error) {
+	if len(value) < 2 {
+		return record{}, fmt.Errorf("invalid corpus value: expected timestamp and line")
+	}
+
+	var ts, line string
+	if err := json.Unmarshal(value[0], &ts); err != nil {
+		return record{}, fmt.Errorf("failed decoding corpus timestamp: %w", err)
+	}
+	if err := json.Unmarshal(value[1], &line); err != nil {
+		return record{}, fmt.Errorf("failed decoding corpus line: %w", err)
+	}
+
+	var metadata map[string]string
+	if len(value) > 2 {
+		if err := json.Unmarshal(value[2], &metadata); err != nil {
+			return record{}, fmt.Errorf("failed decoding corpus structured metadata: %w", err)
+		}
+	}
+
+	parsed, err := parseTimestamp(ts)
+	if err != nil {
+		return record{}, err
+	}
+
+	return record{labels: labels, timestamp: parsed, line: line, metadata: metadata}, nil
+}
+
+// parseTimestamp accepts RFC3339 timestamps and unix epoch nanoseconds.
+func parseTimestamp(value string) (time.Time, error) {
+	if ns, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(0, ns), nil
+	}
+
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+type replayer struct {
+	client  *loki.Client
+	records []record
+	host    string
+}
+
+func newReplayer(client *loki.Client, path, format string) (*replayer, error) {
+	records, err := readCorpus(path, format)
+	if err != nil {
+		return nil, err
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading hostname: %w", err)
+	}
+
+	return &replayer{client: client, records: records, host: host}, nil
+}
+
+// replayAtSpeed pushes the corpus preserving the gaps between the original
+// timestamps divided by speedUp. The timestamps are rewritten relative to the
+// start of every pass over the corpus.
+func (r *replayer) replayAtSpeed(ctx context.Context, speedUp float64, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	origin := r.records[0].timestamp
+	start := time.Now()
+	next := 0
+
+	log.Printf("replaying %d log lines at %.2fx speed", len(r.records), speedUp)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			var batch []record
+			for ; next < len(r.records); next++ {
+				offset := time.Duration(float64(r.records[next].timestamp.Sub(origin)) / speedUp)
+				ts := start.Add(offset)
+				if ts.After(now) {
+					break
+				}
+
+				rec := r.records[next]
+				rec.timestamp = ts
+				batch = append(batch, rec)
+			}
+
+			r.push(ctx, batch)
+
+			if next == len(r.records) {
+				start = now
+				next = 0
+			}
+		}
+	}
+}
+
+// replayAtRate pushes the corpus in a loop at a fixed number of lines per
+// second, spreading the rewritten timestamps evenly over every batch.
+func (r *replayer) replayAtRate(ctx context.Context, logsPerSecond int, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	perBatch := int(float64(logsPerSecond) * interval.Seconds())
+	if perBatch < 1 {
+		perBatch = 1
+	}
+	step := interval / time.Duration(perBatch)
+	next := 0
+
+	log.Printf("replaying %d log lines at %d logs per second", len(r.records), logsPerSecond)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			batch := make([]record, 0, perBatch)
+			start := now.Add(-interval)
+
+			for i := 0; i < perBatch; i++ {
+				rec := r.records[next]
+				rec.timestamp = start.Add(time.Duration(i) * step)
+				batch = append(batch, rec)
+
+				next = (next + 1) % len(r.records)
+			}
+
+			r.push(ctx, batch)
+		}
+	}
+}
+
+func (r *replayer) push(ctx context.Context, batch []record) {
+	if len(batch) == 0 {
+		return
+	}
+
+	streams := map[string]*loki.Stream{}
+	var keys []string
+
+	for _, rec := range batch {
+		key := labelsKey(rec.labels)
+
+		stream, ok := streams[key]
+		if !ok {
+			labels := make(map[string]string, len(rec.labels)+1)
+			for k, v := range rec.labels {
+				labels[k] = v
+			}
+			labels[replicaLabel] = r.host
+
+			stream = &loki.Stream{Labels: labels}
+			streams[key] = stream
+			keys = append(keys, key)
+		}
+
+		stream.Entries = append(stream.Entries, loki.Entry{
+			Timestamp:          rec.timestamp,
+			Line:               rec.line,
+			StructuredMetadata: rec.metadata,
+		})
+	}
+
+	req := &loki.PushRequest{}
+	for _, key := range keys {
+		req.Streams = append(req.Streams, *streams[key])
+	}
+
+	if err := r.client.Push(ctx, req); err != nil {
+		log.Printf("failed pushing %d log lines: %v", len(batch), err)
+	}
+}
+
+func labelsKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s=%q,", name, labels[name])
+	}
+	return b.String()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+	"github.com/observatorium/loki-benchmarks/internal/probe"
+	"github.com/observatorium/loki-benchmarks/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type options struct {
+	pushURL         string
+	queryURL        string
+	tenant          string
+	bearerTokenFile string
+	name            string
+	rate            float64
+	pollInterval    time.Duration
+	timeout         time.Duration
+	requestTimeout  time.Duration
+	metricsAddr     string
+}
+
+func main() {
+	opts := options{}
+
+	flag.StringVar(&opts.pushURL, "push-url", "", "Loki URL the canary lines are pushed to, e.g. http://distributor:3100.")
+	flag.StringVar(&opts.queryURL, "query-url", "", "Loki URL the canary lines are queried from, e.g. http://query-frontend:3100.")
+	flag.StringVar(&opts.tenant, "tenant", "", "Tenant ID sent with every request.")
+	flag.StringVar(&opts.bearerTokenFile, "bearer-token-file", "", "File containing the bearer token sent with every request.")
+	flag.StringVar(&opts.name, "name", "", "Value of the probe label of the canary stream. Defaults to the hostname.")
+	flag.Float64Var(&opts.rate, "rate", 1, "Number of canary lines pushed per second.")
+	flag.DurationVar(&opts.pollInterval, "poll-interval", time.Second, "Interval between two queries for the pending canary lines.")
+	flag.DurationVar(&opts.timeout, "timeout", 5*time.Minute, "Time after which a canary line not returned by a query is missing.")
+	flag.DurationVar(&opts.requestTimeout, "request-timeout", 5*time.Second, "Timeout of a single push or query request.")
+	flag.StringVar(&opts.metricsAddr, "metrics-addr", ":8080", "Address serving the client metrics. Empty disables the metrics server.")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(opts options) error {
+	if opts.pushURL == "" || opts.queryURL == "" {
+		return fmt.Errorf("missing Loki push or query URL")
+	}
+	if opts.rate <= 0 {
+		return fmt.Errorf("invalid rate: %g", opts.rate)
+	}
+	if opts.pollInterval <= 0 {
+		return fmt.Errorf("invalid poll-interval: %s", opts.pollInterval)
+	}
+	if opts.requestTimeout <= 0 {
+		return fmt.Errorf("invalid request-timeout: %s", opts.requestTimeout)
+	}
+
+	if opts.name == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("failed reading hostname: %w", err)
+		}
+		opts.name = hostname
+	}
+
+	// The pushes and queries share the client metrics, they differ by route.
+	pushClient, err := loki.NewClient(opts.pushURL, opts.tenant, opts.bearerTokenFile, opts.requestTimeout)
+	if err != nil {
+		return err
+	}
+	queryClient, err := loki.NewClient(opts.queryURL, opts.tenant, opts.bearerTokenFile, opts.requestTimeout)
+	if err != nil {
+		return err
+	}
+
+	reg := prometheus.NewRegistry()
+	if err := loki.Register(reg); err != nil {
+		return fmt.Errorf("failed registering client metrics: %w", err)
+	}
+	tenantReg := prometheus.WrapRegistererWith(prometheus.Labels{"tenant": opts.tenant}, reg)
+
+	p, err := probe.NewProbe(pushClient, queryClient, opts.name, tenantReg)
+	if err != nil {
+		return fmt.Errorf("failed registering probe metrics: %w", err)
+	}
+
+	if opts.metricsAddr != "" {
+		utils.ServeMetrics(opts.metricsAddr, reg)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	return p.Run(ctx, opts.rate, opts.pollInterval, opts.timeout)
+}
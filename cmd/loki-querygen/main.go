@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/dataset"
+	"github.com/observatorium/loki-benchmarks/internal/loadmodel"
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+	"github.com/observatorium/loki-benchmarks/internal/querygen"
+	"github.com/observatorium/loki-benchmarks/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type options struct {
+	url             string
+	tenant          string
+	bearerTokenFile string
+	queriesFile     string
+	qps             float64
+	concurrency     int
+	loop            string
+	arrival         string
+	maxInflight     int
+	once            bool
+	timeout         time.Duration
+	labelsLookback  time.Duration
+	labelsRefresh   time.Duration
+	tails           int
+	tailDelayFor    time.Duration
+	datasetSeed     int64
+	datasetStreams  int
+	datasetLPS      int
+	datasetStart    string
+	verify          bool
+	verifyInterval  time.Duration
+	verifyWindow    time.Duration
+	verifyLag       time.Duration
+	metricsAddr     string
+}
+
+func main() {
+	opts := options{}
+
+	flag.StringVar(&opts.url, "url", "", "Loki URL, e.g. http://query-frontend:3100.")
+	flag.StringVar(&opts.tenant, "tenant", "", "Tenant ID sent with every query.")
+	flag.StringVar(&opts.bearerTokenFile, "bearer-token-file", "", "File containing the bearer token sent with every query.")
+	flag.StringVar(&opts.queriesFile, "queries-file", "/etc/loki-querygen/queries.yaml", "File containing the query set.")
+	flag.Float64Var(&opts.qps, "qps", 0, "Target queries per second, required in an open loop. In a closed loop without qps, queries are sent back to back by concurrency workers.")
+	flag.IntVar(&opts.concurrency, "concurrency", 1, "Number of workers of a closed loop, or maximum number of queries in flight when qps is set. Open loops are bounded by max-inflight.")
+	flag.StringVar(&opts.loop, "loop", loadmodel.LoopClosed, "Arrival model: closed waits for a free worker, open sends at qps regardless of latency.")
+	flag.StringVar(&opts.arrival, "arrival", loadmodel.ArrivalConstant, "Spacing of the queries in an open loop: constant or poisson.")
+	flag.IntVar(&opts.maxInflight, "max-inflight", 1000, "Maximum number of queries in flight in an open loop, further queries are dropped.")
+	flag.BoolVar(&opts.once, "once", false, "If set, send every query of the set once and exit, e.g. to prime the caches.")
+	flag.DurationVar(&opts.timeout, "timeout", 5*time.Minute, "Timeout of a single query.")
+	flag.DurationVar(&opts.labelsLookback, "labels-lookback", time.Hour, "Range the label values of the template variables are fetched for.")
+	flag.DurationVar(&opts.labelsRefresh, "labels-refresh", 5*time.Minute, "Interval between two refreshes of the label values of the template variables.")
+	flag.IntVar(&opts.tails, "tails", 0, "If set, keep this many websocket tails of the queries open instead of sending queries.")
+	flag.DurationVar(&opts.tailDelayFor, "tail-delay-for", 0, "Delay Loki applies to the tailed log lines, at most 5s.")
+	flag.BoolVar(&opts.verify, "verify", false, "If set, verify the query results over the deterministic dataset instead of sending the query set.")
+	flag.Int64Var(&opts.datasetSeed, "dataset-seed", 1, "Seed of the deterministic dataset.")
+	flag.IntVar(&opts.datasetStreams, "dataset-streams", 1, "Number of streams of the deterministic dataset.")
+	flag.IntVar(&opts.datasetLPS, "dataset-lines-per-second", 10, "Number of log lines per second and stream of the deterministic dataset.")
+	flag.StringVar(&opts.datasetStart, "dataset-start", "", "RFC 3339 time the dataset is pushed from. Windows starting earlier are not verified.")
+	flag.DurationVar(&opts.verifyInterval, "verify-interval", 30*time.Second, "Interval between two verifications.")
+	flag.DurationVar(&opts.verifyWindow, "verify-window", time.Minute, "Window of the dataset a verification covers, in whole seconds.")
+	flag.DurationVar(&opts.verifyLag, "verify-lag", time.Minute, "Age of the end of the verified window, must cover the ingestion delay.")
+	flag.StringVar(&opts.metricsAddr, "metrics-addr", ":8080", "Address serving the client metrics. Empty disables the metrics server.")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(opts options) error {
+	if opts.url == "" {
+		return fmt.Errorf("missing Loki URL")
+	}
+	if opts.concurrency < 1 {
+		return fmt.Errorf("invalid concurrency: %d", opts.concurrency)
+	}
+
+	switch opts.loop {
+	case loadmodel.LoopClosed:
+	case loadmodel.LoopOpen:
+		if opts.qps <= 0 {
+			return fmt.Errorf("open loop requires a positive qps")
+		}
+		if opts.arrival != loadmodel.ArrivalConstant && opts.arrival != loadmodel.ArrivalPoisson {
+			return fmt.Errorf("unsupported arrival: %s", opts.arrival)
+		}
+		if opts.maxInflight < 1 {
+			return fmt.Errorf("invalid max-inflight: %d", opts.maxInflight)
+		}
+	default:
+		return fmt.Errorf("unsupported loop: %s", opts.loop)
+	}
+
+	client, err := loki.NewClient(opts.url, opts.tenant, opts.bearerTokenFile, opts.timeout)
+	if err != nil {
+		return err
+	}
+
+	reg := prometheus.NewRegistry()
+	if err := loki.Register(reg); err != nil {
+		return fmt.Errorf("failed registering client metrics: %w", err)
+	}
+	tenantReg := prometheus.WrapRegistererWith(prometheus.Labels{"tenant": opts.tenant}, reg)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if opts.verify {
+		return verify(ctx, client, tenantReg, reg, opts)
+	}
+
+	set, err := querygen.LoadQuerySet(opts.queriesFile)
+	if err != nil {
+		return err
+	}
+
+	if opts.tails > 0 {
+		tailer, err := querygen.NewTailer(client, set, tenantReg)
+		if err != nil {
+			return fmt.Errorf("failed registering tail metrics: %w", err)
+		}
+
+		if opts.metricsAddr != "" {
+			utils.ServeMetrics(opts.metricsAddr, reg)
+		}
+
+		return tailer.Run(ctx, opts.tails, opts.tailDelayFor)
+	}
+
+	runner, err := querygen.NewRunner(client, set, tenantReg)
+	if err != nil {
+		return fmt.Errorf("failed registering query metrics: %w", err)
+	}
+
+	if opts.metricsAddr != "" {
+		utils.ServeMetrics(opts.metricsAddr, reg)
+	}
+
+	if err := runner.LoadVariables(ctx, opts.labelsRefresh, opts.labelsLookback); err != nil {
+		return err
+	}
+
+	if opts.once {
+		return runner.RunOnce(ctx)
+	}
+
+	if opts.loop == loadmodel.LoopOpen {
+		return runner.RunOpenLoop(ctx, opts.qps, opts.arrival, opts.maxInflight)
+	}
+
+	if opts.qps > 0 {
+		return runner.RunQPS(ctx, opts.qps, opts.concurrency)
+	}
+
+	return runner.RunConcurrency(ctx, opts.concurrency)
+}
+
+func verify(ctx context.Context, client *loki.Client, tenantReg prometheus.Registerer, reg *prometheus.Registry, opts options) error {
+	d := &dataset.Dataset{Seed: opts.datasetSeed, Streams: opts.datasetStreams, LinesPerSecond: opts.datasetLPS}
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	if opts.verifyWindow < time.Second || opts.verifyWindow%time.Second != 0 {
+		return fmt.Errorf("invalid verify-window: %s", opts.verifyWindow)
+	}
+	if opts.verifyInterval <= 0 {
+		return fmt.Errorf("invalid verify-interval: %s", opts.verifyInterval)
+	}
+
+	var start time.Time
+	if opts.datasetStart != "" {
+		var err error
+		start, err = time.Parse(time.RFC3339, opts.datasetStart)
+		if err != nil {
+			return fmt.Errorf("invalid dataset-start: %w", err)
+		}
+	}
+
+	verifier, err := querygen.NewVerifier(client, d, tenantReg)
+	if err != nil {
+		return fmt.Errorf("failed registering verification metrics: %w", err)
+	}
+
+	if opts.metricsAddr != "" {
+		utils.ServeMetrics(opts.metricsAddr, reg)
+	}
+
+	return verifier.Run(ctx, start, opts.verifyInterval, opts.verifyWindow, opts.verifyLag)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+	"github.com/observatorium/loki-benchmarks/internal/ruler"
+
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8sconfig "sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+type options struct {
+	url                string
+	tenant             string
+	bearerTokenFile    string
+	rulesFile          string
+	lookback           time.Duration
+	configMap          string
+	configMapNamespace string
+	timeout            time.Duration
+}
+
+func main() {
+	opts := options{}
+
+	flag.StringVar(&opts.url, "url", "", "Loki URL, e.g. http://ruler:3100.")
+	flag.StringVar(&opts.tenant, "tenant", "", "Tenant ID sent with every request.")
+	flag.StringVar(&opts.bearerTokenFile, "bearer-token-file", "", "File containing the bearer token sent with every request.")
+	flag.StringVar(&opts.rulesFile, "rules-file", "", "File containing the rule set.")
+	flag.DurationVar(&opts.lookback, "lookback", time.Hour, "Range the values of the template variables are looked up in.")
+	flag.StringVar(&opts.configMap, "configmap", "", "If set, write the rules into this ConfigMap instead of loading them through the ruler API.")
+	flag.StringVar(&opts.configMapNamespace, "configmap-namespace", "", "Namespace of the rules ConfigMap.")
+	flag.DurationVar(&opts.timeout, "timeout", time.Minute, "Timeout of a single request.")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run loads the rules and keeps them until the pod is stopped.
+func run(opts options) error {
+	if opts.url == "" {
+		return fmt.Errorf("missing Loki URL")
+	}
+
+	set, err := ruler.LoadRuleSet(opts.rulesFile)
+	if err != nil {
+		return err
+	}
+
+	lokiClient, err := loki.NewClient(opts.url, opts.tenant, opts.bearerTokenFile, opts.timeout)
+	if err != nil {
+		return err
+	}
+
+	var store ruler.Store = ruler.NewAPIStore(lokiClient)
+	if opts.configMap != "" {
+		cfg, err := k8sconfig.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed reading kubernetes configuration: %w", err)
+		}
+
+		k8sClient, err := client.New(cfg, client.Options{Scheme: scheme.Scheme})
+		if err != nil {
+			return fmt.Errorf("failed creating kubernetes client: %w", err)
+		}
+
+		store = ruler.NewConfigMapStore(k8sClient, opts.configMap, opts.configMapNamespace)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	values, err := variableValues(ctx, lokiClient, set.Variables, opts.lookback)
+	if err != nil {
+		return err
+	}
+
+	groups := set.Generate(values)
+	if err := store.Load(ctx, set.Namespace, groups); err != nil {
+		return err
+	}
+	log.Printf("loaded %d rule groups of %d rules into namespace %s", len(groups), set.RulesPerGroup, set.Namespace)
+
+	<-ctx.Done()
+
+	unloadCtx, unloadCancel := context.WithTimeout(context.Background(), opts.timeout)
+	defer unloadCancel()
+
+	if err := store.Unload(unloadCtx, set.Namespace); err != nil {
+		return err
+	}
+	log.Printf("unloaded rule namespace %s", set.Namespace)
+
+	return nil
+}
+
+// variableValues returns the values of the label of every variable.
+func variableValues(ctx context.Context, c *loki.Client, variables map[string]string, lookback time.Duration) (map[string][]string, error) {
+	end := time.Now()
+
+	values := make(map[string][]string, len(variables))
+	for name, label := range variables {
+		vals, err := c.LabelValues(ctx, label, "", end.Add(-lookback), end)
+		if err != nil {
+			return nil, fmt.Errorf("failed fetching values of label %s: %w", label, err)
+		}
+		if len(vals) == 0 {
+			return nil, fmt.Errorf("no values for label %s of variable %s", label, name)
+		}
+
+		values[name] = vals
+	}
+
+	return values, nil
+}
@@ -0,0 +1,165 @@
+// Package audit reconciles the log lines stored in Loki with the lines the
+// generators report as accepted. Every audited generator tags its lines with
+// its stream name and a sequence number and pushes a report of the accepted
+// sequence numbers when it stops.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// ReportLabel marks the stream holding the generator reports.
+	ReportLabel = "loki_benchmarks_audit"
+
+	// StreamKey and SeqKey are the logfmt keys added to every audited line.
+	StreamKey = "audit"
+	SeqKey    = "seq"
+)
+
+// ReportSelector returns the stream selector of the generator reports.
+func ReportSelector() string {
+	return fmt.Sprintf(`{%s="report"}`, ReportLabel)
+}
+
+// ReportLabels returns the stream labels of the generator reports.
+func ReportLabels() map[string]string {
+	return map[string]string{ReportLabel: "report"}
+}
+
+// Report describes the lines a generator pushed to a single stream. The
+// sequence numbers run from 0 to Next-1, those of the Rejected ranges were
+// not accepted by Loki.
+type Report struct {
+	Stream   string     `json:"stream"`
+	Selector string     `json:"selector"`
+	Start    time.Time  `json:"start"`
+	End      time.Time  `json:"end"`
+	Next     int64      `json:"next"`
+	Accepted int64      `json:"accepted"`
+	Rejected [][2]int64 `json:"rejected,omitempty"`
+}
+
+// Reject records the sequence numbers [from, to) as rejected.
+func (r *Report) Reject(from, to int64) {
+	if n := len(r.Rejected); n > 0 && r.Rejected[n-1][1] == from {
+		r.Rejected[n-1][1] = to
+		return
+	}
+	r.Rejected = append(r.Rejected, [2]int64{from, to})
+}
+
+func (r *Report) rejected(seq int64) bool {
+	i := sort.Search(len(r.Rejected), func(i int) bool { return r.Rejected[i][1] > seq })
+	return i < len(r.Rejected) && r.Rejected[i][0] <= seq
+}
+
+func (r *Report) Marshal() (string, error) {
+	out, err := json.Marshal(r)
+	if err != nil {
+		return "", fmt.Errorf("failed encoding audit report: %w", err)
+	}
+	return string(out), nil
+}
+
+func ParseReport(line string) (*Report, error) {
+	r := &Report{}
+	if err := json.Unmarshal([]byte(line), r); err != nil {
+		return nil, fmt.Errorf("failed decoding audit report: %w", err)
+	}
+	return r, nil
+}
+
+// StreamResult is the reconciliation of a single stream.
+type StreamResult struct {
+	Stream     string `json:"stream"`
+	Expected   int64  `json:"expected"`
+	Found      int64  `json:"found"`
+	Missing    int64  `json:"missing"`
+	Duplicated int64  `json:"duplicated"`
+	OutOfOrder int64  `json:"outOfOrder"`
+	Unexpected int64  `json:"unexpected"`
+}
+
+// Reconcile compares the sequence numbers found in Loki, in timestamp order,
+// with the report. Lines with rejected or unknown sequence numbers are
+// counted as unexpected, lines found more than once as duplicated and lines
+// with a lower sequence number than their predecessor as out of order.
+func Reconcile(r *Report, seqs []int64) StreamResult {
+	res := StreamResult{Stream: r.Stream, Expected: r.Accepted, Found: int64(len(seqs))}
+
+	seen := make(map[int64]bool, len(seqs))
+	for i, seq := range seqs {
+		if i > 0 && seq < seqs[i-1] {
+			res.OutOfOrder++
+		}
+
+		if seq < 0 || seq >= r.Next || r.rejected(seq) {
+			res.Unexpected++
+			continue
+		}
+
+		if seen[seq] {
+			res.Duplicated++
+			continue
+		}
+		seen[seq] = true
+	}
+
+	res.Missing = r.Accepted - int64(len(seen))
+	if res.Missing < 0 {
+		// The report undercounts, e.g. a push timed out after Loki stored it.
+		res.Missing = 0
+	}
+
+	return res
+}
+
+// ParseSeq parses a sequence number as written by line_format.
+func ParseSeq(value string) (int64, error) {
+	seq, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid sequence number %q: %w", value, err)
+	}
+	return seq, nil
+}
+
+// Summary holds the reconciliation of all reported streams.
+type Summary struct {
+	Tolerance  float64        `json:"tolerance"`
+	Streams    int            `json:"streams"`
+	Expected   int64          `json:"expected"`
+	Missing    int64          `json:"missing"`
+	Duplicated int64          `json:"duplicated"`
+	OutOfOrder int64          `json:"outOfOrder"`
+	Unexpected int64          `json:"unexpected"`
+	Pass       bool           `json:"pass"`
+	Results    []StreamResult `json:"results,omitempty"`
+}
+
+// Summarize adds up the stream results. The audit passes if the missing,
+// duplicated and out of order lines stay within the tolerated fraction of
+// the expected lines.
+func Summarize(results []StreamResult, tolerance float64) *Summary {
+	s := &Summary{Tolerance: tolerance, Streams: len(results), Results: results}
+
+	for _, r := range results {
+		s.Expected += r.Expected
+		s.Missing += r.Missing
+		s.Duplicated += r.Duplicated
+		s.OutOfOrder += r.OutOfOrder
+		s.Unexpected += r.Unexpected
+	}
+
+	s.Pass = len(results) > 0 && float64(s.Errors()) <= tolerance*float64(s.Expected)
+	return s
+}
+
+// Errors returns the number of lines failing the audit.
+func (s *Summary) Errors() int64 {
+	return s.Missing + s.Duplicated + s.OutOfOrder
+}
@@ -0,0 +1,136 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReportReject(t *testing.T) {
+	r := &Report{Next: 20}
+	r.Reject(2, 4)
+	r.Reject(4, 6)
+	r.Reject(10, 11)
+
+	if want := [][2]int64{{2, 6}, {10, 11}}; !reflect.DeepEqual(r.Rejected, want) {
+		t.Fatalf("got rejected ranges %v, want %v", r.Rejected, want)
+	}
+
+	for seq, want := range map[int64]bool{0: false, 1: false, 2: true, 5: true, 6: false, 9: false, 10: true, 11: false} {
+		if got := r.rejected(seq); got != want {
+			t.Errorf("got rejected(%d) %v, want %v", seq, got, want)
+		}
+	}
+}
+
+func TestReportMarshal(t *testing.T) {
+	r := &Report{Stream: "s", Selector: `{job="a"}`, Next: 3, Accepted: 2, Rejected: [][2]int64{{1, 2}}}
+
+	line, err := r.Marshal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := ParseReport(line)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, r) {
+		t.Errorf("got %+v, want %+v", got, r)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	report := &Report{Stream: "s", Next: 6, Accepted: 5, Rejected: [][2]int64{{3, 4}}}
+
+	tests := []struct {
+		name string
+		seqs []int64
+		want StreamResult
+	}{
+		{
+			name: "all accepted lines found",
+			seqs: []int64{0, 1, 2, 4, 5},
+			want: StreamResult{Stream: "s", Expected: 5, Found: 5},
+		},
+		{
+			name: "missing lines",
+			seqs: []int64{0, 2, 5},
+			want: StreamResult{Stream: "s", Expected: 5, Found: 3, Missing: 2},
+		},
+		{
+			name: "duplicated lines",
+			seqs: []int64{0, 1, 1, 2, 4, 5},
+			want: StreamResult{Stream: "s", Expected: 5, Found: 6, Duplicated: 1},
+		},
+		{
+			name: "out of order lines",
+			seqs: []int64{0, 2, 1, 4, 5},
+			want: StreamResult{Stream: "s", Expected: 5, Found: 5, OutOfOrder: 1},
+		},
+		{
+			name: "rejected and unknown lines",
+			seqs: []int64{0, 1, 2, 3, 4, 5, 6},
+			want: StreamResult{Stream: "s", Expected: 5, Found: 7, Unexpected: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Reconcile(report, tt.seqs); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name      string
+		results   []StreamResult
+		tolerance float64
+		errors    int64
+		pass      bool
+	}{
+		{
+			name:    "no streams",
+			results: nil,
+			pass:    false,
+		},
+		{
+			name:    "no errors",
+			results: []StreamResult{{Expected: 100, Unexpected: 3}, {Expected: 100}},
+			pass:    true,
+		},
+		{
+			name:    "errors without tolerance",
+			results: []StreamResult{{Expected: 100, Missing: 1}, {Expected: 100}},
+			errors:  1,
+			pass:    false,
+		},
+		{
+			name:      "errors within tolerance",
+			results:   []StreamResult{{Expected: 100, Missing: 1, Duplicated: 1}, {Expected: 100, OutOfOrder: 2}},
+			tolerance: 0.02,
+			errors:    4,
+			pass:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Summarize(tt.results, tt.tolerance)
+			if s.Errors() != tt.errors || s.Pass != tt.pass || s.Streams != len(tt.results) {
+				t.Errorf("got %+v, want %d errors and pass %v", s, tt.errors, tt.pass)
+			}
+		})
+	}
+}
+
+func TestParseSeq(t *testing.T) {
+	if seq, err := ParseSeq("42"); err != nil || seq != 42 {
+		t.Errorf("got %d, %v", seq, err)
+	}
+	if _, err := ParseSeq("seq=42"); err == nil {
+		t.Error("expected an error")
+	}
+}
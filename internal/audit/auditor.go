@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+)
+
+// pageSize matches the default max_entries_limit_per_query of Loki.
+const pageSize = 5000
+
+type Auditor struct {
+	client *loki.Client
+}
+
+func NewAuditor(client *loki.Client) *Auditor {
+	return &Auditor{client: client}
+}
+
+// Run reconciles every stream reported between start and end.
+func (a *Auditor) Run(ctx context.Context, start, end time.Time, tolerance float64) (*Summary, error) {
+	reports, err := a.reports(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]StreamResult, 0, len(reports))
+	for _, r := range reports {
+		seqs, err := a.sequence(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+
+		res := Reconcile(r, seqs)
+		log.Printf("audited stream %s: %d expected, %d missing, %d duplicated, %d out of order, %d unexpected",
+			res.Stream, res.Expected, res.Missing, res.Duplicated, res.OutOfOrder, res.Unexpected)
+
+		results = append(results, res)
+	}
+
+	return Summarize(results, tolerance), nil
+}
+
+// reports returns the latest report of every stream.
+func (a *Auditor) reports(ctx context.Context, start, end time.Time) ([]*Report, error) {
+	lines, err := a.lines(ctx, ReportSelector(), start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying audit reports: %w", err)
+	}
+
+	latest := map[string]*Report{}
+	for _, line := range lines {
+		r, err := ParseReport(line.Line)
+		if err != nil {
+			return nil, err
+		}
+		latest[r.Stream] = r
+	}
+
+	reports := make([]*Report, 0, len(latest))
+	for _, r := range latest {
+		reports = append(reports, r)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Stream < reports[j].Stream })
+
+	return reports, nil
+}
+
+// sequence returns the sequence numbers of the reported stream in timestamp
+// order. Loki only returns the sequence numbers to keep the responses small.
+func (a *Auditor) sequence(ctx context.Context, r *Report) ([]int64, error) {
+	query := fmt.Sprintf(
+		`%s |= "%s=%s " | line_format `+"`"+`{{ __line__ | regexReplaceAll "^.* %s=([0-9]+) .*$" "${1}" }}`+"`",
+		r.Selector, StreamKey, r.Stream, SeqKey,
+	)
+
+	lines, err := a.lines(ctx, query, r.Start, r.End.Add(1))
+	if err != nil {
+		return nil, fmt.Errorf("failed querying stream %s: %w", r.Stream, err)
+	}
+
+	seqs := make([]int64, 0, len(lines))
+	for _, line := range lines {
+		seq, err := ParseSeq(line.Line)
+		if err != nil {
+			return nil, err
+		}
+		seqs = append(seqs, seq)
+	}
+
+	return seqs, nil
+}
+
+// lines pages backwards through all log lines of the query in [start, end)
+// and returns them in timestamp order.
+func (a *Auditor) lines(ctx context.Context, query string, start, end time.Time) ([]loki.Entry, error) {
+	var entries []loki.Entry
+
+	for end.After(start) {
+		res, err := a.client.QueryRange(loki.WithQueryName(ctx, "audit"), query, start, end, 0, pageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		var streams []loki.StreamResult
+		if err := json.Unmarshal(res.Data.Result, &streams); err != nil {
+			return nil, fmt.Errorf("failed decoding log lines: %w", err)
+		}
+
+		n := 0
+		oldest := end
+		for _, s := range streams {
+			for _, value := range s.Values {
+				if len(value) < 2 {
+					continue
+				}
+
+				nanos, err := strconv.ParseInt(value[0], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid timestamp %q: %w", value[0], err)
+				}
+
+				ts := time.Unix(0, nanos)
+				if ts.Before(oldest) {
+					oldest = ts
+				}
+
+				entries = append(entries, loki.Entry{Timestamp: ts, Line: value[1]})
+				n++
+			}
+		}
+
+		if n < pageSize {
+			break
+		}
+		end = oldest
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Timestamp.Before(entries[j].Timestamp) })
+
+	return entries, nil
+}
@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+)
+
+// fakeLoki serves the lines of the report stream and the sequence numbers of
+// the audited stream backwards in pages of at most limit lines.
+func fakeLoki(t *testing.T, reports []loki.Entry, seqs []loki.Entry) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		start, _ := strconv.ParseInt(params.Get("start"), 10, 64)
+		end, _ := strconv.ParseInt(params.Get("end"), 10, 64)
+		limit, _ := strconv.Atoi(params.Get("limit"))
+
+		entries := seqs
+		if strings.HasPrefix(params.Get("query"), ReportSelector()) {
+			entries = reports
+		}
+
+		var values [][]string
+		for i := len(entries) - 1; i >= 0 && len(values) < limit; i-- {
+			ts := entries[i].Timestamp.UnixNano()
+			if ts >= start && ts < end {
+				values = append(values, []string{strconv.FormatInt(ts, 10), entries[i].Line})
+			}
+		}
+
+		out := map[string]interface{}{"status": "success", "data": map[string]interface{}{
+			"resultType": "streams",
+			"result":     []loki.StreamResult{{Labels: map[string]string{}, Values: values}},
+		}}
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			t.Errorf("failed encoding response: %v", err)
+		}
+	}))
+}
+
+func TestAuditorRun(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	// More lines than a page, with the line of sequence number 7 lost and
+	// that of 8 stored twice.
+	const n = pageSize + 100
+
+	var seqs []loki.Entry
+	for i := int64(0); i < n; i++ {
+		if i == 7 {
+			continue
+		}
+		seqs = append(seqs, loki.Entry{Timestamp: start.Add(time.Duration(i) * time.Millisecond), Line: strconv.FormatInt(i, 10)})
+		if i == 8 {
+			seqs = append(seqs, loki.Entry{Timestamp: start.Add(time.Duration(i)*time.Millisecond + 1), Line: "8"})
+		}
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i].Timestamp.Before(seqs[j].Timestamp) })
+
+	end := seqs[len(seqs)-1].Timestamp
+	stale := &Report{Stream: "s", Selector: `{job="a"}`, Start: start, End: end, Next: 1, Accepted: 1}
+	latest := &Report{Stream: "s", Selector: `{job="a"}`, Start: start, End: end, Next: n, Accepted: n}
+
+	var reports []loki.Entry
+	for i, r := range []*Report{stale, latest} {
+		line, err := r.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		reports = append(reports, loki.Entry{Timestamp: end.Add(time.Duration(i+1) * time.Second), Line: line})
+	}
+
+	srv := fakeLoki(t, reports, seqs)
+	defer srv.Close()
+
+	client, err := loki.NewClient(srv.URL, "tenant", "", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewAuditor(client).Run(context.Background(), start, end.Add(time.Minute), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := StreamResult{Stream: "s", Expected: n, Found: n, Missing: 1, Duplicated: 1}
+	if len(s.Results) != 1 || s.Results[0] != want {
+		t.Fatalf("got results %+v, want %+v", s.Results, want)
+	}
+	if s.Pass {
+		t.Error("audit passed despite the lost line")
+	}
+}
@@ -0,0 +1,45 @@
+package chaos
+
+import (
+	"time"
+)
+
+const (
+	PhaseBefore = "before"
+	PhaseDuring = "during"
+	PhaseAfter  = "after"
+)
+
+// Event is the timeline of an injected fault. It starts with the injection
+// and ends once the workload is ready again. Recovery is the time between
+// reverting the fault, or injecting it if it is not reverted, and the end.
+type Event struct {
+	Name     string
+	Start    time.Time
+	End      time.Time
+	Recovery time.Duration
+	Err      error
+}
+
+// Phase returns the phase of a sample taken at ts over the window before
+// it: before the fault if the sample ended before the injection, after it if
+// the window started after the recovery and during the fault otherwise.
+func (e Event) Phase(ts time.Time, window time.Duration) string {
+	switch {
+	case !ts.After(e.Start):
+		return PhaseBefore
+	case !e.End.IsZero() && !ts.Add(-window).Before(e.End):
+		return PhaseAfter
+	default:
+		return PhaseDuring
+	}
+}
+
+// Phases returns the phase of every sample.
+func (e Event) Phases(samples []time.Time, window time.Duration) []string {
+	phases := make([]string, 0, len(samples))
+	for _, ts := range samples {
+		phases = append(phases, e.Phase(ts, window))
+	}
+	return phases
+}
@@ -0,0 +1,46 @@
+package chaos
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEventPhase(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	window := time.Minute
+
+	tests := []struct {
+		name  string
+		event Event
+		ts    time.Time
+		want  string
+	}{
+		{name: "sample before the injection", event: Event{Start: start}, ts: start.Add(-time.Second), want: PhaseBefore},
+		{name: "sample at the injection", event: Event{Start: start}, ts: start, want: PhaseBefore},
+		{name: "sample after the injection", event: Event{Start: start, End: start.Add(time.Minute)}, ts: start.Add(time.Second), want: PhaseDuring},
+		{name: "window overlapping the recovery", event: Event{Start: start, End: start.Add(time.Minute)}, ts: start.Add(90 * time.Second), want: PhaseDuring},
+		{name: "window starting at the recovery", event: Event{Start: start, End: start.Add(time.Minute)}, ts: start.Add(2 * time.Minute), want: PhaseAfter},
+		{name: "not recovered", event: Event{Start: start}, ts: start.Add(time.Hour), want: PhaseDuring},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.event.Phase(tt.ts, window); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventPhases(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	e := Event{Start: start, End: start.Add(time.Minute)}
+
+	samples := []time.Time{start.Add(-time.Minute), start.Add(time.Minute), start.Add(3 * time.Minute)}
+	want := []string{PhaseBefore, PhaseDuring, PhaseAfter}
+
+	if got := e.Phases(samples, time.Minute); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,362 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+	"github.com/observatorium/loki-benchmarks/internal/readiness"
+	"github.com/observatorium/loki-benchmarks/internal/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	latencyContainer = "chaos-latency"
+
+	// defaultLatencyImage ships the tc command of iproute2.
+	defaultLatencyImage = "docker.io/nicolaka/netshoot:latest"
+
+	// revertTimeout bounds the revert of a fault, which does not wait for
+	// the workload to recover.
+	revertTimeout = time.Minute
+)
+
+type Injector struct {
+	client  client.Client
+	retry   time.Duration
+	timeout time.Duration
+
+	mu      sync.Mutex
+	pending map[string]revertFunc
+}
+
+// revertFunc reverts a fault. It is only run once, later calls return the
+// error of the first one.
+type revertFunc func(ctx context.Context) error
+
+// NewInjector returns an injector waiting up to timeout for a workload to
+// become ready again after a fault.
+func NewInjector(c client.Client, retry, timeout time.Duration) *Injector {
+	return &Injector{client: c, retry: retry, timeout: timeout, pending: map[string]revertFunc{}}
+}
+
+// Revert reverts the faults injected but not reverted yet, e.g. because the
+// run was cancelled. It is safe to call concurrently with Run.
+func (i *Injector) Revert(ctx context.Context) error {
+	i.mu.Lock()
+	reverts := make([]revertFunc, 0, len(i.pending))
+	for _, revert := range i.pending {
+		reverts = append(reverts, revert)
+	}
+	i.mu.Unlock()
+
+	var errs []error
+	for _, revert := range reverts {
+		if err := revert(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// track keeps the revert of the fault pending until it ran once.
+func (i *Injector) track(name string, revert revertFunc) revertFunc {
+	var (
+		once sync.Once
+		err  error
+	)
+
+	tracked := func(ctx context.Context) error {
+		once.Do(func() {
+			err = revert(ctx)
+
+			i.mu.Lock()
+			delete(i.pending, name)
+			i.mu.Unlock()
+		})
+		return err
+	}
+
+	i.mu.Lock()
+	i.pending[name] = tracked
+	i.mu.Unlock()
+
+	return tracked
+}
+
+// Run injects every fault at its offset after start, concurrently, and
+// returns their events once all workloads recovered.
+func (i *Injector) Run(ctx context.Context, start time.Time, faults []*config.Fault) []Event {
+	events := make([]Event, len(faults))
+
+	var wg sync.WaitGroup
+	for idx, fault := range faults {
+		idx, fault := idx, fault
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case <-ctx.Done():
+				events[idx] = Event{Name: fault.Name, Err: ctx.Err()}
+				return
+			case <-time.After(time.Until(start.Add(fault.Offset))):
+			}
+
+			events[idx] = i.inject(ctx, fault)
+			if err := events[idx].Err; err != nil {
+				log.Printf("fault %s failed: %v", fault.Name, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.SliceStable(events, func(a, b int) bool { return events[a].Start.Before(events[b].Start) })
+	return events
+}
+
+func (i *Injector) inject(ctx context.Context, fault *config.Fault) Event {
+	event := Event{Name: fault.Name, Start: time.Now()}
+
+	w, err := newWorkload(fault)
+	if err != nil {
+		event.Err = err
+		return event
+	}
+
+	var (
+		revert  revertFunc
+		replace func(ctx context.Context) error
+	)
+	switch fault.Type {
+	case config.FaultDeletePods:
+		var deleted map[types.UID]bool
+		deleted, err = i.deletePods(ctx, w, fault.Count)
+		replace = func(ctx context.Context) error { return i.waitReplaced(ctx, w, deleted) }
+	case config.FaultScale:
+		var replicas int32
+		replicas, err = i.scale(ctx, w, fault.Replicas)
+		revert = func(ctx context.Context) error {
+			_, err := i.scale(ctx, w, replicas)
+			return err
+		}
+	case config.FaultLatency:
+		err = i.addLatency(ctx, w, fault)
+		revert = func(ctx context.Context) error { return i.removeLatency(ctx, w, fault) }
+	default:
+		err = fmt.Errorf("unsupported fault type: %s", fault.Type)
+	}
+	if err != nil {
+		event.Err = fmt.Errorf("failed injecting fault: %w", err)
+		return event
+	}
+
+	if revert != nil {
+		revert = i.track(fault.Name, revert)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(fault.Duration):
+		}
+
+		// The workload must be reverted even if the run was cancelled.
+		revertCtx, cancel := context.WithTimeout(context.Background(), revertTimeout)
+		defer cancel()
+
+		if err := revert(revertCtx); err != nil {
+			event.Err = fmt.Errorf("failed reverting fault: %w", err)
+			return event
+		}
+	}
+
+	reverted := time.Now()
+	if replace != nil {
+		if err := replace(ctx); err != nil {
+			event.Err = fmt.Errorf("deleted pods were not replaced: %w", err)
+			return event
+		}
+	}
+	if err := readiness.NewWaiter(i.client, i.retry, i.timeout, nil).Wait(ctx, w.obj); err != nil {
+		event.Err = fmt.Errorf("workload did not recover: %w", err)
+		return event
+	}
+
+	event.End = time.Now()
+	event.Recovery = event.End.Sub(reverted)
+	log.Printf("fault %s lasted %s, recovered in %s", fault.Name, event.End.Sub(event.Start), event.Recovery)
+
+	return event
+}
+
+// deletePods deletes count pods of the workload, at least one, and returns
+// the UIDs of the deleted pods.
+func (i *Injector) deletePods(ctx context.Context, w *workload, count int) (map[types.UID]bool, error) {
+	pods, err := i.pods(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(pods, func(a, b int) bool { return pods[a].Name < pods[b].Name })
+
+	if count <= 0 {
+		count = 1
+	}
+	if count > len(pods) {
+		count = len(pods)
+	}
+
+	deleted := map[types.UID]bool{}
+	for idx := 0; idx < count; idx++ {
+		if err := client.IgnoreNotFound(i.client.Delete(ctx, &pods[idx])); err != nil {
+			return nil, err
+		}
+		deleted[pods[idx].UID] = true
+	}
+
+	return deleted, nil
+}
+
+// waitReplaced waits until the deleted pods are gone and as many pods as the
+// workload has replicas are ready. Right after the deletion the status of the
+// workload still counts the deleted pods as ready.
+func (i *Injector) waitReplaced(ctx context.Context, w *workload, deleted map[types.UID]bool) error {
+	return wait.PollImmediateWithContext(ctx, i.retry, i.timeout, func(ctx context.Context) (bool, error) {
+		pods, err := i.pods(ctx, w)
+		if err != nil {
+			return false, err
+		}
+
+		var ready int32
+		for idx := range pods {
+			pod := &pods[idx]
+			if deleted[pod.UID] {
+				return false, nil
+			}
+			if pod.DeletionTimestamp == nil && utils.IsPodReady(pod) {
+				ready++
+			}
+		}
+
+		return ready >= w.replicas(), nil
+	})
+}
+
+// pods returns the pods selected by the latest version of the workload.
+func (i *Injector) pods(ctx context.Context, w *workload) ([]corev1.Pod, error) {
+	if err := w.get(ctx, i.client); err != nil {
+		return nil, err
+	}
+
+	selector, err := w.selector()
+	if err != nil {
+		return nil, err
+	}
+
+	pods := &corev1.PodList{}
+	err = i.client.List(ctx, pods, client.InNamespace(w.obj.GetNamespace()), client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	return pods.Items, nil
+}
+
+// scale sets the replicas of the workload and returns the previous ones.
+func (i *Injector) scale(ctx context.Context, w *workload, replicas int32) (int32, error) {
+	var previous int32
+
+	err := i.update(ctx, w, func() {
+		previous = w.replicas()
+		w.setReplicas(replicas)
+	})
+
+	return previous, err
+}
+
+// addLatency delays all egress traffic of the pods of the workload with tc,
+// run by an ephemeral container sharing the network namespace of every pod.
+// Unlike a sidecar it does not roll out the workload, so pods created during
+// the fault, e.g. by a restart, run without the delay.
+func (i *Injector) addLatency(ctx context.Context, w *workload, fault *config.Fault) error {
+	delay := fmt.Sprintf("%dms", fault.Latency.Milliseconds())
+	return i.runInPods(ctx, w, fault, "add", "tc qdisc replace dev eth0 root netem delay "+delay)
+}
+
+// removeLatency removes the delay from the pods again. The terminated
+// ephemeral containers stay in the pod specs.
+func (i *Injector) removeLatency(ctx context.Context, w *workload, fault *config.Fault) error {
+	return i.runInPods(ctx, w, fault, "remove", "tc qdisc del dev eth0 root")
+}
+
+// runInPods runs the command in a new ephemeral container of every pod of
+// the workload. Pods deleted in the meantime are skipped.
+func (i *Injector) runInPods(ctx context.Context, w *workload, fault *config.Fault, action, command string) error {
+	image := defaultLatencyImage
+	if fault.Image != "" {
+		image = fault.Image
+	}
+
+	pods, err := i.pods(ctx, w)
+	if err != nil {
+		return err
+	}
+
+	for idx := range pods {
+		pod := &pods[idx]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := i.client.Get(ctx, client.ObjectKeyFromObject(pod), pod); err != nil {
+				return err
+			}
+
+			// Ephemeral containers can neither be removed nor renamed.
+			name := fmt.Sprintf("%s-%s-%d", latencyContainer, action, len(pod.Spec.EphemeralContainers))
+			pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, corev1.EphemeralContainer{
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+					Name:    name,
+					Image:   image,
+					Command: []string{"sh", "-c", command},
+					SecurityContext: &corev1.SecurityContext{
+						Capabilities: &corev1.Capabilities{
+							Add: []corev1.Capability{"NET_ADMIN"},
+						},
+					},
+				},
+			})
+
+			return i.client.SubResource("ephemeralcontainers").Update(ctx, pod)
+		})
+		if client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed running %s in pod %s: %w", command, pod.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// update applies the mutation to the latest version of the workload.
+func (i *Injector) update(ctx context.Context, w *workload, mutate func()) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := w.get(ctx, i.client); err != nil {
+			return err
+		}
+
+		mutate()
+		return i.client.Update(ctx, w.obj)
+	})
+}
@@ -0,0 +1,246 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var testLabels = map[string]string{"app": "loki-querier"}
+
+func testDeployment(replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "loki-querier", Namespace: "loki"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32(replicas),
+			Selector: &metav1.LabelSelector{MatchLabels: testLabels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: testLabels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "loki", Image: "loki"}}},
+			},
+		},
+	}
+}
+
+func testPods(n int) []client.Object {
+	objs := make([]client.Object, 0, n+1)
+	for i := 0; i < n; i++ {
+		objs = append(objs, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("loki-querier-%d", i),
+				Namespace: "loki",
+				Labels:    testLabels,
+				UID:       types.UID(fmt.Sprintf("uid-%d", i)),
+			},
+		})
+	}
+
+	// A pod of another workload.
+	objs = append(objs, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "loki-distributor-0", Namespace: "loki"}})
+	return objs
+}
+
+func testFault(faultType string) *config.Fault {
+	return &config.Fault{Name: "f", Type: faultType, Namespace: "loki", Workload: "loki-querier"}
+}
+
+func TestNewWorkload(t *testing.T) {
+	tests := []struct {
+		kind string
+		want client.Object
+		err  bool
+	}{
+		{kind: "", want: &appsv1.Deployment{}},
+		{kind: config.WorkloadStatefulSet, want: &appsv1.StatefulSet{}},
+		{kind: "DaemonSet", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			fault := testFault(config.FaultScale)
+			fault.Kind = tt.kind
+
+			w, err := newWorkload(fault)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fmt.Sprintf("%T", w.obj) != fmt.Sprintf("%T", tt.want) || w.obj.GetName() != "loki-querier" {
+				t.Errorf("got workload %T %s", w.obj, w.obj.GetName())
+			}
+		})
+	}
+}
+
+func TestScaleAndRevert(t *testing.T) {
+	c := fake.NewClientBuilder().WithObjects(testDeployment(3)).Build()
+	i := NewInjector(c, time.Millisecond, time.Second)
+
+	w, err := newWorkload(testFault(config.FaultScale))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous, err := i.scale(context.Background(), w, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if previous != 3 {
+		t.Errorf("got previous replicas %d, want 3", previous)
+	}
+
+	i.track("f", func(ctx context.Context) error {
+		_, err := i.scale(ctx, w, previous)
+		return err
+	})
+
+	d := &appsv1.Deployment{}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(w.obj), d); err != nil {
+		t.Fatal(err)
+	}
+	if *d.Spec.Replicas != 0 {
+		t.Errorf("got %d replicas, want 0", *d.Spec.Replicas)
+	}
+
+	// Reverting the pending faults twice only runs the revert once.
+	for n := 0; n < 2; n++ {
+		if err := i.Revert(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(w.obj), d); err != nil {
+		t.Fatal(err)
+	}
+	if *d.Spec.Replicas != 3 {
+		t.Errorf("got %d replicas after the revert, want 3", *d.Spec.Replicas)
+	}
+	if len(i.pending) != 0 {
+		t.Errorf("got pending reverts %v", i.pending)
+	}
+}
+
+func TestDeletePods(t *testing.T) {
+	tests := []struct {
+		name  string
+		count int
+		want  int
+	}{
+		{name: "at least one pod", count: 0, want: 1},
+		{name: "some pods", count: 2, want: 2},
+		{name: "more pods than running", count: 5, want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := append(testPods(3), testDeployment(3))
+			c := fake.NewClientBuilder().WithObjects(objs...).Build()
+			i := NewInjector(c, time.Millisecond, time.Second)
+
+			w, err := newWorkload(testFault(config.FaultDeletePods))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			deleted, err := i.deletePods(context.Background(), w, tt.count)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(deleted) != tt.want {
+				t.Errorf("got %d deleted pods, want %d", len(deleted), tt.want)
+			}
+
+			pods := &corev1.PodList{}
+			if err := c.List(context.Background(), pods, client.InNamespace("loki")); err != nil {
+				t.Fatal(err)
+			}
+			if got := len(pods.Items); got != 4-tt.want {
+				t.Errorf("got %d pods left, want %d", got, 4-tt.want)
+			}
+			for _, pod := range pods.Items {
+				if deleted[pod.UID] {
+					t.Errorf("deleted pod %s still exists", pod.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestLatency(t *testing.T) {
+	pods := testPods(2)
+	c := fake.NewClientBuilder().WithObjects(append(pods, testDeployment(2))...).Build()
+	i := NewInjector(c, time.Millisecond, time.Second)
+
+	fault := testFault(config.FaultLatency)
+	fault.Latency = 250 * time.Millisecond
+
+	w, err := newWorkload(fault)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := i.addLatency(context.Background(), w, fault); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := i.removeLatency(context.Background(), w, fault); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, obj := range pods[:2] {
+		pod := &corev1.Pod{}
+		if err := c.Get(context.Background(), client.ObjectKeyFromObject(obj), pod); err != nil {
+			t.Fatal(err)
+		}
+
+		containers := pod.Spec.EphemeralContainers
+		if len(containers) != 2 {
+			t.Fatalf("got ephemeral containers %+v in %s", containers, pod.Name)
+		}
+
+		want := []struct{ name, command string }{
+			{name: latencyContainer + "-add-0", command: "tc qdisc replace dev eth0 root netem delay 250ms"},
+			{name: latencyContainer + "-remove-1", command: "tc qdisc del dev eth0 root"},
+		}
+		for idx, c := range containers {
+			if c.Name != want[idx].name || c.Image != defaultLatencyImage || c.Command[2] != want[idx].command {
+				t.Errorf("got ephemeral container %s running %v, want %s running %q", c.Name, c.Command, want[idx].name, want[idx].command)
+			}
+			if caps := c.SecurityContext.Capabilities.Add; len(caps) != 1 || caps[0] != "NET_ADMIN" {
+				t.Errorf("got capabilities %v", caps)
+			}
+		}
+	}
+
+	other := &corev1.Pod{}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(pods[2]), other); err != nil {
+		t.Fatal(err)
+	}
+	if len(other.Spec.EphemeralContainers) != 0 {
+		t.Errorf("delayed pod %s of another workload", other.Name)
+	}
+
+	// The workload is not rolled out.
+	d := &appsv1.Deployment{}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(w.obj), d); err != nil {
+		t.Fatal(err)
+	}
+	if containers := d.Spec.Template.Spec.Containers; len(containers) != 1 {
+		t.Errorf("got pod template containers %+v", containers)
+	}
+}
@@ -0,0 +1,73 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// workload gives uniform access to the Deployments and StatefulSets faults
+// are injected into.
+type workload struct {
+	obj client.Object
+}
+
+func newWorkload(fault *config.Fault) (*workload, error) {
+	meta := metav1.ObjectMeta{Name: fault.Workload, Namespace: fault.Namespace}
+
+	switch fault.WorkloadKind() {
+	case config.WorkloadDeployment:
+		return &workload{obj: &appsv1.Deployment{ObjectMeta: meta}}, nil
+	case config.WorkloadStatefulSet:
+		return &workload{obj: &appsv1.StatefulSet{ObjectMeta: meta}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported workload kind: %s", fault.Kind)
+	}
+}
+
+func (w *workload) get(ctx context.Context, c client.Client) error {
+	return c.Get(ctx, client.ObjectKeyFromObject(w.obj), w.obj)
+}
+
+func (w *workload) replicas() int32 {
+	var replicas *int32
+	switch obj := w.obj.(type) {
+	case *appsv1.Deployment:
+		replicas = obj.Spec.Replicas
+	case *appsv1.StatefulSet:
+		replicas = obj.Spec.Replicas
+	}
+
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+func (w *workload) setReplicas(replicas int32) {
+	switch obj := w.obj.(type) {
+	case *appsv1.Deployment:
+		obj.Spec.Replicas = pointer.Int32(replicas)
+	case *appsv1.StatefulSet:
+		obj.Spec.Replicas = pointer.Int32(replicas)
+	}
+}
+
+func (w *workload) selector() (labels.Selector, error) {
+	var selector *metav1.LabelSelector
+	switch obj := w.obj.(type) {
+	case *appsv1.Deployment:
+		selector = obj.Spec.Selector
+	case *appsv1.StatefulSet:
+		selector = obj.Spec.Selector
+	}
+
+	return metav1.LabelSelectorAsSelector(selector)
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"fmt"
+	"sort"
 	"time"
 
+	"github.com/observatorium/loki-benchmarks/internal/loadmodel"
+
 	"github.com/onsi/gomega/gmeasure"
 	"github.com/prometheus/common/model"
 )
@@ -20,6 +24,11 @@ type Generator struct {
 	Image          string `yaml:"image"`
 	Tenant         string `yaml:"tenant"`
 	PushURL        string `yaml:"pushURL"`
+	TokenSecret    string `yaml:"tokenSecret,omitempty"`
+
+	// LoadGenImage overrides the image shipping the loki-loadgen binary,
+	// used for the features the load client image does not support.
+	LoadGenImage string `yaml:"loadGenImage,omitempty"`
 }
 
 type Querier struct {
@@ -34,19 +43,130 @@ type Metrics struct {
 	URL                   string `yaml:"url"`
 	Jobs                  *Jobs  `yaml:"jobs"`
 	EnableCadvisorMetrics bool   `yaml:"enableCadvisorMetrics"`
+
+	// DeploymentMode selects how the logical components map to the Loki
+	// targets, by default one target per component.
+	DeploymentMode string `yaml:"deploymentMode,omitempty"`
 }
 
+const (
+	DeploymentModeMicroservices  = "microservices"
+	DeploymentModeSimpleScalable = "simple-scalable"
+	DeploymentModeMonolithic     = "monolithic"
+)
+
+// Jobs names the pod prefix of every logical component. In the simple
+// scalable mode they default to the Read, Write and Backend targets, in the
+// monolithic mode to SingleBinary.
 type Jobs struct {
 	Distributor   string `yaml:"distributor"`
 	Ingester      string `yaml:"ingester"`
 	Querier       string `yaml:"querier"`
 	QueryFrontend string `yaml:"queryFrontend"`
 	IndexGateway  string `yaml:"indexGateway"`
+
+	// Compactor and Ruler are only measured by the scenarios exercising them.
+	Compactor string `yaml:"compactor,omitempty"`
+	Ruler     string `yaml:"ruler,omitempty"`
+
+	// QueryScheduler is empty for deployments without a query-scheduler,
+	// e.g. the LokiStack.
+	QueryScheduler string `yaml:"queryScheduler,omitempty"`
+
+	Read         string `yaml:"read,omitempty"`
+	Write        string `yaml:"write,omitempty"`
+	Backend      string `yaml:"backend,omitempty"`
+	SingleBinary string `yaml:"singleBinary,omitempty"`
+}
+
+// ResolveJobs maps the logical components without a job of their own to the
+// targets of the deployment mode.
+func (m *Metrics) ResolveJobs() error {
+	if m.Jobs == nil {
+		return fmt.Errorf("missing metrics jobs")
+	}
+	j := m.Jobs
+
+	switch m.DeploymentMode {
+	case "", DeploymentModeMicroservices:
+		return nil
+	case DeploymentModeSimpleScalable:
+		if j.Read == "" || j.Write == "" {
+			return fmt.Errorf("simple scalable mode requires the read and write jobs")
+		}
+
+		// Before Loki 2.8 the read target also ran the backend components.
+		backend := j.Backend
+		if backend == "" {
+			backend = j.Read
+		}
+
+		defaultJob(&j.Distributor, j.Write)
+		defaultJob(&j.Ingester, j.Write)
+		defaultJob(&j.QueryFrontend, j.Read)
+		defaultJob(&j.Querier, j.Read)
+		defaultJob(&j.IndexGateway, backend)
+		defaultJob(&j.QueryScheduler, backend)
+		defaultJob(&j.Compactor, backend)
+		defaultJob(&j.Ruler, backend)
+	case DeploymentModeMonolithic:
+		if j.SingleBinary == "" {
+			return fmt.Errorf("monolithic mode requires the singleBinary job")
+		}
+
+		for _, job := range []*string{&j.Distributor, &j.Ingester, &j.QueryFrontend, &j.Querier, &j.IndexGateway, &j.QueryScheduler, &j.Compactor, &j.Ruler} {
+			defaultJob(job, j.SingleBinary)
+		}
+	default:
+		return fmt.Errorf("unsupported deployment mode: %s", m.DeploymentMode)
+	}
+
+	return nil
+}
+
+// TargetJobs returns the jobs of the Loki targets named after prefix, the
+// way the Loki deployments of this repository name them.
+func TargetJobs(mode, prefix string) (*Jobs, error) {
+	switch mode {
+	case "", DeploymentModeMicroservices:
+		return &Jobs{
+			Distributor:    prefix + "-distributor",
+			Ingester:       prefix + "-ingester",
+			Querier:        prefix + "-querier",
+			QueryFrontend:  prefix + "-query-frontend",
+			IndexGateway:   prefix + "-index-gateway",
+			QueryScheduler: prefix + "-query-scheduler",
+			Compactor:      prefix + "-compactor",
+			Ruler:          prefix + "-ruler",
+		}, nil
+	case DeploymentModeSimpleScalable:
+		return &Jobs{
+			Read:    prefix + "-read",
+			Write:   prefix + "-write",
+			Backend: prefix + "-backend",
+		}, nil
+	case DeploymentModeMonolithic:
+		return &Jobs{SingleBinary: prefix}, nil
+	default:
+		return nil, fmt.Errorf("unsupported deployment mode: %s", mode)
+	}
+}
+
+func defaultJob(job *string, target string) {
+	if *job == "" {
+		*job = target
+	}
 }
 
 type Scenarios struct {
 	IngestionPath *IngestionPath `yaml:"ingestionPath,omitempty"`
 	QueryPath     *QueryPath     `yaml:"queryPath,omitempty"`
+	NoisyNeighbor *NoisyNeighbor `yaml:"noisyNeighbor,omitempty"`
+	TailPath      *TailPath      `yaml:"tailPath,omitempty"`
+	Retention     *Retention     `yaml:"retention,omitempty"`
+	Deletion      *Deletion      `yaml:"deletion,omitempty"`
+	Ruler         *Ruler         `yaml:"ruler,omitempty"`
+	Restart       *Restart       `yaml:"restart,omitempty"`
 }
 
 func (s *Scenarios) IsWriteTestEnabled() bool {
@@ -73,11 +193,165 @@ func (s *Scenarios) IsReadTestEnabled() bool {
 	return s.QueryPath.Enabled
 }
 
+func (s *Scenarios) IsNoisyNeighborTestEnabled() bool {
+	if s == nil {
+		return false
+	}
+
+	if s.NoisyNeighbor == nil {
+		return false
+	}
+
+	return s.NoisyNeighbor.Enabled
+}
+
+func (s *Scenarios) IsTailTestEnabled() bool {
+	if s == nil {
+		return false
+	}
+
+	if s.TailPath == nil {
+		return false
+	}
+
+	return s.TailPath.Enabled
+}
+
+func (s *Scenarios) IsRetentionTestEnabled() bool {
+	if s == nil {
+		return false
+	}
+
+	if s.Retention == nil {
+		return false
+	}
+
+	return s.Retention.Enabled
+}
+
+func (s *Scenarios) IsDeletionTestEnabled() bool {
+	if s == nil {
+		return false
+	}
+
+	if s.Deletion == nil {
+		return false
+	}
+
+	return s.Deletion.Enabled
+}
+
+func (s *Scenarios) IsRulerTestEnabled() bool {
+	if s == nil {
+		return false
+	}
+
+	if s.Ruler == nil {
+		return false
+	}
+
+	return s.Ruler.Enabled
+}
+
+func (s *Scenarios) IsRestartTestEnabled() bool {
+	if s == nil {
+		return false
+	}
+
+	if s.Restart == nil {
+		return false
+	}
+
+	return s.Restart.Enabled
+}
+
 type IngestionPath struct {
-	Enabled     bool    `yaml:"enabled"`
-	Description string  `yaml:"description"`
-	Writers     *Writer `yaml:"writers"`
-	samples     *Sample `yaml:"samples,omitempty"`
+	Enabled            bool                `yaml:"enabled"`
+	Description        string              `yaml:"description"`
+	Writers            *Writer             `yaml:"writers"`
+	Samples            *Sample             `yaml:"samples,omitempty"`
+	StructuredMetadata *StructuredMetadata `yaml:"structuredMetadata,omitempty"`
+	Tenants            []*Tenant           `yaml:"tenants,omitempty"`
+	Probe              *Probe              `yaml:"probe,omitempty"`
+	Audit              *Audit              `yaml:"audit,omitempty"`
+	Faults             []*Fault            `yaml:"faults,omitempty"`
+}
+
+func (w *IngestionPath) IsMultiTenant() bool {
+	return w != nil && len(w.Tenants) > 0
+}
+
+// IsAuditEnabled reports whether the lines of the writers are audited after
+// the run. Validate rejects audits of tenant generators.
+func (w *IngestionPath) IsAuditEnabled() bool {
+	return w != nil && w.Audit != nil
+}
+
+func (w *IngestionPath) IsProbeEnabled() bool {
+	return w != nil && w.Probe != nil && w.Probe.Rate > 0
+}
+
+// Audit reconciles the lines stored in Loki with the lines the generators
+// report as accepted. It fails if more than Tolerance of the accepted lines
+// are missing, duplicated or out of order.
+type Audit struct {
+	Tolerance float64       `yaml:"tolerance"`
+	Timeout   time.Duration `yaml:"timeout,omitempty"`
+}
+
+func (a *Audit) WaitTimeout() time.Duration {
+	if a.Timeout > 0 {
+		return a.Timeout
+	}
+	return defaultAuditTimeout
+}
+
+const (
+	FaultDeletePods = "delete-pods"
+	FaultScale      = "scale"
+	FaultLatency    = "latency"
+
+	WorkloadDeployment  = "Deployment"
+	WorkloadStatefulSet = "StatefulSet"
+)
+
+// Fault is injected Offset after the sampling started into the Deployment or
+// StatefulSet Workload in Namespace. A delete-pods fault deletes Count pods,
+// a scale fault scales the workload to Replicas and a latency fault runs tc
+// in an ephemeral container of every pod, delaying its network by Latency.
+// Scale and latency faults are reverted after Duration. The fault lasts until
+// the workload is ready again.
+type Fault struct {
+	Name      string        `yaml:"name"`
+	Type      string        `yaml:"type"`
+	Namespace string        `yaml:"namespace"`
+	Kind      string        `yaml:"kind,omitempty"`
+	Workload  string        `yaml:"workload"`
+	Offset    time.Duration `yaml:"offset"`
+	Duration  time.Duration `yaml:"duration,omitempty"`
+	Count     int           `yaml:"count,omitempty"`
+	Replicas  int32         `yaml:"replicas,omitempty"`
+	Latency   time.Duration `yaml:"latency,omitempty"`
+	Image     string        `yaml:"image,omitempty"`
+}
+
+// WorkloadKind returns the kind of the workload, by default Deployment.
+func (f *Fault) WorkloadKind() string {
+	if f.Kind != "" {
+		return f.Kind
+	}
+	return WorkloadDeployment
+}
+
+// Probe pushes Rate canary lines per second through the generator push URL
+// and polls the querier pull URL every PollInterval until each line appears.
+// Lines not visible within Timeout are counted as missing. A single push or
+// query gives up after RequestTimeout.
+type Probe struct {
+	Rate           float64       `yaml:"rate"`
+	PollInterval   time.Duration `yaml:"pollInterval,omitempty"`
+	Timeout        time.Duration `yaml:"timeout,omitempty"`
+	RequestTimeout time.Duration `yaml:"requestTimeout,omitempty"`
 }
 
 func (w *IngestionPath) SamplingConfiguration() (gmeasure.SamplingConfig, model.Duration) {
@@ -87,8 +361,8 @@ func (w *IngestionPath) SamplingConfiguration() (gmeasure.SamplingConfig, model.
 	}
 
 	if w != nil {
-		if w.samples != nil {
-			samples = w.samples
+		if w.Samples != nil {
+			samples = w.Samples
 		}
 	}
 
@@ -100,11 +374,25 @@ func (w *IngestionPath) SamplingConfiguration() (gmeasure.SamplingConfig, model.
 }
 
 type QueryPath struct {
-	Enabled     bool    `yaml:"enabled"`
-	Description string  `yaml:"description"`
-	Readers     *Reader `yaml:"readers"`
-	samples     *Sample `yaml:"samples,omitempty"`
-	generator   *Writer `yaml:"generator,omitempty"`
+	Enabled            bool                `yaml:"enabled"`
+	Description        string              `yaml:"description"`
+	Readers            *Reader             `yaml:"readers"`
+	Samples            *Sample             `yaml:"samples,omitempty"`
+	Generator          *Writer             `yaml:"generator,omitempty"`
+	StructuredMetadata *StructuredMetadata `yaml:"structuredMetadata,omitempty"`
+	Backfill           *Backfill           `yaml:"backfill,omitempty"`
+	Verification       *Verification       `yaml:"verification,omitempty"`
+	Cache              *Cache              `yaml:"cache,omitempty"`
+	QueryStats         *QueryStats         `yaml:"queryStats,omitempty"`
+	Faults             []*Fault            `yaml:"faults,omitempty"`
+}
+
+// QueryStats breaks the server side work down by named query, from the
+// query statistics lines the query frontend pods matching Selector in
+// Namespace log with the Source tag of every request.
+type QueryStats struct {
+	Namespace string            `yaml:"namespace"`
+	Selector  map[string]string `yaml:"selector"`
 }
 
 func (r *QueryPath) SamplingConfiguration() (gmeasure.SamplingConfig, model.Duration) {
@@ -114,8 +402,8 @@ func (r *QueryPath) SamplingConfiguration() (gmeasure.SamplingConfig, model.Dura
 	}
 
 	if r != nil {
-		if r.samples != nil {
-			samples = r.samples
+		if r.Samples != nil {
+			samples = r.Samples
 		}
 	}
 
@@ -136,14 +424,442 @@ func (r *QueryPath) LogGenerator() *Writer {
 	}
 
 	if r != nil {
-		if r.samples != nil {
-			writer = r.generator
+		if r.Generator != nil {
+			writer = r.Generator
+		}
+	}
+
+	return writer
+}
+
+func (r *QueryPath) IsStructuredMetadataEnabled() bool {
+	if r == nil || r.StructuredMetadata == nil {
+		return false
+	}
+
+	return len(r.StructuredMetadata.Fields) > 0 && len(r.StructuredMetadata.Queries) > 0
+}
+
+func (r *QueryPath) IsBackfillEnabled() bool {
+	if r == nil || r.Backfill == nil {
+		return false
+	}
+
+	return r.Backfill.Replicas > 0 && r.Backfill.VolumeGB > 0
+}
+
+func (r *QueryPath) IsCacheComparisonEnabled() bool {
+	return r != nil && r.Cache != nil
+}
+
+func (r *QueryPath) IsVerificationEnabled() bool {
+	if r == nil || r.Verification == nil {
+		return false
+	}
+
+	return r.Verification.Streams > 0
+}
+
+// BackfillRange returns the range the backfilled log lines are spread over,
+// i.e. the reader query range capped below Loki's reject_old_samples_max_age.
+func (r *QueryPath) BackfillRange() (time.Duration, error) {
+	window, err := r.Readers.Range()
+	if err != nil {
+		return 0, err
+	}
+
+	maxAge := defaultBackfillMaxAge
+	if r.Backfill.MaxAge > 0 {
+		maxAge = r.Backfill.MaxAge
+	}
+
+	if window > maxAge-backfillMaxAgeMargin {
+		window = maxAge - backfillMaxAgeMargin
+	}
+
+	return window, nil
+}
+
+// TailPath opens Tailers.Connections websocket tails in each of
+// Tailers.Replicas pods while the generator writes.
+type TailPath struct {
+	Enabled     bool    `yaml:"enabled"`
+	Description string  `yaml:"description"`
+	Generator   *Writer `yaml:"generator,omitempty"`
+	Tailers     *Tailer `yaml:"tailers"`
+	Samples     *Sample `yaml:"samples,omitempty"`
+}
+
+type Tailer struct {
+	Replicas    int32             `yaml:"replicas"`
+	Connections int               `yaml:"connections"`
+	Queries     map[string]string `yaml:"queries"`
+	DelayFor    time.Duration     `yaml:"delayFor,omitempty"`
+	Limit       int               `yaml:"limit,omitempty"`
+}
+
+func (t *TailPath) SamplingConfiguration() (gmeasure.SamplingConfig, model.Duration) {
+	samples := &Sample{
+		Total:    5,
+		Interval: time.Minute,
+	}
+
+	if t != nil {
+		if t.Samples != nil {
+			samples = t.Samples
+		}
+	}
+
+	return gmeasure.SamplingConfig{
+		N:                   samples.Total,
+		Duration:            samples.Interval * time.Duration(samples.Total+1),
+		MinSamplingInterval: samples.Interval,
+	}, model.Duration(samples.Interval)
+}
+
+func (t *TailPath) LogGenerator() *Writer {
+	writer := &Writer{
+		Replicas: 5,
+		Args: map[string]string{
+			"log-type":        "application",
+			"logs-per-second": "500",
+		},
+	}
+
+	if t != nil {
+		if t.Generator != nil {
+			writer = t.Generator
+		}
+	}
+
+	return writer
+}
+
+// Retention backfills lines older than Period next to the writers, so that
+// the compactor has expired chunks to delete while it is measured. Period
+// must match the retention_period of the Loki tenant, which is read from a
+// compactor pod matching Selector in Namespace before the backfill.
+// ObjectStorageQuery is an optional PromQL query returning the used bytes of
+// the object storage bucket, e.g. from the storage provider's exporter.
+type Retention struct {
+	Enabled            bool              `yaml:"enabled"`
+	Description        string            `yaml:"description"`
+	Period             time.Duration     `yaml:"period"`
+	Namespace          string            `yaml:"namespace"`
+	Selector           map[string]string `yaml:"selector"`
+	Writers            *Writer           `yaml:"writers"`
+	Backfill           *Backfill         `yaml:"backfill"`
+	ObjectStorageQuery string            `yaml:"objectStorageQuery,omitempty"`
+	Samples            *Sample           `yaml:"samples,omitempty"`
+}
+
+func (r *Retention) SamplingConfiguration() (gmeasure.SamplingConfig, model.Duration) {
+	samples := &Sample{
+		Total:    10,
+		Interval: time.Minute * 10,
+	}
+
+	if r != nil {
+		if r.Samples != nil {
+			samples = r.Samples
+		}
+	}
+
+	return gmeasure.SamplingConfig{
+		N:                   samples.Total,
+		Duration:            samples.Interval * time.Duration(samples.Total+1),
+		MinSamplingInterval: samples.Interval,
+	}, model.Duration(samples.Interval)
+}
+
+// BackfillRange returns the range the backfilled log lines are spread over,
+// i.e. up to Loki's reject_old_samples_max_age. Only the lines older than
+// Period are expired by the compactor.
+func (r *Retention) BackfillRange() (time.Duration, error) {
+	maxAge := defaultBackfillMaxAge
+	if r.Backfill.MaxAge > 0 {
+		maxAge = r.Backfill.MaxAge
+	}
+
+	window := maxAge - backfillMaxAgeMargin
+	if r.Period <= 0 || r.Period >= window {
+		return 0, fmt.Errorf("retention period %s must be within the backfill range %s", r.Period, window)
+	}
+
+	return window, nil
+}
+
+// Deletion submits Requests through the delete API against backfilled lines
+// while Readers query them, and waits up to Timeout for the compactor to
+// process the requests.
+type Deletion struct {
+	Enabled      bool             `yaml:"enabled"`
+	Description  string           `yaml:"description"`
+	Backfill     *Backfill        `yaml:"backfill"`
+	Readers      *Reader          `yaml:"readers,omitempty"`
+	Requests     []*DeleteRequest `yaml:"requests"`
+	PollInterval time.Duration    `yaml:"pollInterval,omitempty"`
+	Timeout      time.Duration    `yaml:"timeout,omitempty"`
+	Samples      *Sample          `yaml:"samples,omitempty"`
+}
+
+// DeleteRequest deletes the lines matching Query over Range, ending Offset
+// before the request is submitted. Query is a stream selector, optionally
+// followed by line filters.
+type DeleteRequest struct {
+	Name   string        `yaml:"name"`
+	Query  string        `yaml:"query"`
+	Offset time.Duration `yaml:"offset,omitempty"`
+	Range  time.Duration `yaml:"range"`
+}
+
+func (d *Deletion) SamplingConfiguration() (gmeasure.SamplingConfig, model.Duration) {
+	samples := &Sample{
+		Total:    5,
+		Interval: time.Minute * 3,
+	}
+
+	if d != nil {
+		if d.Samples != nil {
+			samples = d.Samples
+		}
+	}
+
+	return gmeasure.SamplingConfig{
+		N:                   samples.Total,
+		Duration:            samples.Interval * time.Duration(samples.Total+1),
+		MinSamplingInterval: samples.Interval,
+	}, model.Duration(samples.Interval)
+}
+
+// BackfillRange returns the range the backfilled log lines are spread over,
+// i.e. up to Loki's reject_old_samples_max_age. Every request must delete
+// lines within it.
+func (d *Deletion) BackfillRange() (time.Duration, error) {
+	maxAge := defaultBackfillMaxAge
+	if d.Backfill.MaxAge > 0 {
+		maxAge = d.Backfill.MaxAge
+	}
+
+	window := maxAge - backfillMaxAgeMargin
+	for _, req := range d.Requests {
+		if req.Offset+req.Range > window {
+			return 0, fmt.Errorf("delete request %s exceeds the backfill range %s", req.Name, window)
+		}
+	}
+
+	return window, nil
+}
+
+// WaitTimeout returns the time to wait for the requests to be processed,
+// by default one hour.
+func (d *Deletion) WaitTimeout() time.Duration {
+	if d.Timeout > 0 {
+		return d.Timeout
+	}
+	return defaultDeletionTimeout
+}
+
+// Ruler loads Groups rule groups of RulesPerGroup rules each, generated from
+// Templates, while the generator writes. Variables maps template variables
+// like $host to the label whose live values fill them in. The rules are
+// loaded through the ruler API at URL, by default the querier pull URL, or
+// written into ConfigMap in the Loki namespace if set.
+type Ruler struct {
+	Enabled       bool              `yaml:"enabled"`
+	Description   string            `yaml:"description"`
+	Generator     *Writer           `yaml:"generator,omitempty"`
+	URL           string            `yaml:"url,omitempty"`
+	Namespace     string            `yaml:"namespace,omitempty"`
+	Groups        int               `yaml:"groups"`
+	RulesPerGroup int               `yaml:"rulesPerGroup"`
+	Interval      time.Duration     `yaml:"interval,omitempty"`
+	Templates     []*RuleTemplate   `yaml:"templates"`
+	Variables     map[string]string `yaml:"variables,omitempty"`
+	ConfigMap     *RulesConfigMap   `yaml:"configMap,omitempty"`
+	Samples       *Sample           `yaml:"samples,omitempty"`
+}
+
+// RuleTemplate is a recording rule if Record is set, otherwise an alerting
+// rule. The generated rule names are suffixed with the rule index.
+type RuleTemplate struct {
+	Record string            `yaml:"record,omitempty"`
+	Alert  string            `yaml:"alert,omitempty"`
+	Expr   string            `yaml:"expr"`
+	For    time.Duration     `yaml:"for,omitempty"`
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+// RulesConfigMap names the ConfigMap the ruler reads the rule files of the
+// tenant from.
+type RulesConfigMap struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+}
+
+func (r *Ruler) SamplingConfiguration() (gmeasure.SamplingConfig, model.Duration) {
+	samples := &Sample{
+		Total:    10,
+		Interval: time.Minute * 3,
+	}
+
+	if r != nil {
+		if r.Samples != nil {
+			samples = r.Samples
+		}
+	}
+
+	return gmeasure.SamplingConfig{
+		N:                   samples.Total,
+		Duration:            samples.Interval * time.Duration(samples.Total+1),
+		MinSamplingInterval: samples.Interval,
+	}, model.Duration(samples.Interval)
+}
+
+func (r *Ruler) LogGenerator() *Writer {
+	writer := &Writer{
+		Replicas: 5,
+		Args: map[string]string{
+			"log-type":        "application",
+			"label-type":      "client-host",
+			"logs-per-second": "500",
+		},
+	}
+
+	if r != nil {
+		if r.Generator != nil {
+			writer = r.Generator
 		}
 	}
 
 	return writer
 }
 
+// RuleNamespace returns the ruler namespace the rules are loaded into.
+func (r *Ruler) RuleNamespace() string {
+	if r.Namespace != "" {
+		return r.Namespace
+	}
+	return defaultRuleNamespace
+}
+
+const (
+	RestartRolling   = "rolling"
+	RestartAllAtOnce = "all-at-once"
+)
+
+// Restart restarts the ingester StatefulSet in Namespace while the writers
+// ingest at a steady rate. A rolling restart rolls out the pods one by one,
+// an all-at-once restart deletes every pod at the same time. The samples are
+// taken before and after the restart.
+type Restart struct {
+	Enabled     bool          `yaml:"enabled"`
+	Description string        `yaml:"description"`
+	Writers     *Writer       `yaml:"writers"`
+	Namespace   string        `yaml:"namespace"`
+	StatefulSet string        `yaml:"statefulSet"`
+	Strategy    string        `yaml:"strategy,omitempty"`
+	Timeout     time.Duration `yaml:"timeout,omitempty"`
+	Samples     *Sample       `yaml:"samples,omitempty"`
+}
+
+func (r *Restart) SamplingConfiguration() (gmeasure.SamplingConfig, model.Duration) {
+	samples := &Sample{
+		Total:    5,
+		Interval: time.Minute * 3,
+	}
+
+	if r != nil {
+		if r.Samples != nil {
+			samples = r.Samples
+		}
+	}
+
+	return gmeasure.SamplingConfig{
+		N:                   samples.Total,
+		Duration:            samples.Interval * time.Duration(samples.Total+1),
+		MinSamplingInterval: samples.Interval,
+	}, model.Duration(samples.Interval)
+}
+
+// RestartStrategy returns how the ingesters are restarted, by default
+// rolling.
+func (r *Restart) RestartStrategy() string {
+	if r.Strategy != "" {
+		return r.Strategy
+	}
+	return RestartRolling
+}
+
+// WaitTimeout returns the time to wait for the ingesters to be ready again,
+// by default 30 minutes.
+func (r *Restart) WaitTimeout() time.Duration {
+	if r.Timeout > 0 {
+		return r.Timeout
+	}
+	return defaultRestartTimeout
+}
+
+// NoisyNeighbor measures the victim tenant at a steady baseline and again
+// while the aggressor tenant floods writes or runs expensive queries.
+type NoisyNeighbor struct {
+	Enabled     bool          `yaml:"enabled"`
+	Description string        `yaml:"description"`
+	Victim      *NeighborLoad `yaml:"victim"`
+	Aggressor   *NeighborLoad `yaml:"aggressor"`
+	Samples     *Sample       `yaml:"samples,omitempty"`
+}
+
+func (n *NoisyNeighbor) SamplingConfiguration() (gmeasure.SamplingConfig, model.Duration) {
+	samples := &Sample{
+		Total:    5,
+		Interval: time.Minute,
+	}
+
+	if n != nil {
+		if n.Samples != nil {
+			samples = n.Samples
+		}
+	}
+
+	return gmeasure.SamplingConfig{
+		N:                   samples.Total,
+		Duration:            samples.Interval * time.Duration(samples.Total+1),
+		MinSamplingInterval: samples.Interval,
+	}, model.Duration(samples.Interval)
+}
+
+// NeighborLoad describes the writes and queries of a single tenant of the
+// noisy neighbor scenario. PushURL and PullURL default to the generator and
+// querier configuration.
+type NeighborLoad struct {
+	Tenant  string  `yaml:"tenant"`
+	PushURL string  `yaml:"pushURL,omitempty"`
+	PullURL string  `yaml:"pullURL,omitempty"`
+	Writers *Writer `yaml:"writers,omitempty"`
+	Readers *Reader `yaml:"readers,omitempty"`
+}
+
+func (l *NeighborLoad) GeneratorTenant() *Tenant {
+	return &Tenant{
+		Name:     l.Tenant,
+		Replicas: l.Writers.Replicas,
+		Args:     l.Writers.Args,
+		PushURL:  l.PushURL,
+	}
+}
+
+func (l *NeighborLoad) QuerierConfig(cfg *Querier) *Querier {
+	querierCfg := *cfg
+	querierCfg.Tenant = l.Tenant
+
+	if l.PullURL != "" {
+		querierCfg.PullURL = l.PullURL
+	}
+
+	return &querierCfg
+}
+
 type Sample struct {
 	Total    int           `yaml:"total"`
 	Interval time.Duration `yaml:"interval"`
@@ -152,10 +868,251 @@ type Sample struct {
 type Writer struct {
 	Replicas int32             `yaml:"replicas"`
 	Args     map[string]string `yaml:"args"`
+	Replay   *Replay           `yaml:"replay,omitempty"`
+
+	// Audit tags the lines with sequence numbers for the audit, it is set
+	// by the benchmark when the scenario is audited.
+	Audit bool `yaml:"-"`
+}
+
+// Tenant describes the load generated for a single tenant of a multi-tenant
+// scenario. PushURL and TokenSecret default to the generator configuration.
+// TokenSecret names a secret holding the bearer token under the key "token".
+type Tenant struct {
+	Name        string            `yaml:"name"`
+	Replicas    int32             `yaml:"replicas"`
+	Args        map[string]string `yaml:"args"`
+	Replay      *Replay           `yaml:"replay,omitempty"`
+	PushURL     string            `yaml:"pushURL,omitempty"`
+	TokenSecret string            `yaml:"tokenSecret,omitempty"`
+}
+
+const (
+	ReplayFormatJSONL = "jsonl"
+	ReplayFormatLoki  = "loki"
+)
+
+// Replay describes a recorded log corpus replayed by the generator instead of
+// synthetic log lines. File is read from the ConfigMap or the
+// PersistentVolumeClaim mounted into the generator pods. The corpus is
+// replayed at LogsPerSecond if set, otherwise at its original pace divided by
+// SpeedUp.
+type Replay struct {
+	ConfigMap             string  `yaml:"configMap,omitempty"`
+	PersistentVolumeClaim string  `yaml:"persistentVolumeClaim,omitempty"`
+	File                  string  `yaml:"file"`
+	Format                string  `yaml:"format,omitempty"`
+	SpeedUp               float64 `yaml:"speedUp,omitempty"`
+	LogsPerSecond         int     `yaml:"logsPerSecond,omitempty"`
 }
 
+const (
+	defaultBackfillMaxAge  = 168 * time.Hour
+	defaultBackfillTimeout = time.Hour
+	defaultAuditTimeout    = 30 * time.Minute
+	defaultDeletionTimeout = time.Hour
+	defaultRestartTimeout  = 30 * time.Minute
+	defaultRuleNamespace   = "loki-benchmarks"
+	defaultCacheJitter     = 10 * time.Minute
+	defaultPrimingTimeout  = 30 * time.Minute
+
+	// defaultReaderQPS paces readers without qps and concurrency like the
+	// former logcli loop, which slept 10s between two queries.
+	defaultReaderQPS = 0.1
+
+	// backfillMaxAgeMargin keeps the oldest backfilled log lines clear of
+	// the reject_old_samples_max_age limit while they are being pushed.
+	backfillMaxAgeMargin = time.Hour
+)
+
+// Backfill describes the data pushed before the read benchmarks start. MaxAge
+// must match the reject_old_samples_max_age limit of the Loki tenant.
+type Backfill struct {
+	Replicas int32             `yaml:"replicas"`
+	VolumeGB float64           `yaml:"volumeGB"`
+	Args     map[string]string `yaml:"args"`
+	MaxAge   time.Duration     `yaml:"maxAge,omitempty"`
+	Timeout  time.Duration     `yaml:"timeout,omitempty"`
+}
+
+func (b *Backfill) TotalBytes() int64 {
+	return int64(b.VolumeGB * 1000 * 1000 * 1000)
+}
+
+func (b *Backfill) WaitTimeout() time.Duration {
+	if b.Timeout > 0 {
+		return b.Timeout
+	}
+	return defaultBackfillTimeout
+}
+
+const (
+	CacheModeCold = "cold"
+	CacheModeWarm = "warm"
+
+	CacheBustRestart = "restart"
+	CacheBustJitter  = "jitter"
+)
+
+// Cache runs the readers once per mode. In cold mode the caches are busted
+// before every sample, either by restarting the pods matching Selector in
+// Namespace, e.g. memcached, while the queriers are scaled to zero, or by
+// jittering the query ranges by up to Jitter. Jitter only misses the results
+// cache, the chunk and index caches stay warm. In warm mode every query runs
+// once before the queriers start and may take up to PrimingTimeout to return.
+type Cache struct {
+	Modes          []string          `yaml:"modes,omitempty"`
+	Bust           string            `yaml:"bust"`
+	Namespace      string            `yaml:"namespace,omitempty"`
+	Selector       map[string]string `yaml:"selector,omitempty"`
+	Jitter         time.Duration     `yaml:"jitter,omitempty"`
+	PrimingTimeout time.Duration     `yaml:"primingTimeout,omitempty"`
+}
+
+// CacheModes returns the configured modes, by default cold before warm.
+func (c *Cache) CacheModes() []string {
+	if len(c.Modes) > 0 {
+		return c.Modes
+	}
+	return []string{CacheModeCold, CacheModeWarm}
+}
+
+// JitterRange returns the jitter of the cold mode, by default ten minutes.
+func (c *Cache) JitterRange() time.Duration {
+	if c.Jitter > 0 {
+		return c.Jitter
+	}
+	return defaultCacheJitter
+}
+
+// PrimingWaitTimeout returns the time the priming queries of the warm mode
+// may take, by default 30 minutes.
+func (c *Cache) PrimingWaitTimeout() time.Duration {
+	if c.PrimingTimeout > 0 {
+		return c.PrimingTimeout
+	}
+	return defaultPrimingTimeout
+}
+
+// Verification pushes Streams streams of a deterministic dataset next to the
+// generator and compares the results of queries over windows of it, ending
+// Lag ago, with the expected values every Interval.
+type Verification struct {
+	Seed           int64         `yaml:"seed"`
+	Streams        int           `yaml:"streams"`
+	LinesPerSecond int           `yaml:"linesPerSecond"`
+	Window         time.Duration `yaml:"window,omitempty"`
+	Lag            time.Duration `yaml:"lag,omitempty"`
+	Interval       time.Duration `yaml:"interval,omitempty"`
+}
+
+// Reader describes the query load. Every query runs in Replicas pods of
+// its own. In a closed loop each pod keeps Concurrency queries in flight, or
+// sends QPS queries per second with at most Concurrency of them in flight.
+// Without both, each pod sends one query every 10s.
+// In an open loop each pod sends QPS queries per second with Arrival
+// spacing regardless of how fast Loki answers, and Concurrency only caps the
+// queries in flight.
+//
+// Mix is executed by Replicas pods of its own, each picking the next query by
+// its weight. Mix queries without a range draw it from Ranges, and Variables
+// maps template variables like $namespace to the label whose live values
+// fill them in. Jitter moves the end of every query up to that far into the
+// past to avoid cached results.
 type Reader struct {
-	Replicas   int32             `yaml:"replicas"`
-	Queries    map[string]string `yaml:"queries"`
-	QueryRange string            `yaml:"queryRange"`
+	Replicas    int32             `yaml:"replicas"`
+	Queries     map[string]string `yaml:"queries"`
+	QueryRange  string            `yaml:"queryRange"`
+	QueryType   string            `yaml:"queryType,omitempty"`
+	QPS         float64           `yaml:"qps,omitempty"`
+	Concurrency int               `yaml:"concurrency,omitempty"`
+	Loop        string            `yaml:"loop,omitempty"`
+	Arrival     string            `yaml:"arrival,omitempty"`
+	Mix         []*MixQuery       `yaml:"mix,omitempty"`
+	Ranges      []*WeightedRange  `yaml:"ranges,omitempty"`
+	Variables   map[string]string `yaml:"variables,omitempty"`
+	Jitter      time.Duration     `yaml:"jitter,omitempty"`
+}
+
+type MixQuery struct {
+	Name   string        `yaml:"name"`
+	Query  string        `yaml:"query"`
+	Type   string        `yaml:"type,omitempty"`
+	Weight int           `yaml:"weight,omitempty"`
+	Range  time.Duration `yaml:"range,omitempty"`
+	Step   time.Duration `yaml:"step,omitempty"`
+	Limit  int           `yaml:"limit,omitempty"`
+	Label  string        `yaml:"label,omitempty"`
+}
+
+type WeightedRange struct {
+	Range  time.Duration `yaml:"range"`
+	Weight int           `yaml:"weight"`
+}
+
+// QueryNames returns the sorted names of the queries and mix queries.
+func (r *Reader) QueryNames() []string {
+	names := make([]string, 0, len(r.Queries)+len(r.Mix))
+	for name := range r.Queries {
+		names = append(names, name)
+	}
+	for _, q := range r.Mix {
+		names = append(names, q.Name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// UsesReadAPI reports whether the reader queries other endpoints than
+// query_range, e.g. labels or series.
+func (r *Reader) UsesReadAPI() bool {
+	if r.QueryType != "" && r.QueryType != "range" {
+		return true
+	}
+
+	for _, q := range r.Mix {
+		if q.Type != "" && q.Type != "range" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Rate returns the queries per second sent by every pod. Readers setting
+// neither QPS nor Concurrency send one query every 10s.
+func (r *Reader) Rate() float64 {
+	if r.QPS == 0 && r.Concurrency == 0 {
+		return defaultReaderQPS
+	}
+	return r.QPS
+}
+
+func (r *Reader) IsOpenLoop() bool {
+	return r.Loop == loadmodel.LoopOpen
+}
+
+func (r *Reader) Range() (time.Duration, error) {
+	window, err := model.ParseDuration(r.QueryRange)
+	if err != nil {
+		return 0, fmt.Errorf("failed parsing query range %q: %w", r.QueryRange, err)
+	}
+
+	return time.Duration(window), nil
+}
+
+// StructuredMetadata describes the structured metadata attached to every
+// generated log line. Queries maps reader query ids to their structured
+// metadata filter counterparts and is only used by the query path.
+type StructuredMetadata struct {
+	Fields  []StructuredMetadataField `yaml:"fields"`
+	Inline  bool                      `yaml:"inline,omitempty"`
+	Queries map[string]string         `yaml:"queries,omitempty"`
+}
+
+type StructuredMetadataField struct {
+	Name        string `yaml:"name"`
+	Cardinality int    `yaml:"cardinality"`
+	Size        int    `yaml:"size"`
 }
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTargetJobs(t *testing.T) {
+	microservices := &Jobs{
+		Distributor:    "loki-distributor",
+		Ingester:       "loki-ingester",
+		Querier:        "loki-querier",
+		QueryFrontend:  "loki-query-frontend",
+		IndexGateway:   "loki-index-gateway",
+		QueryScheduler: "loki-query-scheduler",
+		Compactor:      "loki-compactor",
+		Ruler:          "loki-ruler",
+	}
+
+	tests := []struct {
+		name string
+		mode string
+		want *Jobs
+		err  string
+	}{
+		{name: "default mode", want: microservices},
+		{name: "microservices mode", mode: DeploymentModeMicroservices, want: microservices},
+		{name: "simple scalable mode", mode: DeploymentModeSimpleScalable, want: &Jobs{Read: "loki-read", Write: "loki-write", Backend: "loki-backend"}},
+		{name: "monolithic mode", mode: DeploymentModeMonolithic, want: &Jobs{SingleBinary: "loki"}},
+		{name: "unsupported mode", mode: "distributed", err: "unsupported deployment mode: distributed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TargetJobs(tt.mode, "loki")
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveJobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		metrics *Metrics
+		want    *Jobs
+		err     string
+	}{
+		{
+			name:    "microservices mode",
+			metrics: &Metrics{Jobs: &Jobs{Querier: "loki-querier"}},
+			want:    &Jobs{Querier: "loki-querier"},
+		},
+		{
+			name:    "simple scalable mode",
+			metrics: &Metrics{DeploymentMode: DeploymentModeSimpleScalable, Jobs: &Jobs{Read: "loki-read", Write: "loki-write", Backend: "loki-backend"}},
+			want: &Jobs{
+				Distributor:    "loki-write",
+				Ingester:       "loki-write",
+				Querier:        "loki-read",
+				QueryFrontend:  "loki-read",
+				IndexGateway:   "loki-backend",
+				QueryScheduler: "loki-backend",
+				Compactor:      "loki-backend",
+				Ruler:          "loki-backend",
+				Read:           "loki-read",
+				Write:          "loki-write",
+				Backend:        "loki-backend",
+			},
+		},
+		{
+			name:    "simple scalable mode without backend",
+			metrics: &Metrics{DeploymentMode: DeploymentModeSimpleScalable, Jobs: &Jobs{Read: "loki-read", Write: "loki-write", Ruler: "loki-ruler"}},
+			want: &Jobs{
+				Distributor:    "loki-write",
+				Ingester:       "loki-write",
+				Querier:        "loki-read",
+				QueryFrontend:  "loki-read",
+				IndexGateway:   "loki-read",
+				QueryScheduler: "loki-read",
+				Compactor:      "loki-read",
+				Ruler:          "loki-ruler",
+				Read:           "loki-read",
+				Write:          "loki-write",
+			},
+		},
+		{
+			name:    "monolithic mode",
+			metrics: &Metrics{DeploymentMode: DeploymentModeMonolithic, Jobs: &Jobs{SingleBinary: "loki"}},
+			want: &Jobs{
+				Distributor:    "loki",
+				Ingester:       "loki",
+				Querier:        "loki",
+				QueryFrontend:  "loki",
+				IndexGateway:   "loki",
+				QueryScheduler: "loki",
+				Compactor:      "loki",
+				Ruler:          "loki",
+				SingleBinary:   "loki",
+			},
+		},
+		{name: "missing jobs", metrics: &Metrics{}, err: "missing metrics jobs"},
+		{
+			name:    "simple scalable mode without write",
+			metrics: &Metrics{DeploymentMode: DeploymentModeSimpleScalable, Jobs: &Jobs{Read: "loki-read"}},
+			err:     "simple scalable mode requires the read and write jobs",
+		},
+		{
+			name:    "monolithic mode without single binary",
+			metrics: &Metrics{DeploymentMode: DeploymentModeMonolithic, Jobs: &Jobs{}},
+			err:     "monolithic mode requires the singleBinary job",
+		},
+		{
+			name:    "unsupported mode",
+			metrics: &Metrics{DeploymentMode: "distributed", Jobs: &Jobs{}},
+			err:     "unsupported deployment mode: distributed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.metrics.ResolveJobs()
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.metrics.Jobs, tt.want) {
+				t.Errorf("got %+v, want %+v", tt.metrics.Jobs, tt.want)
+			}
+		})
+	}
+}
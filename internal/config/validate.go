@@ -0,0 +1,311 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/observatorium/loki-benchmarks/internal/loadmodel"
+)
+
+// Validate rejects configurations the enabled scenarios cannot run with,
+// before any load is deployed.
+func (b *Benchmark) Validate() error {
+	if b.Scenarios == nil {
+		return nil
+	}
+
+	if b.Scenarios.IsWriteTestEnabled() {
+		if err := b.Scenarios.IngestionPath.validate(); err != nil {
+			return fmt.Errorf("ingestionPath: %w", err)
+		}
+	}
+
+	if b.Scenarios.IsReadTestEnabled() {
+		if err := b.Scenarios.QueryPath.validate(); err != nil {
+			return fmt.Errorf("queryPath: %w", err)
+		}
+	}
+
+	if b.Scenarios.IsNoisyNeighborTestEnabled() {
+		if err := b.Scenarios.NoisyNeighbor.validate(); err != nil {
+			return fmt.Errorf("noisyNeighbor: %w", err)
+		}
+	}
+
+	if b.Scenarios.IsTailTestEnabled() {
+		if err := b.Scenarios.TailPath.Generator.validate(); err != nil {
+			return fmt.Errorf("tailPath: generator: %w", err)
+		}
+	}
+
+	if b.Scenarios.IsRetentionTestEnabled() {
+		if err := b.Scenarios.Retention.validate(); err != nil {
+			return fmt.Errorf("retention: %w", err)
+		}
+	}
+
+	if b.Scenarios.IsRulerTestEnabled() {
+		if err := b.Scenarios.Ruler.Generator.validate(); err != nil {
+			return fmt.Errorf("ruler: generator: %w", err)
+		}
+	}
+
+	if b.Scenarios.IsRestartTestEnabled() {
+		if err := b.Scenarios.Restart.Writers.validate(); err != nil {
+			return fmt.Errorf("restart: writers: %w", err)
+		}
+	}
+
+	if b.Scenarios.IsDeletionTestEnabled() && b.Scenarios.Deletion.Readers != nil {
+		if err := b.Scenarios.Deletion.Readers.validate(); err != nil {
+			return fmt.Errorf("deletion: readers: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (w *IngestionPath) validate() error {
+	// The auditor reads the reports of the writers, the tenant generators
+	// push to streams of their own.
+	if w.IsAuditEnabled() && w.IsMultiTenant() {
+		return fmt.Errorf("audit does not support tenants")
+	}
+
+	if err := w.Writers.validate(); err != nil {
+		return fmt.Errorf("writers: %w", err)
+	}
+	for _, t := range w.Tenants {
+		if t.Replay == nil {
+			continue
+		}
+		if err := t.Replay.validate(); err != nil {
+			return fmt.Errorf("tenant %s: replay: %w", t.Name, err)
+		}
+	}
+
+	return validateFaults(w.Faults)
+}
+
+func (r *QueryPath) validate() error {
+	if r.Readers == nil {
+		return fmt.Errorf("missing readers")
+	}
+	if err := r.Readers.validate(); err != nil {
+		return fmt.Errorf("readers: %w", err)
+	}
+	if err := r.Generator.validate(); err != nil {
+		return fmt.Errorf("generator: %w", err)
+	}
+
+	if r.StructuredMetadata != nil {
+		if err := r.StructuredMetadata.validateQueries(r.Readers); err != nil {
+			return fmt.Errorf("structuredMetadata: %w", err)
+		}
+	}
+
+	if r.QueryStats != nil && (r.QueryStats.Namespace == "" || len(r.QueryStats.Selector) == 0) {
+		return fmt.Errorf("queryStats: namespace and selector are required")
+	}
+
+	return validateFaults(r.Faults)
+}
+
+func (r *Retention) validate() error {
+	if r.Period <= 0 {
+		return fmt.Errorf("period must be positive")
+	}
+	// The period is compared with the retention of the compactor pods.
+	if r.Namespace == "" || len(r.Selector) == 0 {
+		return fmt.Errorf("namespace and selector are required")
+	}
+
+	if err := r.Writers.validate(); err != nil {
+		return fmt.Errorf("writers: %w", err)
+	}
+
+	return nil
+}
+
+func (n *NoisyNeighbor) validate() error {
+	if n.Victim != nil {
+		if err := n.Victim.Writers.validate(); err != nil {
+			return fmt.Errorf("victim: writers: %w", err)
+		}
+	}
+	if n.Aggressor != nil {
+		if err := n.Aggressor.Writers.validate(); err != nil {
+			return fmt.Errorf("aggressor: writers: %w", err)
+		}
+	}
+
+	if n.Victim != nil && n.Victim.Readers != nil {
+		if err := n.Victim.Readers.validate(); err != nil {
+			return fmt.Errorf("victim: readers: %w", err)
+		}
+	}
+	if n.Aggressor != nil && n.Aggressor.Readers != nil {
+		if err := n.Aggressor.Readers.validate(); err != nil {
+			return fmt.Errorf("aggressor: readers: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateFaults rejects faults the injector cannot inject or revert. The
+// name identifies the fault in the report and while it is pending.
+func validateFaults(faults []*Fault) error {
+	names := map[string]bool{}
+	for idx, f := range faults {
+		if f.Name == "" {
+			return fmt.Errorf("fault %d: name is required", idx)
+		}
+		if names[f.Name] {
+			return fmt.Errorf("fault %s: duplicate name", f.Name)
+		}
+		names[f.Name] = true
+
+		if err := f.validate(); err != nil {
+			return fmt.Errorf("fault %s: %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (f *Fault) validate() error {
+	if f.Namespace == "" || f.Workload == "" {
+		return fmt.Errorf("namespace and workload are required")
+	}
+	if kind := f.WorkloadKind(); kind != WorkloadDeployment && kind != WorkloadStatefulSet {
+		return fmt.Errorf("unsupported kind: %s", kind)
+	}
+	if f.Offset < 0 {
+		return fmt.Errorf("offset must not be negative")
+	}
+
+	switch f.Type {
+	case FaultDeletePods:
+		if f.Count < 0 {
+			return fmt.Errorf("count must not be negative")
+		}
+	case FaultScale:
+		if f.Duration <= 0 {
+			return fmt.Errorf("scale faults require a positive duration")
+		}
+		if f.Replicas < 0 {
+			return fmt.Errorf("replicas must not be negative")
+		}
+	case FaultLatency:
+		if f.Duration <= 0 || f.Latency <= 0 {
+			return fmt.Errorf("latency faults require a positive duration and latency")
+		}
+	default:
+		return fmt.Errorf("unsupported type: %s", f.Type)
+	}
+
+	return nil
+}
+
+// validate rejects writers replaying a corpus the generator pods cannot
+// read. Writers without a replay generate synthetic lines.
+func (w *Writer) validate() error {
+	if w == nil || w.Replay == nil {
+		return nil
+	}
+
+	if err := w.Replay.validate(); err != nil {
+		return fmt.Errorf("replay: %w", err)
+	}
+
+	return nil
+}
+
+// validate requires the corpus to be mounted from exactly one ConfigMap or
+// PersistentVolumeClaim, otherwise the replay file does not exist.
+func (r *Replay) validate() error {
+	switch {
+	case r.ConfigMap == "" && r.PersistentVolumeClaim == "":
+		return fmt.Errorf("configMap or persistentVolumeClaim is required")
+	case r.ConfigMap != "" && r.PersistentVolumeClaim != "":
+		return fmt.Errorf("configMap and persistentVolumeClaim are mutually exclusive")
+	}
+
+	if r.File == "" {
+		return fmt.Errorf("file is required")
+	}
+
+	switch r.Format {
+	case "", ReplayFormatJSONL, ReplayFormatLoki:
+	default:
+		return fmt.Errorf("unsupported format: %s", r.Format)
+	}
+
+	if r.SpeedUp < 0 || r.LogsPerSecond < 0 {
+		return fmt.Errorf("speedUp and logsPerSecond must not be negative")
+	}
+
+	return nil
+}
+
+// validate rejects load shapes loki-querygen cannot run. An open loop has no
+// default rate, without QPS its pods would not start.
+func (r *Reader) validate() error {
+	if r.QPS < 0 || r.Concurrency < 0 {
+		return fmt.Errorf("qps and concurrency must not be negative")
+	}
+
+	switch r.Loop {
+	case "", loadmodel.LoopClosed:
+	case loadmodel.LoopOpen:
+		if r.QPS <= 0 {
+			return fmt.Errorf("open loop requires a positive qps")
+		}
+	default:
+		return fmt.Errorf("unsupported loop: %s", r.Loop)
+	}
+
+	switch r.Arrival {
+	case "", loadmodel.ArrivalConstant, loadmodel.ArrivalPoisson:
+	default:
+		return fmt.Errorf("unsupported arrival: %s", r.Arrival)
+	}
+
+	return nil
+}
+
+// validateQueries requires every structured metadata query to replace a
+// reader query of the same id and to filter on one of the fields.
+func (s *StructuredMetadata) validateQueries(reader *Reader) error {
+	ids := make([]string, 0, len(s.Queries))
+	for id := range s.Queries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if _, ok := reader.Queries[id]; !ok {
+			return fmt.Errorf("query %s has no reader query of the same id", id)
+		}
+		if !s.filtersOnFields(s.Queries[id]) {
+			return fmt.Errorf("query %s does not filter on a structured metadata field", id)
+		}
+	}
+
+	return nil
+}
+
+// filtersOnFields reports whether the query has a label filter on one of
+// the fields, e.g. | trace_id="...".
+func (s *StructuredMetadata) filtersOnFields(query string) bool {
+	for _, f := range s.Fields {
+		filter := regexp.MustCompile(`\|\s*` + regexp.QuoteMeta(f.Name) + `\s*(=|!=|=~|!~|>|<)`)
+		if filter.MatchString(query) {
+			return true
+		}
+	}
+
+	return false
+}
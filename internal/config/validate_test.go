@@ -0,0 +1,496 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		scenarios string
+		err       string
+	}{
+		{
+			name: "without scenarios",
+		},
+		{
+			name: "disabled scenario",
+			scenarios: `
+queryPath:
+  enabled: false
+`,
+		},
+		{
+			name: "ingestion path with audit",
+			scenarios: `
+ingestionPath:
+  enabled: true
+  audit:
+    tolerance: 0.01
+`,
+		},
+		{
+			name: "ingestion path with audit and tenants",
+			scenarios: `
+ingestionPath:
+  enabled: true
+  audit:
+    tolerance: 0.01
+  tenants:
+    - name: a
+`,
+			err: "ingestionPath: audit does not support tenants",
+		},
+		{
+			name: "faults",
+			scenarios: `
+ingestionPath:
+  enabled: true
+  faults:
+    - name: restart
+      type: delete-pods
+      namespace: loki
+      workload: loki-ingester
+      kind: StatefulSet
+      offset: 1m
+    - name: no-distributors
+      type: scale
+      namespace: loki
+      workload: loki-distributor
+      duration: 1m
+    - name: slow-ingesters
+      type: latency
+      namespace: loki
+      workload: loki-ingester
+      kind: StatefulSet
+      duration: 1m
+      latency: 100ms
+`,
+		},
+		{
+			name: "fault without name",
+			scenarios: `
+ingestionPath:
+  enabled: true
+  faults:
+    - type: delete-pods
+      namespace: loki
+      workload: loki-ingester
+`,
+			err: "ingestionPath: fault 0: name is required",
+		},
+		{
+			name: "faults with the same name",
+			scenarios: `
+ingestionPath:
+  enabled: true
+  faults:
+    - name: restart
+      type: delete-pods
+      namespace: loki
+      workload: loki-ingester
+    - name: restart
+      type: delete-pods
+      namespace: loki
+      workload: loki-distributor
+`,
+			err: "ingestionPath: fault restart: duplicate name",
+		},
+		{
+			name: "fault without workload",
+			scenarios: `
+ingestionPath:
+  enabled: true
+  faults:
+    - name: restart
+      type: delete-pods
+      namespace: loki
+`,
+			err: "ingestionPath: fault restart: namespace and workload are required",
+		},
+		{
+			name: "fault of an unsupported kind",
+			scenarios: `
+ingestionPath:
+  enabled: true
+  faults:
+    - name: restart
+      type: delete-pods
+      namespace: loki
+      workload: loki-ingester
+      kind: DaemonSet
+`,
+			err: "ingestionPath: fault restart: unsupported kind: DaemonSet",
+		},
+		{
+			name: "fault of an unsupported type",
+			scenarios: `
+queryPath:
+  enabled: true
+  readers:
+    qps: 1
+  faults:
+    - name: partition
+      type: network-partition
+      namespace: loki
+      workload: loki-querier
+`,
+			err: "queryPath: fault partition: unsupported type: network-partition",
+		},
+		{
+			name: "scale fault without duration",
+			scenarios: `
+ingestionPath:
+  enabled: true
+  faults:
+    - name: no-distributors
+      type: scale
+      namespace: loki
+      workload: loki-distributor
+`,
+			err: "ingestionPath: fault no-distributors: scale faults require a positive duration",
+		},
+		{
+			name: "latency fault without latency",
+			scenarios: `
+ingestionPath:
+  enabled: true
+  faults:
+    - name: slow-ingesters
+      type: latency
+      namespace: loki
+      workload: loki-ingester
+      duration: 1m
+`,
+			err: "ingestionPath: fault slow-ingesters: latency faults require a positive duration and latency",
+		},
+		{
+			name: "query path without readers",
+			scenarios: `
+queryPath:
+  enabled: true
+`,
+			err: "queryPath: missing readers",
+		},
+		{
+			name: "open loop",
+			scenarios: `
+queryPath:
+  enabled: true
+  readers:
+    qps: 2
+    concurrency: 4
+    loop: open
+    arrival: poisson
+`,
+		},
+		{
+			name: "open loop without qps",
+			scenarios: `
+queryPath:
+  enabled: true
+  readers:
+    concurrency: 4
+    loop: open
+`,
+			err: "queryPath: readers: open loop requires a positive qps",
+		},
+		{
+			name: "unsupported loop",
+			scenarios: `
+queryPath:
+  enabled: true
+  readers:
+    qps: 2
+    loop: half-open
+`,
+			err: "queryPath: readers: unsupported loop: half-open",
+		},
+		{
+			name: "unsupported arrival",
+			scenarios: `
+queryPath:
+  enabled: true
+  readers:
+    qps: 2
+    loop: open
+    arrival: bursty
+`,
+			err: "queryPath: readers: unsupported arrival: bursty",
+		},
+		{
+			name: "negative qps",
+			scenarios: `
+queryPath:
+  enabled: true
+  readers:
+    qps: -1
+`,
+			err: "queryPath: readers: qps and concurrency must not be negative",
+		},
+		{
+			name: "open loop aggressor without qps",
+			scenarios: `
+noisyNeighbor:
+  enabled: true
+  victim:
+    tenant: victim
+  aggressor:
+    tenant: aggressor
+    readers:
+      loop: open
+`,
+			err: "noisyNeighbor: aggressor: readers: open loop requires a positive qps",
+		},
+		{
+			name: "open loop deletion readers without qps",
+			scenarios: `
+deletion:
+  enabled: true
+  readers:
+    loop: open
+`,
+			err: "deletion: readers: open loop requires a positive qps",
+		},
+		{
+			name: "retention",
+			scenarios: `
+retention:
+  enabled: true
+  period: 24h
+  namespace: observatorium
+  selector:
+    app.kubernetes.io/component: compactor
+`,
+		},
+		{
+			name: "retention without period",
+			scenarios: `
+retention:
+  enabled: true
+  namespace: observatorium
+  selector:
+    app.kubernetes.io/component: compactor
+`,
+			err: "retention: period must be positive",
+		},
+		{
+			name: "retention without selector",
+			scenarios: `
+retention:
+  enabled: true
+  period: 24h
+  namespace: observatorium
+`,
+			err: "retention: namespace and selector are required",
+		},
+		{
+			name: "replay",
+			scenarios: `
+ingestionPath:
+  enabled: true
+  writers:
+    replay:
+      configMap: corpus
+      file: corpus.jsonl
+      format: loki
+`,
+		},
+		{
+			name: "replay without configMap or persistentVolumeClaim",
+			scenarios: `
+ingestionPath:
+  enabled: true
+  writers:
+    replay:
+      file: corpus.jsonl
+`,
+			err: "ingestionPath: writers: replay: configMap or persistentVolumeClaim is required",
+		},
+		{
+			name: "replay with configMap and persistentVolumeClaim",
+			scenarios: `
+queryPath:
+  enabled: true
+  readers:
+    queries:
+      logs: '{client="promtail"}'
+  generator:
+    replay:
+      configMap: corpus
+      persistentVolumeClaim: corpus
+      file: corpus.jsonl
+`,
+			err: "queryPath: generator: replay: configMap and persistentVolumeClaim are mutually exclusive",
+		},
+		{
+			name: "replay without file",
+			scenarios: `
+restart:
+  enabled: true
+  writers:
+    replay:
+      persistentVolumeClaim: corpus
+`,
+			err: "restart: writers: replay: file is required",
+		},
+		{
+			name: "replay of an unsupported format",
+			scenarios: `
+noisyNeighbor:
+  enabled: true
+  aggressor:
+    writers:
+      replay:
+        configMap: corpus
+        file: corpus.json
+        format: json
+`,
+			err: "noisyNeighbor: aggressor: writers: replay: unsupported format: json",
+		},
+		{
+			name: "tenant replay without configMap or persistentVolumeClaim",
+			scenarios: `
+ingestionPath:
+  enabled: true
+  tenants:
+    - name: tenant-a
+      replay:
+        file: corpus.jsonl
+`,
+			err: "ingestionPath: tenant tenant-a: replay: configMap or persistentVolumeClaim is required",
+		},
+		{
+			name: "query stats without selector",
+			scenarios: `
+queryPath:
+  enabled: true
+  readers:
+    queries:
+      logs: '{client="promtail"}'
+  queryStats:
+    namespace: observatorium
+`,
+			err: "queryPath: queryStats: namespace and selector are required",
+		},
+		{
+			name: "structured metadata filters",
+			scenarios: `
+queryPath:
+  enabled: true
+  structuredMetadata:
+    fields:
+      - name: trace_id
+        cardinality: 10
+        size: 32
+    queries:
+      trace: '{client="promtail"} | trace_id="00000000000000000000000000000001"'
+      rate: 'sum(rate({client="promtail"} | trace_id=~"0+1" [1s]))'
+  readers:
+    queries:
+      trace: '{client="promtail"} |= "trace_id=00000000000000000000000000000001"'
+      rate: 'sum(rate({client="promtail"} |= "trace_id=00000000000000000000000000000001" [1s]))'
+`,
+		},
+		{
+			name: "structured metadata query without reader query",
+			scenarios: `
+queryPath:
+  enabled: true
+  structuredMetadata:
+    fields:
+      - name: trace_id
+    queries:
+      traceMetadata: '{client="promtail"} | trace_id="1"'
+  readers:
+    queries:
+      traceLine: '{client="promtail"} |= "trace_id=1"'
+`,
+			err: "queryPath: structuredMetadata: query traceMetadata has no reader query of the same id",
+		},
+		{
+			name: "structured metadata query with a line filter",
+			scenarios: `
+queryPath:
+  enabled: true
+  structuredMetadata:
+    fields:
+      - name: trace_id
+    queries:
+      trace: '{client="promtail"} |= "trace_id=1"'
+  readers:
+    queries:
+      trace: '{client="promtail"} |= "trace_id=1"'
+`,
+			err: "queryPath: structuredMetadata: query trace does not filter on a structured metadata field",
+		},
+		{
+			name: "structured metadata query filtering on another label",
+			scenarios: `
+queryPath:
+  enabled: true
+  structuredMetadata:
+    fields:
+      - name: trace_id
+    queries:
+      trace: '{client="promtail"} | json | level="error"'
+  readers:
+    queries:
+      trace: '{client="promtail"} |= "trace_id=1"'
+`,
+			err: "queryPath: structuredMetadata: query trace does not filter on a structured metadata field",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Benchmark{}
+			if tt.scenarios != "" {
+				cfg.Scenarios = &Scenarios{}
+				if err := yaml.Unmarshal([]byte(tt.scenarios), cfg.Scenarios); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := cfg.Validate()
+			if tt.err == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestValidateScenarioFiles(t *testing.T) {
+	files, err := filepath.Glob("../../config/benchmarks/scenarios/*/*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no scenario files found")
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := &Benchmark{}
+			if err := yaml.Unmarshal(data, cfg); err != nil {
+				t.Fatalf("invalid scenario: %v", err)
+			}
+			if err := cfg.Validate(); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
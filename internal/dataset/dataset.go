@@ -0,0 +1,102 @@
+// Package dataset describes a deterministic log dataset. Every stream holds
+// LinesPerSecond lines per second on a fixed timestamp grid and the content
+// of every line only depends on the seed, the stream and the timestamp. The
+// expected result of a query over any past window can therefore be computed
+// without knowing what was pushed.
+package dataset
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+)
+
+const (
+	DatasetLabel = "dataset"
+	StreamLabel  = "stream"
+)
+
+var Levels = []string{"debug", "info", "warn", "error"}
+
+type Dataset struct {
+	Seed           int64
+	Streams        int
+	LinesPerSecond int
+}
+
+func (d *Dataset) Validate() error {
+	if d.Streams < 1 {
+		return fmt.Errorf("invalid number of dataset streams: %d", d.Streams)
+	}
+	if d.LinesPerSecond < 1 || d.LinesPerSecond > int(time.Second) {
+		return fmt.Errorf("invalid dataset lines per second: %d", d.LinesPerSecond)
+	}
+	return nil
+}
+
+// Selector returns the stream selector matching all streams of the dataset.
+func (d *Dataset) Selector() string {
+	return fmt.Sprintf(`{%s="%d"}`, DatasetLabel, d.Seed)
+}
+
+// StreamSelector returns the stream selector matching a single stream.
+func (d *Dataset) StreamSelector(stream int) string {
+	return fmt.Sprintf(`{%s="%d", %s="%d"}`, DatasetLabel, d.Seed, StreamLabel, stream)
+}
+
+func (d *Dataset) Labels(stream int) map[string]string {
+	return map[string]string{
+		DatasetLabel: strconv.FormatInt(d.Seed, 10),
+		StreamLabel:  strconv.Itoa(stream),
+	}
+}
+
+func (d *Dataset) step() time.Duration {
+	return time.Second / time.Duration(d.LinesPerSecond)
+}
+
+// Entries returns the lines of the stream with timestamps in (start, end].
+func (d *Dataset) Entries(stream int, start, end time.Time) []loki.Entry {
+	var entries []loki.Entry
+	d.each(stream, start, end, func(k int64, ts time.Time) {
+		entries = append(entries, loki.Entry{Timestamp: ts, Line: d.line(stream, k, ts)})
+	})
+	return entries
+}
+
+// Count returns the number of lines of the stream with timestamps in
+// (start, end] and the given level, or of any level if level is empty.
+func (d *Dataset) Count(stream int, start, end time.Time, level string) int {
+	n := 0
+	d.each(stream, start, end, func(k int64, _ time.Time) {
+		if level == "" || d.level(stream, k) == level {
+			n++
+		}
+	})
+	return n
+}
+
+// each calls fn for every grid point k of the stream in (start, end].
+func (d *Dataset) each(stream int, start, end time.Time, fn func(k int64, ts time.Time)) {
+	step := int64(d.step())
+	first := start.UnixNano()/step + 1
+	last := end.UnixNano() / step
+
+	for k := first; k <= last; k++ {
+		fn(k, time.Unix(0, k*step))
+	}
+}
+
+func (d *Dataset) level(stream int, k int64) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d/%d/%d", d.Seed, stream, k)
+	return Levels[h.Sum64()%uint64(len(Levels))]
+}
+
+func (d *Dataset) line(stream int, k int64, ts time.Time) string {
+	return fmt.Sprintf("ts=%s level=%s stream=%d seq=%d msg=\"deterministic line\"",
+		ts.UTC().Format(time.RFC3339Nano), d.level(stream, k), stream, k)
+}
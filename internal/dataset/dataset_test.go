@@ -0,0 +1,139 @@
+package dataset
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		dataset Dataset
+		err     string
+	}{
+		{name: "valid", dataset: Dataset{Streams: 1, LinesPerSecond: 10}},
+		{name: "no streams", dataset: Dataset{LinesPerSecond: 10}, err: "invalid number of dataset streams: 0"},
+		{name: "no lines", dataset: Dataset{Streams: 1}, err: "invalid dataset lines per second: 0"},
+		{name: "more lines than nanoseconds", dataset: Dataset{Streams: 1, LinesPerSecond: int(time.Second) + 1}, err: "invalid dataset lines per second"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.dataset.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestEntries(t *testing.T) {
+	d := &Dataset{Seed: 7, Streams: 2, LinesPerSecond: 4}
+	start := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		want  int
+	}{
+		{name: "one second excludes the start", start: start, end: start.Add(time.Second), want: 4},
+		{name: "between grid points", start: start.Add(time.Millisecond), end: start.Add(260 * time.Millisecond), want: 1},
+		{name: "empty window", start: start, end: start, want: 0},
+		{name: "ten seconds", start: start, end: start.Add(10 * time.Second), want: 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := d.Entries(1, tt.start, tt.end)
+			if len(entries) != tt.want {
+				t.Fatalf("got %d entries, want %d", len(entries), tt.want)
+			}
+			if n := d.Count(1, tt.start, tt.end, ""); n != tt.want {
+				t.Errorf("got count %d, want %d", n, tt.want)
+			}
+
+			for _, e := range entries {
+				if !e.Timestamp.After(tt.start) || e.Timestamp.After(tt.end) {
+					t.Errorf("entry at %s outside of (%s, %s]", e.Timestamp, tt.start, tt.end)
+				}
+				if !strings.Contains(e.Line, "stream=1 ") {
+					t.Errorf("got line %q of another stream", e.Line)
+				}
+			}
+		})
+	}
+}
+
+func TestCountByLevel(t *testing.T) {
+	d := &Dataset{Seed: 7, Streams: 1, LinesPerSecond: 100}
+	start := time.Unix(1700000000, 0)
+	end := start.Add(time.Minute)
+
+	total := 0
+	for _, level := range Levels {
+		n := d.Count(0, start, end, level)
+		if n == 0 {
+			t.Errorf("no lines of level %s", level)
+		}
+
+		lines := 0
+		for _, e := range d.Entries(0, start, end) {
+			if strings.Contains(e.Line, " level="+level+" ") {
+				lines++
+			}
+		}
+		if lines != n {
+			t.Errorf("got %d lines of level %s, count %d", lines, level, n)
+		}
+		total += n
+	}
+
+	if want := d.Count(0, start, end, ""); total != want {
+		t.Errorf("got %d lines over all levels, want %d", total, want)
+	}
+}
+
+func TestDeterminism(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	end := start.Add(time.Second)
+
+	a := (&Dataset{Seed: 1, Streams: 1, LinesPerSecond: 10}).Entries(0, start, end)
+	b := (&Dataset{Seed: 1, Streams: 1, LinesPerSecond: 10}).Entries(0, start, end)
+	c := (&Dataset{Seed: 2, Streams: 1, LinesPerSecond: 10}).Entries(0, start, end)
+
+	for i := range a {
+		if a[i].Line != b[i].Line || !a[i].Timestamp.Equal(b[i].Timestamp) {
+			t.Fatalf("entry %d differs for the same seed: %v and %v", i, a[i], b[i])
+		}
+	}
+
+	same := true
+	for i := range a {
+		same = same && a[i].Line == c[i].Line
+	}
+	if same {
+		t.Error("entries do not depend on the seed")
+	}
+}
+
+func TestSelectors(t *testing.T) {
+	d := &Dataset{Seed: 7}
+
+	if got := d.Selector(); got != `{dataset="7"}` {
+		t.Errorf("got selector %s", got)
+	}
+	if got := d.StreamSelector(3); got != `{dataset="7", stream="3"}` {
+		t.Errorf("got stream selector %s", got)
+	}
+	if got := d.Labels(3); got[DatasetLabel] != "7" || got[StreamLabel] != "3" {
+		t.Errorf("got labels %v", got)
+	}
+}
@@ -0,0 +1,162 @@
+package deletion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+)
+
+type Deleter struct {
+	client *loki.Client
+}
+
+func NewDeleter(client *loki.Client) *Deleter {
+	return &Deleter{client: client}
+}
+
+// Run submits all requests at once, polls the delete requests every
+// pollInterval until all of them are processed or timeout passed, and then
+// counts the lines left in every deleted range.
+func (d *Deleter) Run(ctx context.Context, requests []Request, pollInterval, timeout time.Duration) (*Summary, error) {
+	now := time.Now()
+
+	results := make([]Result, 0, len(requests))
+	for _, req := range requests {
+		end := now.Add(-req.Offset).Truncate(time.Second)
+		res := Result{
+			Name:  req.Name,
+			Query: req.Query,
+			Start: end.Add(-req.Range),
+			End:   end,
+		}
+
+		lines, err := d.count(ctx, res)
+		if err != nil {
+			return nil, fmt.Errorf("failed counting lines of delete request %s: %w", req.Name, err)
+		}
+		res.Lines = lines
+
+		if err := d.client.Delete(ctx, res.Query, res.Start, res.End); err != nil {
+			return nil, fmt.Errorf("failed submitting delete request %s: %w", req.Name, err)
+		}
+		res.submitted = time.Now()
+
+		log.Printf("submitted delete request %s: %d lines between %s and %s", res.Name, res.Lines,
+			res.Start.Format(time.RFC3339), res.End.Format(time.RFC3339))
+		results = append(results, res)
+	}
+
+	if err := d.wait(ctx, results, pollInterval, timeout); err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		remaining, err := d.count(ctx, results[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed counting remaining lines of delete request %s: %w", results[i].Name, err)
+		}
+		results[i].Remaining = remaining
+
+		log.Printf("delete request %s: processed %t after %.0fs, %d of %d lines remaining", results[i].Name,
+			results[i].Processed, results[i].Duration, results[i].Remaining, results[i].Lines)
+	}
+
+	return Summarize(results), nil
+}
+
+// wait polls the delete requests until all results are processed. Requests
+// still pending after timeout stay unprocessed.
+func (d *Deleter) wait(ctx context.Context, results []Result, pollInterval, timeout time.Duration) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	deadline := time.After(timeout)
+
+	for {
+		reqs, err := d.client.DeleteRequests(ctx)
+		if err != nil {
+			log.Printf("failed listing delete requests: %v", err)
+		}
+
+		pending := 0
+		for i := range results {
+			res := &results[i]
+			if res.Processed {
+				continue
+			}
+
+			if isProcessed(reqs, res) {
+				res.Processed = true
+				res.Duration = time.Since(res.submitted).Seconds()
+				continue
+			}
+
+			pending++
+		}
+
+		if pending == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			log.Printf("%d delete requests not processed within %s", pending, timeout)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// isProcessed reports whether Loki lists the result as processed. The list
+// does not contain the request IDs on submission, so that requests are
+// matched by query and range.
+func isProcessed(reqs []loki.DeleteRequest, res *Result) bool {
+	for _, req := range reqs {
+		if req.Query != res.Query || int64(req.StartTime) != res.Start.Unix() || int64(req.EndTime) != res.End.Unix() {
+			continue
+		}
+
+		return req.Status == loki.DeleteStatusProcessed
+	}
+
+	return false
+}
+
+// count returns the number of lines matching the query of the result within
+// its range.
+func (d *Deleter) count(ctx context.Context, res Result) (int64, error) {
+	query := fmt.Sprintf(`sum(count_over_time(%s[%ds]))`, res.Query, int(res.End.Sub(res.Start).Seconds()))
+
+	out, err := d.client.Query(ctx, query, res.End, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	var vector []loki.VectorResult
+	if err := json.Unmarshal(out.Data.Result, &vector); err != nil {
+		return 0, fmt.Errorf("failed decoding vector: %w", err)
+	}
+
+	if len(vector) == 0 || len(vector[0].Value) < 2 {
+		return 0, nil
+	}
+
+	raw, ok := vector[0].Value[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected sample value: %v", vector[0].Value[1])
+	}
+
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed parsing sample value: %w", err)
+	}
+
+	return int64(value), nil
+}
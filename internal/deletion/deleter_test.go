@@ -0,0 +1,141 @@
+package deletion
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+)
+
+func TestIsProcessed(t *testing.T) {
+	res := &Result{Query: `{job="a"}`, Start: time.Unix(100, 0), End: time.Unix(200, 0)}
+
+	tests := []struct {
+		name string
+		reqs []loki.DeleteRequest
+		want bool
+	}{
+		{name: "not listed"},
+		{
+			name: "received",
+			reqs: []loki.DeleteRequest{{Query: `{job="a"}`, StartTime: 100, EndTime: 200, Status: loki.DeleteStatusReceived}},
+		},
+		{
+			name: "processed",
+			reqs: []loki.DeleteRequest{{Query: `{job="a"}`, StartTime: 100, EndTime: 200, Status: loki.DeleteStatusProcessed}},
+			want: true,
+		},
+		{
+			name: "processed with another range",
+			reqs: []loki.DeleteRequest{{Query: `{job="a"}`, StartTime: 100, EndTime: 300, Status: loki.DeleteStatusProcessed}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isProcessed(tt.reqs, res); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeLoki deletes the lines of a request on its second listing. The lines
+// left after a deletion are given by remaining.
+type fakeLoki struct {
+	mu        sync.Mutex
+	requests  []loki.DeleteRequest
+	listed    int
+	remaining int
+}
+
+func (f *fakeLoki) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	params := r.URL.Query()
+
+	switch {
+	case r.URL.Path == loki.DeletePath && r.Method == http.MethodPost:
+		start, _ := strconv.ParseFloat(params.Get("start"), 64)
+		end, _ := strconv.ParseFloat(params.Get("end"), 64)
+		f.requests = append(f.requests, loki.DeleteRequest{
+			Query:     params.Get("query"),
+			StartTime: start,
+			EndTime:   end,
+			Status:    loki.DeleteStatusReceived,
+		})
+		w.WriteHeader(http.StatusNoContent)
+	case r.URL.Path == loki.DeletePath:
+		if f.listed++; f.listed > 1 {
+			for i := range f.requests {
+				f.requests[i].Status = loki.DeleteStatusProcessed
+			}
+		}
+		_ = json.NewEncoder(w).Encode(f.requests)
+	case r.URL.Path == loki.QueryPath:
+		count := 100
+		for _, req := range f.requests {
+			if req.Status == loki.DeleteStatusProcessed && strings.Contains(params.Get("query"), req.Query) {
+				count = f.remaining
+			}
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1,"` + strconv.Itoa(count) + `"]}]}}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestDeleterRun(t *testing.T) {
+	requests := []Request{
+		{Name: "recent", Query: `{job="a"}`, Offset: time.Hour, Range: time.Hour},
+		{Name: "old", Query: `{job="b"} |= "error"`, Offset: 3 * time.Hour, Range: 30 * time.Minute},
+	}
+
+	tests := []struct {
+		name      string
+		remaining int
+		timeout   time.Duration
+		processed int
+		pass      bool
+	}{
+		{name: "all deleted", timeout: time.Minute, processed: 2, pass: true},
+		{name: "lines remaining", remaining: 3, timeout: time.Minute, processed: 2, pass: false},
+		{name: "not processed in time", timeout: time.Millisecond, processed: 0, pass: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeLoki{remaining: tt.remaining}
+			srv := httptest.NewServer(fake)
+			defer srv.Close()
+
+			client, err := loki.NewClient(srv.URL, "tenant", "", time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := NewDeleter(client).Run(context.Background(), requests, 10*time.Millisecond, tt.timeout)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if s.Processed != tt.processed || s.Pass != tt.pass || s.Lines != 200 {
+				t.Errorf("got %+v, want %d processed and pass %v", s, tt.processed, tt.pass)
+			}
+
+			for i, res := range s.Results {
+				if got := res.End.Sub(res.Start); got != requests[i].Range {
+					t.Errorf("got range %s of request %s, want %s", got, res.Name, requests[i].Range)
+				}
+			}
+		})
+	}
+}
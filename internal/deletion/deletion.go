@@ -0,0 +1,89 @@
+package deletion
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Request deletes the lines matching Query over Range, ending Offset before
+// the request is submitted. Query is a stream selector, optionally followed
+// by line filters.
+type Request struct {
+	Name   string
+	Query  string
+	Offset time.Duration
+	Range  time.Duration
+}
+
+// String formats the request as name:offset:range:query, the format of the
+// --request flag of loki-delete.
+func (r Request) String() string {
+	return fmt.Sprintf("%s:%s:%s:%s", r.Name, r.Offset, r.Range, r.Query)
+}
+
+// ParseRequest parses a request formatted by String. The query is last, so
+// that it may contain colons.
+func ParseRequest(s string) (Request, error) {
+	parts := strings.SplitN(s, ":", 4)
+	if len(parts) != 4 {
+		return Request{}, fmt.Errorf("invalid delete request %q, expected name:offset:range:query", s)
+	}
+
+	offset, err := time.ParseDuration(parts[1])
+	if err != nil {
+		return Request{}, fmt.Errorf("invalid offset of delete request %s: %w", parts[0], err)
+	}
+
+	window, err := time.ParseDuration(parts[2])
+	if err != nil {
+		return Request{}, fmt.Errorf("invalid range of delete request %s: %w", parts[0], err)
+	}
+
+	if window <= 0 {
+		return Request{}, fmt.Errorf("empty range of delete request %s", parts[0])
+	}
+
+	return Request{Name: parts[0], Offset: offset, Range: window, Query: parts[3]}, nil
+}
+
+// Result describes how long a request took until Loki marked it processed
+// and how many of the deleted lines can still be queried afterwards.
+type Result struct {
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Lines     int64     `json:"lines"`
+	Processed bool      `json:"processed"`
+	Duration  float64   `json:"duration"`
+	Remaining int64     `json:"remaining"`
+
+	submitted time.Time
+}
+
+// Summary is the outcome of all requests. It passes if every request was
+// processed and none of the deleted lines can be queried anymore.
+type Summary struct {
+	Requests  int      `json:"requests"`
+	Processed int      `json:"processed"`
+	Lines     int64    `json:"lines"`
+	Remaining int64    `json:"remaining"`
+	Pass      bool     `json:"pass"`
+	Results   []Result `json:"results,omitempty"`
+}
+
+func Summarize(results []Result) *Summary {
+	s := &Summary{Requests: len(results), Results: results}
+
+	for _, r := range results {
+		if r.Processed {
+			s.Processed++
+		}
+		s.Lines += r.Lines
+		s.Remaining += r.Remaining
+	}
+
+	s.Pass = len(results) > 0 && s.Processed == s.Requests && s.Remaining == 0
+	return s
+}
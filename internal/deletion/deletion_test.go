@@ -0,0 +1,87 @@
+package deletion
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want Request
+		err  string
+	}{
+		{
+			name: "query with colons",
+			in:   `errors:1h:30m:{job="a"} |= "level:error"`,
+			want: Request{Name: "errors", Offset: time.Hour, Range: 30 * time.Minute, Query: `{job="a"} |= "level:error"`},
+		},
+		{
+			name: "missing query",
+			in:   "errors:1h:30m",
+			err:  "expected name:offset:range:query",
+		},
+		{
+			name: "invalid offset",
+			in:   `errors:soon:30m:{job="a"}`,
+			err:  "invalid offset of delete request errors",
+		},
+		{
+			name: "invalid range",
+			in:   `errors:1h:long:{job="a"}`,
+			err:  "invalid range of delete request errors",
+		},
+		{
+			name: "empty range",
+			in:   `errors:1h:0s:{job="a"}`,
+			err:  "empty range of delete request errors",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRequest(tt.in)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+
+			again, err := ParseRequest(got.String())
+			if err != nil || again != got {
+				t.Errorf("got %+v, %v after a round trip", again, err)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []Result
+		pass    bool
+	}{
+		{name: "no requests", pass: false},
+		{name: "all processed and deleted", results: []Result{{Processed: true, Lines: 10}, {Processed: true, Lines: 5}}, pass: true},
+		{name: "not processed", results: []Result{{Processed: true, Lines: 10}, {Lines: 5, Remaining: 5}}, pass: false},
+		{name: "lines remaining", results: []Result{{Processed: true, Lines: 10, Remaining: 1}}, pass: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Summarize(tt.results)
+			if s.Pass != tt.pass || s.Requests != len(tt.results) {
+				t.Errorf("got %+v, want pass %v", s, tt.pass)
+			}
+		})
+	}
+}
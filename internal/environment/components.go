@@ -0,0 +1,48 @@
+package environment
+
+import (
+	"fmt"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Components returns the Deployments and StatefulSets of the Loki targets
+// named after prefix in the deployment mode. The query-scheduler only exists
+// in microservices mode and only in some deployments.
+func Components(mode, prefix, namespace string, queryScheduler bool) ([]client.Object, error) {
+	deployment := func(name string) client.Object {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+	statefulSet := func(name string) client.Object {
+		return &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+
+	switch mode {
+	case "", config.DeploymentModeMicroservices:
+		objs := []client.Object{
+			deployment(prefix + "-querier"),
+			deployment(prefix + "-query-frontend"),
+			deployment(prefix + "-distributor"),
+			statefulSet(prefix + "-ingester"),
+			statefulSet(prefix + "-index-gateway"),
+		}
+		if queryScheduler {
+			objs = append(objs, deployment(prefix+"-query-scheduler"))
+		}
+		return objs, nil
+	case config.DeploymentModeSimpleScalable:
+		return []client.Object{
+			deployment(prefix + "-read"),
+			statefulSet(prefix + "-write"),
+			statefulSet(prefix + "-backend"),
+		}, nil
+	case config.DeploymentModeMonolithic:
+		return []client.Object{statefulSet(prefix)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported deployment mode: %s", mode)
+	}
+}
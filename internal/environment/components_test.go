@@ -0,0 +1,93 @@
+package environment
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func TestComponents(t *testing.T) {
+	tests := []struct {
+		name           string
+		mode           string
+		queryScheduler bool
+		want           []string
+		err            string
+	}{
+		{
+			name: "default mode",
+			want: []string{
+				"Deployment/loki-querier",
+				"Deployment/loki-query-frontend",
+				"Deployment/loki-distributor",
+				"StatefulSet/loki-ingester",
+				"StatefulSet/loki-index-gateway",
+			},
+		},
+		{
+			name:           "microservices mode with query-scheduler",
+			mode:           config.DeploymentModeMicroservices,
+			queryScheduler: true,
+			want: []string{
+				"Deployment/loki-querier",
+				"Deployment/loki-query-frontend",
+				"Deployment/loki-distributor",
+				"StatefulSet/loki-ingester",
+				"StatefulSet/loki-index-gateway",
+				"Deployment/loki-query-scheduler",
+			},
+		},
+		{
+			name:           "simple scalable mode",
+			mode:           config.DeploymentModeSimpleScalable,
+			queryScheduler: true,
+			want:           []string{"Deployment/loki-read", "StatefulSet/loki-write", "StatefulSet/loki-backend"},
+		},
+		{
+			name: "monolithic mode",
+			mode: config.DeploymentModeMonolithic,
+			want: []string{"StatefulSet/loki"},
+		},
+		{
+			name: "unsupported mode",
+			mode: "distributed",
+			err:  "unsupported deployment mode: distributed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs, err := Components(tt.mode, "loki", "benchmarks", tt.queryScheduler)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := make([]string, 0, len(objs))
+			for _, obj := range objs {
+				if obj.GetNamespace() != "benchmarks" {
+					t.Errorf("%s is in namespace %q", obj.GetName(), obj.GetNamespace())
+				}
+
+				kind := "Deployment"
+				if _, ok := obj.(*appsv1.StatefulSet); ok {
+					kind = "StatefulSet"
+				}
+				got = append(got, fmt.Sprintf("%s/%s", kind, obj.GetName()))
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
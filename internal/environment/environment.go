@@ -0,0 +1,362 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/readiness"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	k8sconfig "sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const (
+	FlavorObservatorium = "observatorium"
+	FlavorRHOBS         = "rhobs"
+	FlavorOperator      = "operator"
+
+	// OpenShiftOperatorNamespace is the namespace the LokiStack is deployed
+	// into on OpenShift.
+	OpenShiftOperatorNamespace = "openshift-logging"
+
+	operatorsNamespace     = "openshift-operators-redhat"
+	clusterMonitoringLabel = "openshift.io/cluster-monitoring"
+
+	loadClientRBACFile     = "hack/loadclient-rbac.yaml"
+	scriptsDir             = "hack/scripts"
+	openShiftMonitoringDir = "config/openshift"
+
+	readinessPollInterval   = 5 * time.Second
+	defaultReadinessTimeout = 10 * time.Minute
+)
+
+// Options describe the benchmarking environment. Paths are relative to the
+// root of this repository.
+type Options struct {
+	Namespace       string
+	Flavor          string
+	ComponentPrefix string
+	DeploymentMode  string
+	OpenShift       bool
+	Cadvisor        bool
+	StorageBucket   string
+
+	// Kind creates a kind cluster for the environment and deletes it again.
+	Kind bool
+
+	RHOBSDeploymentFile string
+	OperatorRegistry    string
+
+	ObservatoriumDir string
+	OperatorDir      string
+	CadvisorDir      string
+
+	KindBinary      string
+	KustomizeBinary string
+
+	Timeout time.Duration
+}
+
+// Environment creates, deploys and destroys the Loki under benchmark.
+type Environment struct {
+	opts   Options
+	client client.Client
+}
+
+func New(opts Options) *Environment {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultReadinessTimeout
+	}
+	return &Environment{opts: opts}
+}
+
+// Client returns the Kubernetes client, created on first use so that it
+// picks up the kind cluster.
+func (e *Environment) Client() (client.Client, error) {
+	if e.client != nil {
+		return e.client, nil
+	}
+
+	cfg, err := k8sconfig.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading kubernetes configuration: %w", err)
+	}
+
+	mapper, err := apiutil.NewDynamicRESTMapper(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating REST mapper: %w", err)
+	}
+
+	c, err := client.New(cfg, client.Options{Scheme: scheme.Scheme, Mapper: mapper})
+	if err != nil {
+		return nil, fmt.Errorf("failed creating kubernetes client: %w", err)
+	}
+
+	e.client = c
+	return c, nil
+}
+
+// Create creates the cluster if requested, the namespace, cAdvisor and the
+// object storage bucket.
+func (e *Environment) Create(ctx context.Context) error {
+	if e.opts.Kind {
+		if err := command(ctx, "", nil, e.opts.KindBinary, "create", "cluster"); err != nil {
+			return err
+		}
+		// A client of an earlier cluster talks to an API server that no
+		// longer exists.
+		e.client = nil
+	}
+
+	c, err := e.Client()
+	if err != nil {
+		return err
+	}
+
+	if err := createNamespace(ctx, c, e.opts.Namespace); err != nil {
+		return err
+	}
+
+	if e.opts.Cadvisor {
+		manifests, err := output(ctx, e.opts.CadvisorDir, e.opts.KustomizeBinary, "build", "deploy/kubernetes/base")
+		if err != nil {
+			return err
+		}
+		if err := Apply(ctx, c, "", manifests); err != nil {
+			return fmt.Errorf("failed deploying cadvisor: %w", err)
+		}
+	}
+
+	if e.opts.OpenShift {
+		log.Printf("creating object storage bucket %s", e.opts.StorageBucket)
+		if err := command(ctx, "", nil, filepath.Join(scriptsDir, "create-s3-bucket.sh"), e.opts.StorageBucket); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Deploy deploys Loki in the flavor of the options and waits until all its
+// components are ready.
+func (e *Environment) Deploy(ctx context.Context) error {
+	c, err := e.Client()
+	if err != nil {
+		return err
+	}
+
+	queryScheduler := true
+
+	switch e.opts.Flavor {
+	case FlavorObservatorium:
+		err = e.deployObservatorium(ctx)
+	case FlavorRHOBS:
+		err = e.deployRHOBS(ctx, c)
+	case FlavorOperator:
+		// The LokiStack has no query-scheduler.
+		queryScheduler = false
+		err = e.deployOperator(ctx, c)
+	default:
+		err = fmt.Errorf("unsupported flavor: %s", e.opts.Flavor)
+	}
+	if err != nil {
+		return err
+	}
+
+	objs, err := Components(e.opts.DeploymentMode, e.opts.ComponentPrefix, e.opts.Namespace, queryScheduler)
+	if err != nil {
+		return err
+	}
+
+	waiter := readiness.NewWaiter(c, readinessPollInterval, e.opts.Timeout, readiness.LogProgress)
+	for _, obj := range objs {
+		if err := waiter.Wait(ctx, obj); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Environment) deployObservatorium(ctx context.Context) error {
+	kubectl, err := exec.LookPath("kubectl")
+	if err != nil {
+		return fmt.Errorf("failed finding kubectl: %w", err)
+	}
+
+	return command(ctx, e.opts.ObservatoriumDir, []string{"KUBECTL=" + kubectl}, "./configuration/tests/e2e.sh", "deploy")
+}
+
+func (e *Environment) deployRHOBS(ctx context.Context, c client.Client) error {
+	if err := ApplyFile(ctx, c, e.opts.Namespace, e.opts.RHOBSDeploymentFile); err != nil {
+		return err
+	}
+
+	return command(ctx, "", nil, filepath.Join(scriptsDir, "deploy-example-secret.sh"), e.opts.Namespace, e.opts.StorageBucket)
+}
+
+func (e *Environment) deployOperator(ctx context.Context, c client.Client) error {
+	if e.opts.OpenShift {
+		if err := createNamespace(ctx, c, operatorsNamespace); err != nil {
+			return err
+		}
+		if err := labelNamespace(ctx, c, e.opts.Namespace, clusterMonitoringLabel, "true"); err != nil {
+			return err
+		}
+	}
+
+	sha, err := output(ctx, e.opts.OperatorDir, "git", "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return err
+	}
+
+	args := []string{
+		"olm-deploy",
+		fmt.Sprintf("REGISTRY_BASE=quay.io/%s", e.opts.OperatorRegistry),
+		fmt.Sprintf("VERSION=v0.0.1-%s", sha),
+	}
+	lokiStackFile := "hack/lokistack_gateway_dev.yaml"
+	if e.opts.OpenShift {
+		args = append(args, "VARIANT=openshift")
+		lokiStackFile = "hack/lokistack_gateway_ocp.yaml"
+	}
+
+	if err := command(ctx, e.opts.OperatorDir, nil, "make", args...); err != nil {
+		return err
+	}
+
+	if e.opts.OpenShift {
+		if err := command(ctx, e.opts.OperatorDir, nil, "./hack/deploy-aws-storage-secret.sh", e.opts.StorageBucket); err != nil {
+			return err
+		}
+	}
+
+	if err := ApplyFile(ctx, c, e.opts.Namespace, filepath.Join(e.opts.OperatorDir, lokiStackFile)); err != nil {
+		return err
+	}
+	if err := ApplyFile(ctx, c, e.opts.Namespace, loadClientRBACFile); err != nil {
+		return err
+	}
+
+	// The components are created only after the operator reconciled the
+	// LokiStack.
+	waiter := readiness.NewWaiter(c, readinessPollInterval, e.opts.Timeout, readiness.LogProgress)
+	return waiter.Wait(ctx, readiness.LokiStack(e.opts.ComponentPrefix, e.opts.Namespace))
+}
+
+// Destroy removes everything Create and Deploy created. It continues after
+// failures and returns the first error.
+func (e *Environment) Destroy(ctx context.Context) error {
+	var errs []error
+
+	if e.opts.Kind {
+		errs = append(errs, command(ctx, "", nil, e.opts.KindBinary, "delete", "cluster"))
+		e.client = nil
+	} else {
+		errs = append(errs, e.destroyObjects(ctx))
+	}
+
+	if e.opts.OpenShift {
+		log.Printf("deleting object storage bucket %s", e.opts.StorageBucket)
+		errs = append(errs, command(ctx, "", nil, filepath.Join(scriptsDir, "delete-s3-bucket.sh"), e.opts.StorageBucket))
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Environment) destroyObjects(ctx context.Context) error {
+	c, err := e.Client()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	if e.opts.Cadvisor {
+		manifests, err := output(ctx, e.opts.CadvisorDir, e.opts.KustomizeBinary, "build", "deploy/kubernetes/base")
+		if err == nil {
+			err = Delete(ctx, c, "", manifests)
+		}
+		errs = append(errs, err)
+	}
+
+	if e.opts.OpenShift {
+		errs = append(errs, DisableUserWorkloadMonitoring(ctx, c))
+	}
+
+	errs = append(errs,
+		DeleteFile(ctx, c, e.opts.Namespace, loadClientRBACFile),
+		deleteNamespace(ctx, c, operatorsNamespace, e.opts.Timeout),
+		deleteNamespace(ctx, c, e.opts.Namespace, e.opts.Timeout),
+	)
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createNamespace(ctx context.Context, c client.Client, name string) error {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+
+	if err := c.Create(ctx, ns); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed creating namespace %s: %w", name, err)
+	}
+	return nil
+}
+
+// deleteNamespace deletes the namespace and waits until it is gone, so that
+// a following Create does not run into the terminating namespace.
+func deleteNamespace(ctx context.Context, c client.Client, name string, timeout time.Duration) error {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+
+	if err := c.Delete(ctx, ns); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed deleting namespace %s: %w", name, err)
+	}
+
+	err := wait.PollImmediateWithContext(ctx, readinessPollInterval, timeout, func(ctx context.Context) (bool, error) {
+		err := c.Get(ctx, client.ObjectKey{Name: name}, &corev1.Namespace{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed waiting for namespace %s to be deleted: %w", name, err)
+	}
+	return nil
+}
+
+func labelNamespace(ctx context.Context, c client.Client, name, key, value string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ns := &corev1.Namespace{}
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, ns); err != nil {
+			return err
+		}
+
+		if ns.Labels == nil {
+			ns.Labels = map[string]string{}
+		}
+		ns.Labels[key] = value
+
+		return c.Update(ctx, ns)
+	})
+}
@@ -0,0 +1,124 @@
+package environment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const fieldOwner = client.FieldOwner("loki-benchmarks")
+
+// ApplyFile applies the manifests in the file, see Apply.
+func ApplyFile(ctx context.Context, c client.Client, namespace, file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed reading manifests: %w", err)
+	}
+
+	if err := Apply(ctx, c, namespace, data); err != nil {
+		return fmt.Errorf("failed applying %s: %w", file, err)
+	}
+	return nil
+}
+
+// Apply applies the multi-document YAML manifests server side, like
+// kubectl apply. Namespaced objects without a namespace are applied into
+// namespace.
+func Apply(ctx context.Context, c client.Client, namespace string, data []byte) error {
+	objs, err := decodeManifests(c, namespace, data)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objs {
+		if err := c.Patch(ctx, obj, client.Apply, fieldOwner, client.ForceOwnership); err != nil {
+			return fmt.Errorf("failed applying %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// DeleteFile deletes the objects of the manifests in the file. Missing
+// objects and unknown kinds are ignored.
+func DeleteFile(ctx context.Context, c client.Client, namespace, file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed reading manifests: %w", err)
+	}
+
+	if err := Delete(ctx, c, namespace, data); err != nil {
+		return fmt.Errorf("failed deleting %s: %w", file, err)
+	}
+	return nil
+}
+
+// Delete deletes the objects of the manifests. Missing objects and unknown
+// kinds are ignored.
+func Delete(ctx context.Context, c client.Client, namespace string, data []byte) error {
+	objs, err := decodeManifests(c, namespace, data)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objs {
+		err := c.Delete(ctx, obj)
+		if err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			return fmt.Errorf("failed deleting %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+func decodeManifests(c client.Client, namespace string, data []byte) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+
+	dec := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := dec.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed decoding manifests: %w", err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+
+		if obj.GetNamespace() == "" && namespace != "" {
+			namespaced, err := isNamespaced(c, obj)
+			if err != nil {
+				return nil, err
+			}
+			if namespaced {
+				obj.SetNamespace(namespace)
+			}
+		}
+
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
+
+func isNamespaced(c client.Client, obj *unstructured.Unstructured) (bool, error) {
+	gvk := obj.GroupVersionKind()
+
+	mapping, err := c.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return false, fmt.Errorf("failed mapping %s: %w", gvk, err)
+	}
+
+	return mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
@@ -0,0 +1,33 @@
+package environment
+
+import (
+	"context"
+	"path/filepath"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var userWorkloadMonitoringFiles = map[string]string{
+	"openshift-monitoring":               "cluster-monitoring-config.yaml",
+	"openshift-user-workload-monitoring": "user-workload-monitoring-config.yaml",
+}
+
+// EnableUserWorkloadMonitoring lets the OpenShift user workload monitoring
+// scrape the Loki components.
+func EnableUserWorkloadMonitoring(ctx context.Context, c client.Client) error {
+	for namespace, file := range userWorkloadMonitoringFiles {
+		if err := ApplyFile(ctx, c, namespace, filepath.Join(openShiftMonitoringDir, file)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func DisableUserWorkloadMonitoring(ctx context.Context, c client.Client) error {
+	for namespace, file := range userWorkloadMonitoringFiles {
+		if err := DeleteFile(ctx, c, namespace, filepath.Join(openShiftMonitoringDir, file)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package loadclient
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+const (
+	AuditName = "audit"
+
+	auditBin = "/usr/local/bin/loki-audit"
+)
+
+// CreateAudit returns a job running loki-audit over the lines pushed by the
+// generators between start and end. It queries the querier pull URL with the
+// generator tenant and credentials and writes its summary to the termination
+// message of the pod.
+func CreateAudit(
+	audit *config.Audit,
+	start, end time.Time,
+	generator *config.Generator,
+	querier *config.Querier,
+) *batchv1.Job {
+	args := []string{
+		fmt.Sprintf("--%s=%s", "url", querier.PullURL),
+		fmt.Sprintf("--%s=%s", "tenant", generator.Tenant),
+		fmt.Sprintf("--%s=%s", "start", start.UTC().Format(time.RFC3339)),
+		fmt.Sprintf("--%s=%s", "end", end.UTC().Format(time.RFC3339)),
+		fmt.Sprintf("--%s=%g", "tolerance", audit.Tolerance),
+	}
+
+	if generator.TokenSecret != "" {
+		args = append(args, fmt.Sprintf("--%s=%s", "bearer-token-file", path.Join(tokenMountPath, tokenSecretKey)))
+	} else if generator.ServiceAccount != "" {
+		args = append(args, fmt.Sprintf("--%s=%s", "bearer-token-file", serviceAccountTokenFile))
+	}
+
+	job := NewLoadClientJob(AuditName, generator.Namespace, loadGenImage(generator), generator.ServiceAccount, args, 1)
+	// The labels map is shared by the job and the pod template.
+	job.Labels["app"] = "loki-benchmarks-audit"
+	job.Spec.Template.Spec.Containers[0].Name = "audit"
+	job.Spec.Template.Spec.Containers[0].Command = []string{auditBin}
+
+	if generator.TokenSecret != "" {
+		mountTokenSecret(&job.Spec.Template.Spec, generator.TokenSecret)
+	}
+
+	return job
+}
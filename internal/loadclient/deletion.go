@@ -0,0 +1,60 @@
+package loadclient
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+	"github.com/observatorium/loki-benchmarks/internal/deletion"
+
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+const (
+	DeletionName = "deletion"
+
+	deleteBin = "/usr/local/bin/loki-delete"
+)
+
+// CreateDeletion returns a job running loki-delete with the delete requests
+// of the scenario. It sends them to the querier pull URL, which must route
+// the delete API to the compactor, with the generator tenant and credentials
+// and writes its summary to the termination message of the pod.
+func CreateDeletion(
+	scenario *config.Deletion,
+	generator *config.Generator,
+	querier *config.Querier,
+) *batchv1.Job {
+	args := []string{
+		fmt.Sprintf("--%s=%s", "url", querier.PullURL),
+		fmt.Sprintf("--%s=%s", "tenant", generator.Tenant),
+		fmt.Sprintf("--%s=%s", "process-timeout", scenario.WaitTimeout()),
+	}
+
+	if scenario.PollInterval > 0 {
+		args = append(args, fmt.Sprintf("--%s=%s", "poll-interval", scenario.PollInterval))
+	}
+
+	for _, req := range scenario.Requests {
+		r := deletion.Request{Name: req.Name, Query: req.Query, Offset: req.Offset, Range: req.Range}
+		args = append(args, fmt.Sprintf("--%s=%s", "request", r))
+	}
+
+	if generator.TokenSecret != "" {
+		args = append(args, fmt.Sprintf("--%s=%s", "bearer-token-file", path.Join(tokenMountPath, tokenSecretKey)))
+	} else if generator.ServiceAccount != "" {
+		args = append(args, fmt.Sprintf("--%s=%s", "bearer-token-file", serviceAccountTokenFile))
+	}
+
+	job := NewLoadClientJob(DeletionName, generator.Namespace, loadGenImage(generator), generator.ServiceAccount, args, 1)
+	// The labels map is shared by the job and the pod template.
+	job.Labels["app"] = "loki-benchmarks-deletion"
+	job.Spec.Template.Spec.Containers[0].Name = "deletion"
+	job.Spec.Template.Spec.Containers[0].Command = []string{deleteBin}
+
+	if generator.TokenSecret != "" {
+		mountTokenSecret(&job.Spec.Template.Spec, generator.TokenSecret)
+	}
+
+	return job
+}
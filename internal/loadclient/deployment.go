@@ -2,10 +2,14 @@ package loadclient
 
 import (
 	"fmt"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/observatorium/loki-benchmarks/internal/config"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
@@ -14,9 +18,70 @@ import (
 
 const (
 	DeploymentName = "generator"
+	BackfillName   = "backfill"
+	DatasetName    = "dataset"
+
+	// LoadGenImage is the default image shipping the loki-loadgen binary of
+	// this repository. It is used for generator features the configured load
+	// client image does not support, e.g. structured metadata.
+	LoadGenImage = "quay.io/observatorium/loki-benchmarks:latest"
+
+	serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	corpusVolumeName = "corpus"
+	corpusMountPath  = "/var/lib/loki-benchmarks/corpus"
+
+	tokenVolumeName = "token"
+	tokenMountPath  = "/var/run/secrets/loki-benchmarks/token"
+	tokenSecretKey  = "token"
 )
 
-func CreateGenerator(scenarioCfg *config.Writer, cfg *config.Generator) client.Object {
+func CreateGenerator(scenarioCfg *config.Writer, metadata *config.StructuredMetadata, cfg *config.Generator) client.Object {
+	return createGenerator(DeploymentName, scenarioCfg, metadata, cfg, false)
+}
+
+// CreateTenantGenerators returns one generator deployment per tenant. The
+// deployment names share the DeploymentName prefix, so that measurements
+// selecting the generator pods cover all tenants. Tenant generators always
+// run loki-loadgen, whose client metrics are labeled with the tenant.
+func CreateTenantGenerators(
+	tenants []*config.Tenant,
+	metadata *config.StructuredMetadata,
+	cfg *config.Generator,
+) []client.Object {
+	var dpls []client.Object
+	for _, tenant := range tenants {
+		tenantCfg := *cfg
+		tenantCfg.Tenant = tenant.Name
+
+		if tenant.PushURL != "" {
+			tenantCfg.PushURL = tenant.PushURL
+		}
+		if tenant.TokenSecret != "" {
+			tenantCfg.TokenSecret = tenant.TokenSecret
+		}
+
+		writer := &config.Writer{
+			Replicas: tenant.Replicas,
+			Args:     tenant.Args,
+			Replay:   tenant.Replay,
+		}
+
+		name := fmt.Sprintf("%s-%s", DeploymentName, strings.ToLower(tenant.Name))
+		dpls = append(dpls, createGenerator(name, writer, metadata, &tenantCfg, true))
+	}
+
+	return dpls
+}
+
+func createGenerator(
+	name string,
+	scenarioCfg *config.Writer,
+	metadata *config.StructuredMetadata,
+	cfg *config.Generator,
+	useLoadGen bool,
+) *appsv1.Deployment {
+	image := cfg.Image
 	args := []string{
 		"--command=generate",
 		"--destination=loki",
@@ -28,11 +93,220 @@ func CreateGenerator(scenarioCfg *config.Writer, cfg *config.Generator) client.O
 		args = append(args, fmt.Sprintf("--%s=%s", k, v))
 	}
 
-	return NewLoadClientDeployment(cfg.Namespace, cfg.Image, cfg.ServiceAccount, args, scenarioCfg.Replicas)
+	if metadata != nil && len(metadata.Fields) > 0 {
+		useLoadGen = true
+		args = append(args, structuredMetadataArgs(metadata)...)
+	}
+
+	if scenarioCfg.Replay != nil {
+		useLoadGen = true
+		args = append(args, replayArgs(scenarioCfg.Replay)...)
+	}
+
+	if scenarioCfg.Audit {
+		useLoadGen = true
+		args = append(args, "--audit=true")
+	}
+
+	// Only the loki-loadgen binary can read a bearer token from a secret.
+	if cfg.TokenSecret != "" {
+		useLoadGen = true
+		args = append(args, fmt.Sprintf("--%s=%s", "bearer-token-file", path.Join(tokenMountPath, tokenSecretKey)))
+	} else if useLoadGen && cfg.ServiceAccount != "" {
+		args = append(args, fmt.Sprintf("--%s=%s", "bearer-token-file", serviceAccountTokenFile))
+	}
+
+	if useLoadGen {
+		image = loadGenImage(cfg)
+	}
+
+	dpl := NewLoadClientDeployment(name, cfg.Namespace, image, cfg.ServiceAccount, args, scenarioCfg.Replicas)
+
+	if useLoadGen {
+		ExposeMetrics(&dpl.Spec.Template)
+	}
+	if scenarioCfg.Replay != nil {
+		mountCorpus(&dpl.Spec.Template.Spec, scenarioCfg.Replay)
+	}
+	if cfg.TokenSecret != "" {
+		mountTokenSecret(&dpl.Spec.Template.Spec, cfg.TokenSecret)
+	}
+
+	return dpl
+}
+
+// CreateBackfill returns a job pushing the backfill volume spread over the
+// given range. Every replica pushes its share to its own stream, labeled with
+// the host of the pod.
+func CreateBackfill(
+	backfill *config.Backfill,
+	window time.Duration,
+	metadata *config.StructuredMetadata,
+	cfg *config.Generator,
+) client.Object {
+	args := []string{
+		"--command=generate",
+		"--destination=loki",
+		fmt.Sprintf("--%s=%s", "url", cfg.PushURL),
+		fmt.Sprintf("--%s=%s", "tenant", cfg.Tenant),
+		fmt.Sprintf("--%s=%s", "backfill-range", window),
+		fmt.Sprintf("--%s=%d", "backfill-bytes", backfill.TotalBytes()/int64(backfill.Replicas)),
+	}
+
+	// Replicas pushing to the same stream reject each other's lines as out
+	// of order, so the host label of the pod always keeps them apart.
+	labelType := "client-host"
+	if backfill.Args["label-type"] == "host" {
+		labelType = "host"
+	}
+	args = append(args, fmt.Sprintf("--%s=%s", "label-type", labelType))
+
+	for k, v := range backfill.Args {
+		if k == "label-type" {
+			continue
+		}
+		args = append(args, fmt.Sprintf("--%s=%s", k, v))
+	}
+
+	if metadata != nil {
+		args = append(args, structuredMetadataArgs(metadata)...)
+	}
+
+	if cfg.TokenSecret != "" {
+		args = append(args, fmt.Sprintf("--%s=%s", "bearer-token-file", path.Join(tokenMountPath, tokenSecretKey)))
+	} else if cfg.ServiceAccount != "" {
+		args = append(args, fmt.Sprintf("--%s=%s", "bearer-token-file", serviceAccountTokenFile))
+	}
+
+	job := NewLoadClientJob(BackfillName, cfg.Namespace, loadGenImage(cfg), cfg.ServiceAccount, args, backfill.Replicas)
+
+	if cfg.TokenSecret != "" {
+		mountTokenSecret(&job.Spec.Template.Spec, cfg.TokenSecret)
+	}
+
+	return job
+}
+
+// CreateDatasetGenerator returns a single replica deployment pushing the
+// deterministic dataset of the verification. A second replica would push the
+// same lines again.
+func CreateDatasetGenerator(verification *config.Verification, cfg *config.Generator) client.Object {
+	args := []string{
+		fmt.Sprintf("--%s=%s", "url", cfg.PushURL),
+		fmt.Sprintf("--%s=%s", "tenant", cfg.Tenant),
+		fmt.Sprintf("--%s=%d", "dataset-seed", verification.Seed),
+		fmt.Sprintf("--%s=%d", "dataset-streams", verification.Streams),
+	}
+
+	if verification.LinesPerSecond > 0 {
+		args = append(args, fmt.Sprintf("--%s=%d", "dataset-lines-per-second", verification.LinesPerSecond))
+	}
+
+	if cfg.TokenSecret != "" {
+		args = append(args, fmt.Sprintf("--%s=%s", "bearer-token-file", path.Join(tokenMountPath, tokenSecretKey)))
+	} else if cfg.ServiceAccount != "" {
+		args = append(args, fmt.Sprintf("--%s=%s", "bearer-token-file", serviceAccountTokenFile))
+	}
+
+	dpl := NewLoadClientDeployment(DatasetName, cfg.Namespace, loadGenImage(cfg), cfg.ServiceAccount, args, 1)
+	ExposeMetrics(&dpl.Spec.Template)
+
+	if cfg.TokenSecret != "" {
+		mountTokenSecret(&dpl.Spec.Template.Spec, cfg.TokenSecret)
+	}
+
+	return dpl
+}
+
+// loadGenImage returns the loki-loadgen image of the generator config,
+// falling back to LoadGenImage.
+func loadGenImage(cfg *config.Generator) string {
+	if cfg.LoadGenImage != "" {
+		return cfg.LoadGenImage
+	}
+
+	return LoadGenImage
+}
+
+func structuredMetadataArgs(metadata *config.StructuredMetadata) []string {
+	var args []string
+	for _, field := range metadata.Fields {
+		args = append(args, fmt.Sprintf("--%s=%s:%d:%d", "structured-metadata", field.Name, field.Cardinality, field.Size))
+	}
+
+	if metadata.Inline {
+		args = append(args, "--structured-metadata-inline=true")
+	}
+
+	return args
+}
+
+func replayArgs(replay *config.Replay) []string {
+	format := replay.Format
+	if format == "" {
+		format = config.ReplayFormatJSONL
+	}
+
+	args := []string{
+		fmt.Sprintf("--%s=%s", "replay-file", path.Join(corpusMountPath, replay.File)),
+		fmt.Sprintf("--%s=%s", "replay-format", format),
+	}
+
+	if replay.LogsPerSecond > 0 {
+		args = append(args, fmt.Sprintf("--%s=%d", "replay-logs-per-second", replay.LogsPerSecond))
+	}
+	if replay.SpeedUp > 0 {
+		args = append(args, fmt.Sprintf("--%s=%g", "replay-speed-up", replay.SpeedUp))
+	}
+
+	return args
+}
+
+// mountCorpus mounts the ConfigMap or PersistentVolumeClaim holding the
+// replayed log corpus read-only into the load client container.
+func mountCorpus(spec *corev1.PodSpec, replay *config.Replay) {
+	volume := corev1.Volume{Name: corpusVolumeName}
+
+	switch {
+	case replay.ConfigMap != "":
+		volume.ConfigMap = &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: replay.ConfigMap},
+		}
+	case replay.PersistentVolumeClaim != "":
+		volume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{
+			ClaimName: replay.PersistentVolumeClaim,
+			ReadOnly:  true,
+		}
+	default:
+		return
+	}
+
+	spec.Volumes = append(spec.Volumes, volume)
+	spec.Containers[0].VolumeMounts = append(spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      corpusVolumeName,
+		MountPath: corpusMountPath,
+		ReadOnly:  true,
+	})
+}
+
+// mountTokenSecret mounts the secret holding the bearer token of the tenant
+// into the load client container.
+func mountTokenSecret(spec *corev1.PodSpec, secret string) {
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: tokenVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: secret},
+		},
+	})
+	spec.Containers[0].VolumeMounts = append(spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      tokenVolumeName,
+		MountPath: tokenMountPath,
+		ReadOnly:  true,
+	})
 }
 
 func NewLoadClientDeployment(
-	namespace, image, serviceAccount string,
+	name, namespace, image, serviceAccount string,
 	args []string,
 	replicas int32,
 ) *appsv1.Deployment {
@@ -51,12 +325,13 @@ func NewLoadClientDeployment(
 	}
 
 	labels := map[string]string{
-		"app": "loki-benchmarks-generator",
+		"app":       "loki-benchmarks-generator",
+		"generator": name,
 	}
 
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      DeploymentName,
+			Name:      name,
 			Namespace: namespace,
 			Labels:    labels,
 		},
@@ -74,3 +349,49 @@ func NewLoadClientDeployment(
 		},
 	}
 }
+
+func NewLoadClientJob(
+	name, namespace, image, serviceAccount string,
+	args []string,
+	replicas int32,
+) *batchv1.Job {
+	spec := corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Name:  "loadclient",
+				Image: image,
+				Args:  args,
+			},
+		},
+		// Restarted pods would push their stream from the start again and be
+		// rejected as out of order.
+		RestartPolicy: corev1.RestartPolicyNever,
+	}
+
+	if serviceAccount != "" {
+		spec.ServiceAccountName = serviceAccount
+	}
+
+	labels := map[string]string{
+		"app": "loki-benchmarks-backfill",
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism:  pointer.Int32(replicas),
+			Completions:  pointer.Int32(replicas),
+			BackoffLimit: pointer.Int32(0),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: spec,
+			},
+		},
+	}
+}
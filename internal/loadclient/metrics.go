@@ -0,0 +1,68 @@
+package loadclient
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	PodMonitorName = "loki-benchmarks-loadgen"
+
+	// MetricsLabel marks the pods running the loki-loadgen binary, which
+	// serves the client side request metrics.
+	MetricsLabel = "loki-benchmarks/metrics"
+
+	metricsPortName = "metrics"
+	metricsPort     = 8080
+)
+
+// ExposeMetrics labels the pods of the template for the PodMonitor and
+// declares the metrics port of the load client container.
+func ExposeMetrics(template *corev1.PodTemplateSpec) {
+	// Copy the labels as they may be shared with the deployment selector.
+	labels := map[string]string{MetricsLabel: "true"}
+	for k, v := range template.Labels {
+		labels[k] = v
+	}
+	template.Labels = labels
+
+	template.Spec.Containers[0].Ports = append(template.Spec.Containers[0].Ports, corev1.ContainerPort{
+		Name:          metricsPortName,
+		ContainerPort: metricsPort,
+		Protocol:      corev1.ProtocolTCP,
+	})
+}
+
+// NewPodMonitor returns a PodMonitor scraping the client side metrics of
+// all loki-loadgen pods. It is built as an unstructured object to avoid a
+// dependency on the prometheus-operator API.
+func NewPodMonitor(namespace string) *unstructured.Unstructured {
+	pm := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"selector": map[string]interface{}{
+					"matchLabels": map[string]interface{}{
+						MetricsLabel: "true",
+					},
+				},
+				"podMetricsEndpoints": []interface{}{
+					map[string]interface{}{
+						"port":     metricsPortName,
+						"interval": "15s",
+					},
+				},
+			},
+		},
+	}
+
+	pm.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "monitoring.coreos.com",
+		Version: "v1",
+		Kind:    "PodMonitor",
+	})
+	pm.SetName(PodMonitorName)
+	pm.SetNamespace(namespace)
+
+	return pm
+}
@@ -0,0 +1,56 @@
+package loadclient
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ProbeName = "probe"
+
+	probeBin = "/usr/local/bin/loki-probe"
+)
+
+// CreateProbe returns a single replica deployment running loki-probe in the
+// generator namespace. It pushes with the generator tenant and credentials
+// and queries the pull URL of the queriers.
+func CreateProbe(probe *config.Probe, generator *config.Generator, querier *config.Querier) client.Object {
+	args := []string{
+		fmt.Sprintf("--%s=%s", "push-url", generator.PushURL),
+		fmt.Sprintf("--%s=%s", "query-url", querier.PullURL),
+		fmt.Sprintf("--%s=%s", "tenant", generator.Tenant),
+		fmt.Sprintf("--%s=%g", "rate", probe.Rate),
+	}
+
+	if probe.PollInterval > 0 {
+		args = append(args, fmt.Sprintf("--%s=%s", "poll-interval", probe.PollInterval))
+	}
+	if probe.Timeout > 0 {
+		args = append(args, fmt.Sprintf("--%s=%s", "timeout", probe.Timeout))
+	}
+	if probe.RequestTimeout > 0 {
+		args = append(args, fmt.Sprintf("--%s=%s", "request-timeout", probe.RequestTimeout))
+	}
+
+	if generator.TokenSecret != "" {
+		args = append(args, fmt.Sprintf("--%s=%s", "bearer-token-file", path.Join(tokenMountPath, tokenSecretKey)))
+	} else if generator.ServiceAccount != "" {
+		args = append(args, fmt.Sprintf("--%s=%s", "bearer-token-file", serviceAccountTokenFile))
+	}
+
+	dpl := NewLoadClientDeployment(ProbeName, generator.Namespace, loadGenImage(generator), generator.ServiceAccount, args, 1)
+	dpl.Spec.Template.Spec.Containers[0].Name = "probe"
+	dpl.Spec.Template.Spec.Containers[0].Command = []string{probeBin}
+
+	ExposeMetrics(&dpl.Spec.Template)
+
+	if generator.TokenSecret != "" {
+		mountTokenSecret(&dpl.Spec.Template.Spec, generator.TokenSecret)
+	}
+
+	return dpl
+}
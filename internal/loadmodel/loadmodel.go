@@ -0,0 +1,18 @@
+// Package loadmodel names the arrival models of the query load. It is shared
+// by the benchmark configuration and the loki-querygen binary, which must not
+// depend on the test libraries of the configuration.
+package loadmodel
+
+// A closed loop waits for a free worker before it sends the next query, an
+// open loop sends at the target rate regardless of the latency.
+const (
+	LoopClosed = "closed"
+	LoopOpen   = "open"
+)
+
+// The arrival spaces the queries of an open loop, either evenly or as a
+// Poisson process.
+const (
+	ArrivalConstant = "constant"
+	ArrivalPoisson  = "poisson"
+)
@@ -0,0 +1,339 @@
+package loki
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	PushPath        = "/loki/api/v1/push"
+	QueryPath       = "/loki/api/v1/query"
+	QueryRangePath  = "/loki/api/v1/query_range"
+	LabelsPath      = "/loki/api/v1/labels"
+	LabelValuesPath = "/loki/api/v1/label/%s/values"
+	SeriesPath      = "/loki/api/v1/series"
+	IndexStatsPath  = "/loki/api/v1/index/stats"
+	VolumePath      = "/loki/api/v1/index/volume"
+	VolumeRangePath = "/loki/api/v1/index/volume_range"
+	DeletePath      = "/loki/api/v1/delete"
+	RulesPath       = "/loki/api/v1/rules/%s"
+
+	// ConfigPath and RuntimeConfigPath are served by every Loki component,
+	// but usually not exposed by the gateways.
+	ConfigPath        = "/config"
+	RuntimeConfigPath = "/runtime_config"
+
+	tenantHeader = "X-Scope-OrgID"
+)
+
+// StatusError is returned for responses with a non 2xx status code.
+type StatusError struct {
+	Code    int
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.Code, e.Message)
+}
+
+// Retryable reports whether the request was rejected by a rate limit or a
+// server side error and can be sent again.
+func (e *StatusError) Retryable() bool {
+	return e.Code == http.StatusTooManyRequests || e.Code/100 == 5
+}
+
+type Client struct {
+	addr   string
+	tenant string
+	token  string
+	http   *http.Client
+}
+
+// NewClient returns a client for the Loki HTTP API served at addr. The
+// bearer token file is optional and only read once on creation.
+func NewClient(addr, tenant, bearerTokenFile string, timeout time.Duration) (*Client, error) {
+	var token string
+	if bearerTokenFile != "" {
+		b, err := os.ReadFile(bearerTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading bearer token file: %w", err)
+		}
+		token = strings.TrimSpace(string(b))
+	}
+
+	return &Client{
+		addr:   strings.TrimSuffix(addr, "/"),
+		tenant: tenant,
+		token:  token,
+		http: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: true, //nolint:gosec
+				},
+			},
+		},
+	}, nil
+}
+
+func (c *Client) Push(ctx context.Context, req *PushRequest) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed encoding push request: %w", err)
+	}
+
+	res, err := c.do(ctx, http.MethodPost, PushPath, nil, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		return responseError(res)
+	}
+
+	return nil
+}
+
+func (c *Client) QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration, limit int) (*QueryResponse, error) {
+	params := rangeParams(query, start, end)
+	if step > 0 {
+		params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+	}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	return c.query(ctx, QueryRangePath, params)
+}
+
+func (c *Client) Query(ctx context.Context, query string, ts time.Time, limit int) (*QueryResponse, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("time", strconv.FormatInt(ts.UnixNano(), 10))
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	return c.query(ctx, QueryPath, params)
+}
+
+// Labels returns the label names seen between start and end, optionally
+// restricted to the streams matching the selector query.
+func (c *Client) Labels(ctx context.Context, query string, start, end time.Time) ([]string, error) {
+	out := &LabelResponse{}
+	if err := c.get(ctx, LabelsPath, rangeParams(query, start, end), out); err != nil {
+		return nil, err
+	}
+
+	return out.Data, nil
+}
+
+// LabelValues returns the values of the label seen between start and end,
+// optionally restricted to the streams matching the selector query.
+func (c *Client) LabelValues(ctx context.Context, name, query string, start, end time.Time) ([]string, error) {
+	out := &LabelResponse{}
+	if err := c.get(ctx, fmt.Sprintf(LabelValuesPath, url.PathEscape(name)), rangeParams(query, start, end), out); err != nil {
+		return nil, err
+	}
+
+	return out.Data, nil
+}
+
+// Series returns the label sets of the streams matching the selector.
+func (c *Client) Series(ctx context.Context, match string, start, end time.Time) ([]map[string]string, error) {
+	params := rangeParams("", start, end)
+	params.Set("match[]", match)
+
+	out := &SeriesResponse{}
+	if err := c.get(ctx, SeriesPath, params, out); err != nil {
+		return nil, err
+	}
+
+	return out.Data, nil
+}
+
+// IndexStats returns the number of streams, chunks, entries and bytes the
+// index holds for the selector.
+func (c *Client) IndexStats(ctx context.Context, query string, start, end time.Time) (*IndexStatsResponse, error) {
+	out := &IndexStatsResponse{}
+	if err := c.get(ctx, IndexStatsPath, rangeParams(query, start, end), out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// Volume returns the bytes ingested for the selector as a vector.
+func (c *Client) Volume(ctx context.Context, query string, start, end time.Time, limit int) (*QueryResponse, error) {
+	params := rangeParams(query, start, end)
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	return c.query(ctx, VolumePath, params)
+}
+
+// VolumeRange returns the bytes ingested for the selector as a matrix.
+func (c *Client) VolumeRange(ctx context.Context, query string, start, end time.Time, step time.Duration, limit int) (*QueryResponse, error) {
+	params := rangeParams(query, start, end)
+	if step > 0 {
+		params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+	}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	return c.query(ctx, VolumeRangePath, params)
+}
+
+// Delete submits a request deleting the log lines matching the query between
+// start and end. Loki only accepts second precision for the range.
+func (c *Client) Delete(ctx context.Context, query string, start, end time.Time) error {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(end.Unix(), 10))
+
+	res, err := c.do(ctx, http.MethodPost, DeletePath+"?"+params.Encode(), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		return responseError(res)
+	}
+
+	return nil
+}
+
+// DeleteRequests returns the delete requests of the tenant.
+func (c *Client) DeleteRequests(ctx context.Context) ([]DeleteRequest, error) {
+	var out []DeleteRequest
+	if err := c.get(ctx, DeletePath, url.Values{}, &out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// SetRuleGroup creates or replaces the rule group in the ruler namespace. The
+// group is a single group of a rule file in YAML.
+func (c *Client) SetRuleGroup(ctx context.Context, namespace string, group []byte) error {
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/yaml")
+
+	res, err := c.do(ctx, http.MethodPost, fmt.Sprintf(RulesPath, url.PathEscape(namespace)), headers, bytes.NewReader(group))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		return responseError(res)
+	}
+
+	return nil
+}
+
+// DeleteRuleNamespace deletes all rule groups of the ruler namespace.
+func (c *Client) DeleteRuleNamespace(ctx context.Context, namespace string) error {
+	res, err := c.do(ctx, http.MethodDelete, fmt.Sprintf(RulesPath, url.PathEscape(namespace)), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 && res.StatusCode != http.StatusNotFound {
+		return responseError(res)
+	}
+
+	return nil
+}
+
+func (c *Client) query(ctx context.Context, path string, params url.Values) (*QueryResponse, error) {
+	out := &QueryResponse{}
+	if err := c.get(ctx, path, params, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	res, err := c.do(ctx, http.MethodGet, path+"?"+params.Encode(), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		return responseError(res)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed decoding %s response: %w", route(path), err)
+	}
+
+	return nil
+}
+
+func rangeParams(query string, start, end time.Time) url.Values {
+	params := url.Values{}
+	if query != "" {
+		params.Set("query", query)
+	}
+	params.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+
+	return params
+}
+
+func (c *Client) do(ctx context.Context, method, path string, headers http.Header, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.addr+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating request: %w", err)
+	}
+
+	for k, v := range headers {
+		req.Header[k] = v
+	}
+
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.tenant != "" {
+		req.Header.Set(tenantHeader, c.tenant)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	setQueryHeaders(ctx, req.Header)
+
+	start := time.Now()
+	res, err := c.http.Do(req)
+	if err != nil {
+		observe(c.tenant, path, 0, start)
+		return nil, fmt.Errorf("failed executing %s %s: %w", method, path, err)
+	}
+
+	observe(c.tenant, path, res.StatusCode, start)
+	return res, nil
+}
+
+func responseError(res *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return &StatusError{Code: res.StatusCode, Message: strings.TrimSpace(string(msg))}
+}
@@ -0,0 +1,266 @@
+package loki
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatusErrorRetryable(t *testing.T) {
+	tests := []struct {
+		code int
+		want bool
+	}{
+		{code: http.StatusBadRequest, want: false},
+		{code: http.StatusNotFound, want: false},
+		{code: http.StatusTooManyRequests, want: true},
+		{code: http.StatusInternalServerError, want: true},
+		{code: http.StatusServiceUnavailable, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.code), func(t *testing.T) {
+			err := &StatusError{Code: tt.code}
+			if got := err.Retryable(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientHeaders(t *testing.T) {
+	token := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(token, []byte("secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		token     string
+		want      map[string]string
+		wantUnset []string
+	}{
+		{
+			name:      "without token",
+			ctx:       context.Background(),
+			want:      map[string]string{"X-Scope-OrgID": "tenant", "Content-Type": "application/json"},
+			wantUnset: []string{"Authorization"},
+		},
+		{
+			name:  "with bearer token",
+			ctx:   context.Background(),
+			token: token,
+			want:  map[string]string{"Authorization": "Bearer secret"},
+		},
+		{
+			name:      "without query name",
+			ctx:       context.Background(),
+			want:      map[string]string{"User-Agent": "loki-benchmarks"},
+			wantUnset: []string{"X-Query-Tags"},
+		},
+		{
+			name: "with query name",
+			ctx:  WithQueryName(context.Background(), "errors by pod"),
+			want: map[string]string{"X-Query-Tags": "Source=errors_by_pod", "User-Agent": "loki-benchmarks/errors_by_pod"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got http.Header
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Clone()
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer srv.Close()
+
+			c, err := NewClient(srv.URL+"/", "tenant", tt.token, time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := c.Push(tt.ctx, &PushRequest{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for k, v := range tt.want {
+				if got.Get(k) != v {
+					t.Errorf("got header %s %q, want %q", k, got.Get(k), v)
+				}
+			}
+			for _, k := range tt.wantUnset {
+				if got.Get(k) != "" {
+					t.Errorf("got unexpected header %s %q", k, got.Get(k))
+				}
+			}
+		})
+	}
+}
+
+func TestClientPush(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != PushPath {
+			t.Errorf("got %s %s", r.Method, r.URL.Path)
+		}
+
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tenant", "", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := &PushRequest{Streams: []Stream{{
+		Labels:  map[string]string{"job": "a"},
+		Entries: []Entry{{Timestamp: time.Unix(1, 0), Line: "msg"}},
+	}}}
+	if err := c.Push(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := `{"streams":[{"stream":{"job":"a"},"values":[["1000000000","msg"]]}]}`; body != want {
+		t.Errorf("got body %s, want %s", body, want)
+	}
+}
+
+func TestClientRequests(t *testing.T) {
+	start := time.Unix(1000, 0)
+	end := time.Unix(2000, 0)
+
+	tests := []struct {
+		name   string
+		call   func(c *Client) error
+		method string
+		path   string
+		params map[string]string
+		body   string
+	}{
+		{
+			name: "range query",
+			call: func(c *Client) error {
+				_, err := c.QueryRange(context.Background(), `{job="a"}`, start, end, 30*time.Second, 100)
+				return err
+			},
+			method: http.MethodGet,
+			path:   QueryRangePath,
+			params: map[string]string{"query": `{job="a"}`, "start": "1000000000000", "end": "2000000000000", "step": "30", "limit": "100"},
+		},
+		{
+			name: "instant query without limit",
+			call: func(c *Client) error {
+				_, err := c.Query(context.Background(), `count_over_time({job="a"}[1m])`, end, 0)
+				return err
+			},
+			method: http.MethodGet,
+			path:   QueryPath,
+			params: map[string]string{"time": "2000000000000", "limit": ""},
+		},
+		{
+			name: "label values",
+			call: func(c *Client) error {
+				_, err := c.LabelValues(context.Background(), "pod", `{job="a"}`, start, end)
+				return err
+			},
+			method: http.MethodGet,
+			path:   "/loki/api/v1/label/pod/values",
+			params: map[string]string{"query": `{job="a"}`},
+			body:   `{"status":"success","data":["a"]}`,
+		},
+		{
+			name: "series",
+			call: func(c *Client) error {
+				_, err := c.Series(context.Background(), `{job="a"}`, start, end)
+				return err
+			},
+			method: http.MethodGet,
+			path:   SeriesPath,
+			params: map[string]string{"match[]": `{job="a"}`, "query": ""},
+			body:   `{"status":"success","data":[{"job":"a"}]}`,
+		},
+		{
+			name: "delete in seconds",
+			call: func(c *Client) error {
+				return c.Delete(context.Background(), `{job="a"}`, start, end)
+			},
+			method: http.MethodPost,
+			path:   DeletePath,
+			params: map[string]string{"start": "1000", "end": "2000"},
+		},
+		{
+			name: "delete rule namespace",
+			call: func(c *Client) error {
+				return c.DeleteRuleNamespace(context.Background(), "benchmarks")
+			},
+			method: http.MethodDelete,
+			path:   "/loki/api/v1/rules/benchmarks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Clone(context.Background())
+				body := tt.body
+				if body == "" {
+					body = `{"status":"success","data":{"resultType":"vector","result":[]}}`
+				}
+				_, _ = w.Write([]byte(body))
+			}))
+			defer srv.Close()
+
+			c, err := NewClient(srv.URL, "tenant", "", time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tt.call(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got.Method != tt.method || got.URL.Path != tt.path {
+				t.Errorf("got %s %s, want %s %s", got.Method, got.URL.Path, tt.method, tt.path)
+			}
+			for k, v := range tt.params {
+				if p := got.URL.Query().Get(k); p != v {
+					t.Errorf("got param %s %q, want %q", k, p, v)
+				}
+			}
+		})
+	}
+}
+
+func TestClientStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "too many outstanding requests", http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "tenant", "", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Push(context.Background(), &PushRequest{})
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("got error %v, want a status error", err)
+	}
+	if statusErr.Code != http.StatusTooManyRequests || !strings.Contains(statusErr.Message, "too many outstanding requests") {
+		t.Errorf("got status error %+v", statusErr)
+	}
+}
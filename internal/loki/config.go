@@ -0,0 +1,53 @@
+package loki
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Retention is the retention the compactor applies to the lines of a
+// tenant.
+type Retention struct {
+	Enabled bool
+	Period  time.Duration
+}
+
+type retentionConfig struct {
+	Compactor struct {
+		RetentionEnabled bool `yaml:"retention_enabled"`
+	} `yaml:"compactor"`
+	LimitsConfig struct {
+		RetentionPeriod model.Duration `yaml:"retention_period"`
+	} `yaml:"limits_config"`
+}
+
+type runtimeRetentionConfig struct {
+	Overrides map[string]struct {
+		RetentionPeriod *model.Duration `yaml:"retention_period"`
+	} `yaml:"overrides"`
+}
+
+// ParseRetention returns the retention of the tenant from the responses of
+// the config and runtime config endpoints. A retention_period override of
+// the tenant takes precedence over the one of the limits_config.
+func ParseRetention(config, runtimeConfig []byte, tenant string) (*Retention, error) {
+	cfg := &retentionConfig{}
+	if err := yaml.Unmarshal(config, cfg); err != nil {
+		return nil, fmt.Errorf("failed decoding config: %w", err)
+	}
+
+	runtimeCfg := &runtimeRetentionConfig{}
+	if err := yaml.Unmarshal(runtimeConfig, runtimeCfg); err != nil {
+		return nil, fmt.Errorf("failed decoding runtime config: %w", err)
+	}
+
+	period := cfg.LimitsConfig.RetentionPeriod
+	if override := runtimeCfg.Overrides[tenant].RetentionPeriod; override != nil {
+		period = *override
+	}
+
+	return &Retention{Enabled: cfg.Compactor.RetentionEnabled, Period: time.Duration(period)}, nil
+}
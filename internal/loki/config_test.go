@@ -0,0 +1,82 @@
+package loki
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testConfig = `
+compactor:
+  working_directory: /tmp/loki/compactor
+  retention_enabled: true
+limits_config:
+  ingestion_rate_mb: 4
+  retention_period: 1w
+`
+
+func TestParseRetention(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        string
+		runtimeConfig string
+		want          *Retention
+		err           string
+	}{
+		{
+			name:          "limits config",
+			config:        testConfig,
+			runtimeConfig: "overrides: {}\n",
+			want:          &Retention{Enabled: true, Period: 7 * 24 * time.Hour},
+		},
+		{
+			name:          "tenant override",
+			config:        testConfig,
+			runtimeConfig: "overrides:\n  tenant:\n    retention_period: 24h\n  other:\n    retention_period: 1h\n",
+			want:          &Retention{Enabled: true, Period: 24 * time.Hour},
+		},
+		{
+			name:          "override of another limit",
+			config:        testConfig,
+			runtimeConfig: "overrides:\n  tenant:\n    ingestion_rate_mb: 8\n",
+			want:          &Retention{Enabled: true, Period: 7 * 24 * time.Hour},
+		},
+		{
+			name:          "retention disabled",
+			config:        "compactor:\n  retention_enabled: false\nlimits_config:\n  retention_period: 0s\n",
+			runtimeConfig: "",
+			want:          &Retention{},
+		},
+		{
+			name:          "invalid config",
+			config:        "compactor: [",
+			runtimeConfig: "",
+			err:           "failed decoding config",
+		},
+		{
+			name:          "invalid runtime config",
+			config:        testConfig,
+			runtimeConfig: "overrides:\n  tenant:\n    retention_period: forever\n",
+			err:           "failed decoding runtime config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRetention([]byte(tt.config), []byte(tt.runtimeConfig), "tenant")
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package loki
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RequestDuration records the client side latency of every request. The
+// route label uses the same naming as the route label of Loki's own
+// loki_request_duration_seconds metric, e.g. loki_api_v1_push.
+var RequestDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: "loki_benchmarks",
+		Name:      "client_request_duration_seconds",
+		Help:      "Time spent executing requests against the Loki API.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 15),
+	},
+	[]string{"tenant", "route", "status_code"},
+)
+
+func Register(reg prometheus.Registerer) error {
+	return reg.Register(RequestDuration)
+}
+
+func observe(tenant, path string, code int, start time.Time) {
+	status := "error"
+	if code > 0 {
+		status = strconv.Itoa(code)
+	}
+
+	RequestDuration.WithLabelValues(tenant, route(path), status).Observe(time.Since(start).Seconds())
+}
+
+// route converts a request path into Loki's route naming, e.g.
+// /loki/api/v1/query_range becomes loki_api_v1_query_range. Label names in
+// the path are replaced by "name" and rule namespaces by "namespace" to keep
+// the cardinality bounded.
+func route(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 1; i < len(parts)-1; i++ {
+		if parts[i-1] == "label" && parts[i+1] == "values" {
+			parts[i] = "name"
+		}
+	}
+	if n := len(parts); n > 1 && parts[n-2] == "rules" {
+		parts[n-1] = "namespace"
+	}
+
+	return strings.Join(parts, "_")
+}
@@ -0,0 +1,214 @@
+package loki
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryLog is the query statistics line the query frontend logs for every
+// query it answered. Source is the Source tag of the X-Query-Tags header,
+// i.e. the name of the query set by WithQueryName.
+type QueryLog struct {
+	Source   string
+	Status   string
+	Duration time.Duration
+	Bytes    float64
+}
+
+// QueryLogSummary aggregates the query statistics lines of a source.
+type QueryLogSummary struct {
+	Queries   int
+	Errors    int
+	Bytes     float64
+	durations []time.Duration
+}
+
+// byteUnits are the units of the humanized total_bytes field.
+var byteUnits = map[string]float64{
+	"B":  1,
+	"kB": 1e3,
+	"MB": 1e6,
+	"GB": 1e9,
+	"TB": 1e12,
+	"PB": 1e15,
+}
+
+// ParseQueryLog parses a query statistics line of the query frontend. It
+// returns false for other lines and for queries without a source.
+func ParseQueryLog(line string) (QueryLog, bool) {
+	fields := logfmt(line)
+	if fields["component"] != "frontend" || fields["source"] == "" {
+		return QueryLog{}, false
+	}
+
+	duration, err := time.ParseDuration(fields["duration"])
+	if err != nil || fields["status"] == "" {
+		return QueryLog{}, false
+	}
+
+	// Label and series requests are logged without the bytes processed.
+	bytes, _ := parseBytes(fields["total_bytes"])
+
+	return QueryLog{
+		Source:   fields["source"],
+		Status:   fields["status"],
+		Duration: duration,
+		Bytes:    bytes,
+	}, true
+}
+
+// SummarizeQueryLogs reads the query statistics lines of r and aggregates
+// them by source.
+func SummarizeQueryLogs(r io.Reader) (map[string]*QueryLogSummary, error) {
+	summaries := map[string]*QueryLogSummary{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		entry, ok := ParseQueryLog(scanner.Text())
+		if !ok {
+			continue
+		}
+
+		s, ok := summaries[entry.Source]
+		if !ok {
+			s = &QueryLogSummary{}
+			summaries[entry.Source] = s
+		}
+		s.add(entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading query statistics: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// Merge adds the queries of other to the summary, e.g. of another query
+// frontend pod.
+func (s *QueryLogSummary) Merge(other *QueryLogSummary) {
+	s.Queries += other.Queries
+	s.Errors += other.Errors
+	s.Bytes += other.Bytes
+	s.durations = append(s.durations, other.durations...)
+}
+
+// DurationQuantile returns the q-quantile of the query durations, or zero
+// without queries.
+func (s *QueryLogSummary) DurationQuantile(q float64) time.Duration {
+	if len(s.durations) == 0 {
+		return 0
+	}
+
+	sorted := append([]time.Duration(nil), s.durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	i := int(q*float64(len(sorted))+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+
+	return sorted[i]
+}
+
+// BytesAverage returns the average bytes processed per query, or zero
+// without queries.
+func (s *QueryLogSummary) BytesAverage() float64 {
+	if s.Queries == 0 {
+		return 0
+	}
+
+	return s.Bytes / float64(s.Queries)
+}
+
+func (s *QueryLogSummary) add(stats QueryLog) {
+	s.Queries++
+	if stats.Status != "200" {
+		s.Errors++
+	}
+	s.Bytes += stats.Bytes
+	s.durations = append(s.durations, stats.Duration)
+}
+
+// parseBytes parses a humanized size like "1.5 MB".
+func parseBytes(s string) (float64, error) {
+	value, unit, ok := strings.Cut(strings.TrimSpace(s), " ")
+	if !ok {
+		return 0, fmt.Errorf("invalid size: %q", s)
+	}
+
+	multiplier, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit: %q", s)
+	}
+
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size: %q", s)
+	}
+
+	return v * multiplier, nil
+}
+
+// logfmt splits a logfmt line into its keys and unquoted values. Keys
+// without a value are dropped.
+func logfmt(line string) map[string]string {
+	fields := map[string]string{}
+
+	for line != "" {
+		line = strings.TrimLeft(line, " ")
+
+		eq := strings.IndexAny(line, "= ")
+		if eq < 0 {
+			break
+		}
+		if line[eq] == ' ' {
+			line = line[eq:]
+			continue
+		}
+
+		key := line[:eq]
+		line = line[eq+1:]
+
+		var value string
+		if strings.HasPrefix(line, `"`) {
+			end := 1
+			for ; end < len(line); end++ {
+				if line[end] == '\\' {
+					end++
+					continue
+				}
+				if line[end] == '"' {
+					break
+				}
+			}
+			if end >= len(line) {
+				end = len(line) - 1
+			}
+
+			unquoted, err := strconv.Unquote(line[:end+1])
+			if err != nil {
+				unquoted = strings.Trim(line[:end+1], `"`)
+			}
+			value, line = unquoted, line[end+1:]
+		} else {
+			end := strings.IndexByte(line, ' ')
+			if end < 0 {
+				end = len(line)
+			}
+			value, line = line[:end], line[end:]
+		}
+
+		fields[key] = value
+	}
+
+	return fields
+}
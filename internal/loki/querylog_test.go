@@ -0,0 +1,96 @@
+package loki
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testStatsLine = `level=info ts=2024-01-01T00:00:00Z caller=metrics.go:159 component=frontend org_id=test-oidc latency=fast query="sum by (level) (rate({client=\"promtail\"} [1m]))" query_hash=1 query_type=metric range_type=range length=1h0m0s step=14s duration=250ms status=200 limit=100 returned_lines=0 throughput="4.0 MB" total_bytes="1.5 MB" source=sumRateByLevel`
+	testLabelLine = `level=info ts=2024-01-01T00:00:00Z caller=metrics.go:203 component=frontend org_id=test-oidc latency=fast query_type=labels length=1h0m0s duration=20ms status=500 label= source=labels`
+)
+
+func TestParseQueryLog(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want QueryLog
+		ok   bool
+	}{
+		{
+			name: "range query",
+			line: testStatsLine,
+			want: QueryLog{Source: "sumRateByLevel", Status: "200", Duration: 250 * time.Millisecond, Bytes: 1.5e6},
+			ok:   true,
+		},
+		{
+			name: "labels query",
+			line: testLabelLine,
+			want: QueryLog{Source: "labels", Status: "500", Duration: 20 * time.Millisecond},
+			ok:   true,
+		},
+		{
+			name: "querier line",
+			line: strings.Replace(testStatsLine, "component=frontend", "component=querier", 1),
+		},
+		{
+			name: "without source",
+			line: strings.Replace(testStatsLine, " source=sumRateByLevel", "", 1),
+		},
+		{
+			name: "other line",
+			line: `level=info ts=2024-01-01T00:00:00Z caller=roundtrip.go:300 component=frontend msg="executing query" source=sumRateByLevel`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseQueryLog(tt.line)
+			if ok != tt.ok {
+				t.Fatalf("got ok %v, want %v", ok, tt.ok)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeQueryLogs(t *testing.T) {
+	lines := []string{
+		testStatsLine,
+		strings.Replace(testStatsLine, "duration=250ms", "duration=1s", 1),
+		strings.Replace(testStatsLine, "status=200", "status=504", 1),
+		testLabelLine,
+		"not a logfmt line",
+	}
+
+	got, err := SummarizeQueryLogs(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got sources %v", got)
+	}
+
+	s := got["sumRateByLevel"]
+	switch {
+	case s.Queries != 3 || s.Errors != 1:
+		t.Errorf("got %d queries and %d errors", s.Queries, s.Errors)
+	case s.BytesAverage() != 1.5e6:
+		t.Errorf("got %v bytes on average", s.BytesAverage())
+	case s.DurationQuantile(0.5) != 250*time.Millisecond || s.DurationQuantile(0.99) != time.Second:
+		t.Errorf("got P50 %s and P99 %s", s.DurationQuantile(0.5), s.DurationQuantile(0.99))
+	}
+
+	s.Merge(got["labels"])
+	if s.Queries != 4 || s.Errors != 2 {
+		t.Errorf("got %d queries and %d errors after merging", s.Queries, s.Errors)
+	}
+
+	empty := &QueryLogSummary{}
+	if empty.DurationQuantile(0.99) != 0 || empty.BytesAverage() != 0 {
+		t.Errorf("got non-zero values without queries")
+	}
+}
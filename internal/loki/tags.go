@@ -0,0 +1,41 @@
+package loki
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+)
+
+const (
+	queryTagsHeader = "X-Query-Tags"
+	userAgent       = "loki-benchmarks"
+)
+
+type queryNameKey struct{}
+
+// invalidTagChars matches the characters Loki strips from query tags.
+var invalidTagChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// WithQueryName attributes the requests sent with the context to the named
+// query. The name is sent as X-Query-Tags source, which Loki adds to its
+// query statistics log lines, and as part of the User-Agent.
+func WithQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, queryNameKey{}, QueryTag(name))
+}
+
+// QueryTag returns the name of the query as Loki logs it in the source
+// field of its query statistics.
+func QueryTag(name string) string {
+	return invalidTagChars.ReplaceAllString(name, "_")
+}
+
+func setQueryHeaders(ctx context.Context, h http.Header) {
+	name, ok := ctx.Value(queryNameKey{}).(string)
+	if !ok || name == "" {
+		h.Set("User-Agent", userAgent)
+		return
+	}
+
+	h.Set(queryTagsHeader, "Source="+name)
+	h.Set("User-Agent", userAgent+"/"+name)
+}
@@ -0,0 +1,112 @@
+package loki
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const TailPath = "/loki/api/v1/tail"
+
+type TailResponse struct {
+	Streams        []StreamResult `json:"streams"`
+	DroppedEntries []struct {
+		Labels    string `json:"labels"`
+		Timestamp string `json:"timestamp"`
+	} `json:"dropped_entries"`
+}
+
+// Tail opens a websocket tail of the query and calls handle for every
+// response until the context is done or the connection fails. The
+// connected callback is called once the websocket handshake succeeded.
+func (c *Client) Tail(
+	ctx context.Context,
+	query string,
+	delayFor time.Duration,
+	limit int,
+	connected func(),
+	handle func(res *TailResponse, received time.Time),
+) error {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(time.Now().UnixNano(), 10))
+	if delayFor > 0 {
+		params.Set("delay_for", strconv.Itoa(int(delayFor.Seconds())))
+	}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	addr := c.addr
+	switch {
+	case strings.HasPrefix(addr, "https://"):
+		addr = "wss://" + strings.TrimPrefix(addr, "https://")
+	case strings.HasPrefix(addr, "http://"):
+		addr = "ws://" + strings.TrimPrefix(addr, "http://")
+	}
+
+	headers := http.Header{}
+	if c.tenant != "" {
+		headers.Set(tenantHeader, c.tenant)
+	}
+	if c.token != "" {
+		headers.Set("Authorization", "Bearer "+c.token)
+	}
+	setQueryHeaders(ctx, headers)
+
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: 30 * time.Second,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true, //nolint:gosec
+		},
+	}
+
+	start := time.Now()
+	conn, res, err := dialer.DialContext(ctx, addr+TailPath+"?"+params.Encode(), headers)
+	if err != nil {
+		if res != nil {
+			observe(c.tenant, TailPath, res.StatusCode, start)
+			defer res.Body.Close()
+			return responseError(res)
+		}
+		observe(c.tenant, TailPath, 0, start)
+		return fmt.Errorf("failed opening tail: %w", err)
+	}
+	defer conn.Close()
+
+	observe(c.tenant, TailPath, res.StatusCode, start)
+	if connected != nil {
+		connected()
+	}
+
+	// Unblock the read below once the context is done.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		out := &TailResponse{}
+		if err := conn.ReadJSON(out); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed reading tail: %w", err)
+		}
+
+		handle(out, time.Now())
+	}
+}
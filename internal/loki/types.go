@@ -0,0 +1,149 @@
+package loki
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type PushRequest struct {
+	Streams []Stream `json:"streams"`
+}
+
+type Stream struct {
+	Labels  map[string]string `json:"stream"`
+	Entries []Entry           `json:"values"`
+}
+
+// Entry is a single log line. It is encoded as the tuple expected by the
+// push API, with the structured metadata object omitted when empty.
+type Entry struct {
+	Timestamp          time.Time
+	Line               string
+	StructuredMetadata map[string]string
+}
+
+func (e Entry) MarshalJSON() ([]byte, error) {
+	ts := strconv.FormatInt(e.Timestamp.UnixNano(), 10)
+
+	if len(e.StructuredMetadata) == 0 {
+		return json.Marshal([]interface{}{ts, e.Line})
+	}
+
+	return json.Marshal([]interface{}{ts, e.Line, e.StructuredMetadata})
+}
+
+type QueryResponse struct {
+	Status string    `json:"status"`
+	Data   QueryData `json:"data"`
+}
+
+const (
+	ResultTypeStreams = "streams"
+	ResultTypeMatrix  = "matrix"
+	ResultTypeVector  = "vector"
+)
+
+// QueryData holds the query result undecoded, as its shape depends on the
+// result type, e.g. streams or matrix.
+type QueryData struct {
+	ResultType string          `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+	Stats      QueryStats      `json:"stats"`
+}
+
+type QueryStats struct {
+	Summary struct {
+		BytesProcessedPerSecond int64   `json:"bytesProcessedPerSecond"`
+		LinesProcessedPerSecond int64   `json:"linesProcessedPerSecond"`
+		TotalBytesProcessed     int64   `json:"totalBytesProcessed"`
+		TotalLinesProcessed     int64   `json:"totalLinesProcessed"`
+		ExecTime                float64 `json:"execTime"`
+	} `json:"summary"`
+}
+
+type LabelResponse struct {
+	Status string   `json:"status"`
+	Data   []string `json:"data"`
+}
+
+type SeriesResponse struct {
+	Status string              `json:"status"`
+	Data   []map[string]string `json:"data"`
+}
+
+type IndexStatsResponse struct {
+	Streams int64 `json:"streams"`
+	Chunks  int64 `json:"chunks"`
+	Entries int64 `json:"entries"`
+	Bytes   int64 `json:"bytes"`
+}
+
+const (
+	DeleteStatusReceived  = "received"
+	DeleteStatusProcessed = "processed"
+)
+
+// DeleteRequest is an entry of the delete requests list. The times are in
+// seconds since epoch.
+type DeleteRequest struct {
+	RequestID string  `json:"request_id"`
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+	Query     string  `json:"query"`
+	Status    string  `json:"status"`
+	CreatedAt float64 `json:"created_at"`
+}
+
+type StreamResult struct {
+	Labels map[string]string `json:"stream"`
+	Values [][]string        `json:"values"`
+}
+
+type MatrixResult struct {
+	Metric map[string]string `json:"metric"`
+	Values [][]interface{}   `json:"values"`
+}
+
+type VectorResult struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value"`
+}
+
+// Entries returns the number of log lines of a streams result or the number
+// of samples of a matrix or vector result.
+func (d *QueryData) Entries() (int, error) {
+	switch d.ResultType {
+	case ResultTypeStreams:
+		var streams []StreamResult
+		if err := json.Unmarshal(d.Result, &streams); err != nil {
+			return 0, err
+		}
+
+		n := 0
+		for _, s := range streams {
+			n += len(s.Values)
+		}
+		return n, nil
+	case ResultTypeMatrix:
+		var matrix []MatrixResult
+		if err := json.Unmarshal(d.Result, &matrix); err != nil {
+			return 0, err
+		}
+
+		n := 0
+		for _, m := range matrix {
+			n += len(m.Values)
+		}
+		return n, nil
+	case ResultTypeVector:
+		var vector []VectorResult
+		if err := json.Unmarshal(d.Result, &vector); err != nil {
+			return 0, err
+		}
+		return len(vector), nil
+	default:
+		return 0, fmt.Errorf("unsupported result type: %s", d.ResultType)
+	}
+}
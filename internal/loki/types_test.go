@@ -0,0 +1,95 @@
+package loki
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestEntryMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry Entry
+		want  string
+	}{
+		{
+			name:  "without structured metadata",
+			entry: Entry{Timestamp: time.Unix(1, 5), Line: "msg"},
+			want:  `["1000000005","msg"]`,
+		},
+		{
+			name:  "with structured metadata",
+			entry: Entry{Timestamp: time.Unix(1, 5), Line: "msg", StructuredMetadata: map[string]string{"trace_id": "abc"}},
+			want:  `["1000000005","msg",{"trace_id":"abc"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.entry)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryDataEntries(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want int
+		err  bool
+	}{
+		{
+			name: "streams",
+			data: `{"resultType":"streams","result":[{"stream":{"a":"1"},"values":[["1","x"],["2","y"]]},{"stream":{"a":"2"},"values":[["3","z"]]}]}`,
+			want: 3,
+		},
+		{
+			name: "matrix",
+			data: `{"resultType":"matrix","result":[{"metric":{"a":"1"},"values":[[1,"1"],[2,"2"]]}]}`,
+			want: 2,
+		},
+		{
+			name: "vector",
+			data: `{"resultType":"vector","result":[{"metric":{"a":"1"},"value":[1,"1"]},{"metric":{"a":"2"},"value":[1,"2"]}]}`,
+			want: 2,
+		},
+		{
+			name: "empty streams",
+			data: `{"resultType":"streams","result":[]}`,
+		},
+		{
+			name: "unsupported result type",
+			data: `{"resultType":"scalar","result":[1,"1"]}`,
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &QueryData{}
+			if err := json.Unmarshal([]byte(tt.data), d); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := d.Entries()
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %d entries, want %d", got, tt.want)
+			}
+		})
+	}
+}
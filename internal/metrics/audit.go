@@ -0,0 +1,11 @@
+package metrics
+
+import (
+	"github.com/onsi/gomega/gmeasure"
+)
+
+const (
+	AuditAnnotation = gmeasure.Annotation("audit")
+
+	LinesUnit = gmeasure.Units("lines")
+)
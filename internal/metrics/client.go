@@ -76,6 +76,16 @@ func (c *Client) Measure(e *gmeasure.Experiment, data Measurement) error {
 	return nil
 }
 
+// Value returns the current value of the measurement without recording it.
+func (c *Client) Value(data Measurement) (float64, error) {
+	value, err := c.executeScalarQuery(data.Query)
+	if err != nil {
+		return 0.0, fmt.Errorf("error querying measurement: %w", err)
+	}
+
+	return value, nil
+}
+
 func (c *Client) MeasureHTTPRequestMetrics(
 	e *gmeasure.Experiment,
 	path RequestPath,
@@ -179,6 +189,196 @@ func (c *Client) MeasureQueryMetrics(
 	return nil
 }
 
+// MeasureQueryMetricsByType records the query latency per type and range
+// label of Loki's query statistics, e.g. metric range queries apart from
+// filtered log range queries.
+func (c *Client) MeasureQueryMetricsByType(
+	e *gmeasure.Experiment,
+	job string,
+	sampleRange model.Duration,
+	annotation gmeasure.Annotation,
+) error {
+	for _, queryType := range LogQLQueryTypes {
+		for _, rangeType := range LogQLRangeTypes {
+			if err := c.Measure(e, LogQLQueryLatencyAverageByType("2.*", job, queryType, rangeType, sampleRange, annotation)); err != nil {
+				return err
+			}
+			if err := c.Measure(e, LogQLQueryLatencyQuantileByType("2.*", job, queryType, rangeType, DefaultPercentile, sampleRange, annotation)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// MeasureQuerySplittingMetrics records how the query-frontend splits and
+// shards the queries.
+func (c *Client) MeasureQuerySplittingMetrics(
+	e *gmeasure.Experiment,
+	job string,
+	sampleRange model.Duration,
+	annotation gmeasure.Annotation,
+) error {
+	if err := c.Measure(e, QueryShardFactorAverage(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, QueryShardFactorQuantile(job, DefaultPercentile, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, QuerySplitPartitionsAverage(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, QuerySplitPartitionsQuantile(job, DefaultPercentile, sampleRange, annotation)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MeasureQuerySchedulerMetrics records how long the sub-queries wait for a
+// querier and how many of them are queued and in flight.
+func (c *Client) MeasureQuerySchedulerMetrics(
+	e *gmeasure.Experiment,
+	job string,
+	sampleRange model.Duration,
+	annotation gmeasure.Annotation,
+) error {
+	if err := c.Measure(e, QueryQueueWaitQuantile(job, DefaultPercentile, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, QuerySchedulerQueueLengthAverage(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, QuerySchedulerQueueLengthMax(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, QuerySchedulerInflightAverage(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MeasureCompactorMetrics records how long compaction and retention take and
+// how many index tables and chunks the compactor processed and deleted. The
+// reclaimed bytes are estimated from the chunks flushed by ingesterJob.
+func (c *Client) MeasureCompactorMetrics(
+	e *gmeasure.Experiment,
+	job, ingesterJob string,
+	sampleRange model.Duration,
+	annotation gmeasure.Annotation,
+) error {
+	if err := c.Measure(e, CompactionDurationMax(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, CompactionRuns(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, RetentionDurationMax(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, IndexTablesProcessed(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, ChunksMarkedForDeletion(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, ChunksDeleted(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, ObjectStorageBytesReclaimed(job, ingesterJob, sampleRange, annotation)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MeasureRulerMetrics records how many rules the ruler evaluates, how long
+// and how reliably, and how the remote write of the recorded samples keeps up.
+func (c *Client) MeasureRulerMetrics(
+	e *gmeasure.Experiment,
+	job string,
+	sampleRange model.Duration,
+	annotation gmeasure.Annotation,
+) error {
+	if err := c.Measure(e, RulesLoaded(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, RuleEvaluationRate(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, RuleEvaluationFailureRate(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, RuleEvaluationDurationAverage(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, RuleGroupDurationMax(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, RuleGroupIterationsMissed(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, RemoteWriteSamplesRate(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, RemoteWriteSamplesFailedRate(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, RemoteWriteSamplesPendingAverage(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, RemoteWriteShardsMax(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MeasurePushErrorMetrics records the pushes the distributors reject and
+// the pushes they fail to forward to the ingesters.
+func (c *Client) MeasurePushErrorMetrics(
+	e *gmeasure.Experiment,
+	job string,
+	sampleRange model.Duration,
+	annotation gmeasure.Annotation,
+) error {
+	if err := c.Measure(e, PushErrorRate(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, IngesterClientPushErrorRate(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MeasureWALReplayMetrics records how long the ingesters replayed their WAL
+// after a restart, what they recovered and the streams they hold now. The
+// sample range must start after all ingesters restarted.
+func (c *Client) MeasureWALReplayMetrics(
+	e *gmeasure.Experiment,
+	job string,
+	sampleRange model.Duration,
+	annotation gmeasure.Annotation,
+) error {
+	if err := c.Measure(e, WALReplayDurationMax(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, WALRecoveredStreams(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, WALRecoveredEntries(job, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, IngesterStreams(job, annotation)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (c *Client) MeasureIngestionVerificationMetrics(
 	e *gmeasure.Experiment,
 	deployment string,
@@ -199,6 +399,199 @@ func (c *Client) MeasureIngestionVerificationMetrics(
 	return nil
 }
 
+func (c *Client) MeasureStructuredMetadataMetrics(
+	e *gmeasure.Experiment,
+	sampleRange model.Duration,
+) error {
+	if err := c.Measure(e, DistributorStructuredMetadataGiPDReceivedTotal(sampleRange)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, IngesterGiPDStoredTotal(sampleRange)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Client) MeasureTenantMetrics(
+	e *gmeasure.Experiment,
+	tenant string,
+	sampleRange model.Duration,
+) error {
+	if err := c.Measure(e, TenantDistributorGiPDReceived(tenant, sampleRange)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, TenantDistributorLinesReceivedRate(tenant, sampleRange)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, TenantDiscardedLinesRate(tenant, sampleRange)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, TenantDiscardedBytesRate(tenant, sampleRange)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MeasureClientHTTPRequestMetrics records the request rate, error rate and
+// latency observed by the loki-loadgen clients of a single tenant.
+func (c *Client) MeasureClientHTTPRequestMetrics(
+	e *gmeasure.Experiment,
+	path RequestPath,
+	tenant string,
+	sampleRange model.Duration,
+	annotation gmeasure.Annotation,
+) error {
+	var route string
+	switch path {
+	case WriteRequestPath:
+		route = HTTPPushRoute
+	case ReadRequestPath:
+		route = HTTPQueryRangeRoute
+	default:
+		return fmt.Errorf("error unknown path specified: %d", path)
+	}
+
+	name := fmt.Sprintf("2xx %s", route)
+
+	if err := c.Measure(e, ClientRequestRate(name, tenant, route, "2.*", sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, ClientRequestErrorRate(route, tenant, route, sampleRange, annotation)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, ClientRequestDurationQuantile(name, tenant, route, "2.*", DefaultPercentile, sampleRange, annotation)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MeasureReadAPIMetrics records the rate and latency of every read endpoint
+// of the job.
+func (c *Client) MeasureReadAPIMetrics(
+	e *gmeasure.Experiment,
+	job string,
+	sampleRange model.Duration,
+	annotation gmeasure.Annotation,
+) error {
+	for _, route := range HTTPReadAPIRoutes {
+		name := fmt.Sprintf("read API 2xx %s", route)
+
+		if err := c.Measure(e, RequestRate(name, job, route, "2.*", sampleRange, annotation)); err != nil {
+			return err
+		}
+		if err := c.Measure(e, RequestDurationQuantile(name, job, HTTPGetMethod, route, "2.*", DefaultPercentile, sampleRange, annotation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MeasureClientReadAPIMetrics records the client side rate, error rate and
+// latency of every read endpoint for the tenant.
+func (c *Client) MeasureClientReadAPIMetrics(
+	e *gmeasure.Experiment,
+	tenant string,
+	sampleRange model.Duration,
+	annotation gmeasure.Annotation,
+) error {
+	for _, route := range HTTPReadAPIRoutes {
+		name := fmt.Sprintf("read API 2xx %s", route)
+
+		if err := c.Measure(e, ClientRequestRate(name, tenant, route, "2.*", sampleRange, annotation)); err != nil {
+			return err
+		}
+		if err := c.Measure(e, ClientRequestErrorRate(fmt.Sprintf("read API %s", route), tenant, route, sampleRange, annotation)); err != nil {
+			return err
+		}
+		if err := c.Measure(e, ClientRequestDurationQuantile(name, tenant, route, "2.*", DefaultPercentile, sampleRange, annotation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) MeasureTailMetrics(
+	e *gmeasure.Experiment,
+	sampleRange model.Duration,
+) error {
+	if err := c.Measure(e, TailConnections()); err != nil {
+		return err
+	}
+	if err := c.Measure(e, TailDisconnectRate(sampleRange)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, TailEntriesRate(sampleRange)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, TailDroppedEntriesRate(sampleRange)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, TailDeliveryLatencyQuantile(DefaultPercentile, sampleRange)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MeasureProbeMetrics records the distribution of the visibility delay of
+// the canary lines and the share of lines never seen by a query.
+func (c *Client) MeasureProbeMetrics(
+	e *gmeasure.Experiment,
+	sampleRange model.Duration,
+) error {
+	if err := c.Measure(e, ProbeLinesSentRate(sampleRange)); err != nil {
+		return err
+	}
+	for _, percentile := range []int{50, DefaultPercentile, 99} {
+		if err := c.Measure(e, ProbeVisibilityDelayQuantile(percentile, sampleRange)); err != nil {
+			return err
+		}
+	}
+	if err := c.Measure(e, ProbeMissingLinesPercentage(sampleRange)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MeasureVerificationMetrics records the number of compared and mismatching
+// values of every verification check.
+func (c *Client) MeasureVerificationMetrics(
+	e *gmeasure.Experiment,
+	checks []string,
+	sampleRange model.Duration,
+) error {
+	for _, check := range checks {
+		if err := c.Measure(e, VerificationComparisons(check, sampleRange)); err != nil {
+			return err
+		}
+		if err := c.Measure(e, VerificationMismatches(check, sampleRange)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MeasureNamedQueryMetrics records the client side rate, errors, latency and
+// bytes processed of a single named query.
+func (c *Client) MeasureNamedQueryMetrics(
+	e *gmeasure.Experiment,
+	query string,
+	sampleRange model.Duration,
+) error {
+	if err := c.Measure(e, NamedQueryRate(query, sampleRange)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, NamedQueryErrorRate(query, sampleRange)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, NamedQueryDurationQuantile(query, DefaultPercentile, sampleRange)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, NamedQueryMBProcessedAverage(query, sampleRange)); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (c *Client) MeasureLoadQuerierMetrics(
 	e *gmeasure.Experiment,
 	sampleRange model.Duration,
@@ -206,6 +599,12 @@ func (c *Client) MeasureLoadQuerierMetrics(
 	if err := c.Measure(e, LogQLQueryRate(sampleRange)); err != nil {
 		return err
 	}
+	if err := c.Measure(e, QueryClientIntendedRate(sampleRange)); err != nil {
+		return err
+	}
+	if err := c.Measure(e, QueryClientAchievedRate(sampleRange)); err != nil {
+		return err
+	}
 	if err := c.Measure(e, LogQLQueryDurationAverage(sampleRange)); err != nil {
 		return err
 	}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/onsi/gomega/gmeasure"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	CompactorAnnotation = gmeasure.Annotation("compactor")
+
+	TablesUnit = gmeasure.Units("tables")
+	ChunksUnit = gmeasure.Units("chunks")
+	RunsUnit   = gmeasure.Units("runs")
+)
+
+// The compactor metrics are named loki_boltdb_shipper_* up to Loki 2.9 and
+// loki_compactor_* since.
+const (
+	compactionDurationMetric = `{__name__=~"loki_(boltdb_shipper|compactor)_compact_tables_operation_duration_seconds", pod=~"%s.*"}`
+	compactionTotalMetric    = `{__name__=~"loki_(boltdb_shipper|compactor)_compact_tables_operation_total", status="success", pod=~"%s.*"}`
+	retentionDurationMetric  = `{__name__=~"loki_(boltdb_shipper|compactor)_apply_retention_operation_duration_seconds", pod=~"%s.*"}`
+	tablesProcessedMetric    = `{__name__=~"loki_(boltdb_shipper|compactor)_retention_marker_table_processed_total", pod=~"%s.*"}`
+	chunksMarkedMetric       = `{__name__=~"loki_(boltdb_shipper|compactor)_retention_marker_count_total", pod=~"%s.*"}`
+	chunksDeletedMetric      = `{__name__=~"loki_(boltdb_shipper|compactor)_retention_sweeper_chunk_deleted_duration_seconds_count", status="success", pod=~"%s.*"}`
+)
+
+func CompactionDurationMax(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name: "Compaction duration max",
+		Query: fmt.Sprintf(
+			`max(max_over_time(%s[%s])) * %d`,
+			fmt.Sprintf(compactionDurationMetric, job), duration, SecondsToMillisecondsMultiplier,
+		),
+		Unit:       MillisecondsUnit,
+		Annotation: annotation,
+	}
+}
+
+func CompactionRuns(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name:       "Compaction runs",
+		Query:      fmt.Sprintf(`sum(increase(%s[%s]))`, fmt.Sprintf(compactionTotalMetric, job), duration),
+		Unit:       RunsUnit,
+		Annotation: annotation,
+	}
+}
+
+func RetentionDurationMax(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name: "Retention duration max",
+		Query: fmt.Sprintf(
+			`max(max_over_time(%s[%s])) * %d`,
+			fmt.Sprintf(retentionDurationMetric, job), duration, SecondsToMillisecondsMultiplier,
+		),
+		Unit:       MillisecondsUnit,
+		Annotation: annotation,
+	}
+}
+
+func IndexTablesProcessed(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name:       "Index tables processed",
+		Query:      fmt.Sprintf(`sum(increase(%s[%s]))`, fmt.Sprintf(tablesProcessedMetric, job), duration),
+		Unit:       TablesUnit,
+		Annotation: annotation,
+	}
+}
+
+func ChunksMarkedForDeletion(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name:       "Chunks marked for deletion",
+		Query:      fmt.Sprintf(`sum(increase(%s[%s]))`, fmt.Sprintf(chunksMarkedMetric, job), duration),
+		Unit:       ChunksUnit,
+		Annotation: annotation,
+	}
+}
+
+func ChunksDeleted(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name:       "Chunks deleted",
+		Query:      fmt.Sprintf(`sum(increase(%s[%s]))`, fmt.Sprintf(chunksDeletedMetric, job), duration),
+		Unit:       ChunksUnit,
+		Annotation: annotation,
+	}
+}
+
+// ObjectStorageBytesReclaimed estimates the reclaimed bytes from the deleted
+// chunks and the average size of the chunks flushed by the ingesters, since
+// Loki does not report the size of the deleted chunks.
+func ObjectStorageBytesReclaimed(job, ingesterJob string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	deleted := fmt.Sprintf(`sum(increase(%s[%s]))`, fmt.Sprintf(chunksDeletedMetric, job), duration)
+	chunkSize := fmt.Sprintf(
+		`sum(rate(loki_ingester_chunk_size_bytes_sum{pod=~"%s.*"}[%s])) / sum(rate(loki_ingester_chunk_size_bytes_count{pod=~"%s.*"}[%s]))`,
+		ingesterJob, duration, ingesterJob, duration,
+	)
+
+	return Measurement{
+		Name:       "Object storage bytes reclaimed (estimated)",
+		Query:      fmt.Sprintf(`%s * %s / %d`, deleted, chunkSize, BytesToMegabytesMultiplier),
+		Unit:       MegabytesUnit,
+		Annotation: annotation,
+	}
+}
+
+// ObjectStorageUsedBytes runs the scenario's query for the used bytes of the
+// object storage bucket.
+func ObjectStorageUsedBytes(query string, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name:       "Object storage used bytes",
+		Query:      fmt.Sprintf(`sum(%s) / %d`, query, BytesToGigabytesMultiplier),
+		Unit:       GigabytesUnit,
+		Annotation: annotation,
+	}
+}
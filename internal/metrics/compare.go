@@ -0,0 +1,140 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/onsi/gomega/gmeasure"
+)
+
+// RecordMedianDeltas compares two experiments sampling the same
+// measurements. For every value measurement annotated with annotation, it
+// records the difference between its median in the other experiment and its
+// median in the baseline experiment into delta.
+func RecordMedianDeltas(delta, baseline, other *gmeasure.Experiment, annotation gmeasure.Annotation) {
+	for _, m := range baseline.Measurements {
+		if m.Type != gmeasure.MeasurementTypeValue {
+			continue
+		}
+
+		before, ok := annotatedMedian(m, annotation)
+		if !ok {
+			continue
+		}
+
+		idx := other.Measurements.IdxWithName(m.Name)
+		if idx == -1 {
+			continue
+		}
+
+		after, ok := annotatedMedian(other.Measurements[idx], annotation)
+		if !ok {
+			continue
+		}
+
+		delta.RecordValue(m.Name, after-before, m.Units, annotation, gmeasure.Precision(4))
+	}
+}
+
+// RecordMedians copies the median of every value measurement of source
+// annotated with annotation into target, with the suffix appended to the
+// measurement name. Recording several sources with different suffixes puts
+// their medians side by side.
+func RecordMedians(target, source *gmeasure.Experiment, annotation gmeasure.Annotation, suffix string) {
+	for _, m := range source.Measurements {
+		if m.Type != gmeasure.MeasurementTypeValue {
+			continue
+		}
+
+		median, ok := annotatedMedian(m, annotation)
+		if !ok {
+			continue
+		}
+
+		target.RecordValue(fmt.Sprintf("%s - %s", m.Name, suffix), median, m.Units, annotation, gmeasure.Precision(4))
+	}
+}
+
+// RecordPhaseMedians records the median of every value measurement of source
+// per annotation and phase into target, with the phase appended to the
+// measurement name. phases holds the phase of every sample, the n-th value
+// of an annotation is attributed to the n-th sample. Samples without a phase
+// are skipped.
+func RecordPhaseMedians(target, source *gmeasure.Experiment, phases []string) {
+	for _, m := range source.Measurements {
+		if m.Type != gmeasure.MeasurementTypeValue {
+			continue
+		}
+
+		// The annotations and phases keep the order they were sampled in.
+		var annotations []string
+		order := map[string][]string{}
+		seen := map[string]int{}
+		values := map[string]map[string][]float64{}
+
+		for i, a := range m.Annotations {
+			n := seen[a]
+			seen[a]++
+
+			if n >= len(phases) || phases[n] == "" {
+				continue
+			}
+			phase := phases[n]
+
+			if _, ok := values[a]; !ok {
+				values[a] = map[string][]float64{}
+				annotations = append(annotations, a)
+			}
+			if _, ok := values[a][phase]; !ok {
+				order[a] = append(order[a], phase)
+			}
+			values[a][phase] = append(values[a][phase], m.Values[i])
+		}
+
+		for _, a := range annotations {
+			for _, phase := range order[a] {
+				target.RecordValue(fmt.Sprintf("%s - %s", m.Name, phase), median(values[a][phase]), m.Units, gmeasure.Annotation(a), gmeasure.Precision(4))
+			}
+		}
+	}
+}
+
+// AnnotatedMedians returns the median of the values of a value measurement
+// per annotation.
+func AnnotatedMedians(m gmeasure.Measurement) map[string]float64 {
+	values := map[string][]float64{}
+	for i, a := range m.Annotations {
+		values[a] = append(values[a], m.Values[i])
+	}
+
+	medians := make(map[string]float64, len(values))
+	for a, v := range values {
+		medians[a] = median(v)
+	}
+	return medians
+}
+
+func annotatedMedian(m gmeasure.Measurement, annotation gmeasure.Annotation) (float64, bool) {
+	var values []float64
+	for i, a := range m.Annotations {
+		if gmeasure.Annotation(a) == annotation {
+			values = append(values, m.Values[i])
+		}
+	}
+
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	return median(values), true
+}
+
+func median(values []float64) float64 {
+	sort.Float64s(values)
+
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/onsi/gomega/gmeasure"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	CorrectnessAnnotation = gmeasure.Annotation("correctness")
+
+	ComparisonsUnit = gmeasure.Units("comparisons")
+)
+
+// The following measurements use the verification metrics served by the
+// loki-querygen verifier. Every check compares several values, e.g. the
+// line count of every stream, and counts each differing value as mismatch.
+
+func VerificationComparisons(check string, duration model.Duration) Measurement {
+	return Measurement{
+		Name: fmt.Sprintf("Verified %s values", check),
+		Query: fmt.Sprintf(
+			`sum(increase(loki_benchmarks_verification_checks_total{check="%s"}[%s]))`,
+			check, duration,
+		),
+		Unit:       ComparisonsUnit,
+		Annotation: CorrectnessAnnotation,
+	}
+}
+
+func VerificationMismatches(check string, duration model.Duration) Measurement {
+	return Measurement{
+		Name: fmt.Sprintf("Mismatching %s values", check),
+		Query: fmt.Sprintf(
+			`sum(increase(loki_benchmarks_verification_mismatches_total{check="%s"}[%s]))`,
+			check, duration,
+		),
+		Unit:       ComparisonsUnit,
+		Annotation: CorrectnessAnnotation,
+	}
+}
@@ -0,0 +1,11 @@
+package metrics
+
+import (
+	"github.com/onsi/gomega/gmeasure"
+)
+
+const (
+	DeletionAnnotation = gmeasure.Annotation("deletion")
+
+	SecondsUnit = gmeasure.Units("s")
+)
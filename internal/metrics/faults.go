@@ -0,0 +1,9 @@
+package metrics
+
+import (
+	"github.com/onsi/gomega/gmeasure"
+)
+
+const (
+	FaultAnnotation = gmeasure.Annotation("fault")
+)
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/prometheus/common/model"
+)
+
+func DistributorStructuredMetadataGiPDReceivedTotal(duration model.Duration) Measurement {
+	return Measurement{
+		Name: "Total Projected Structured Metadata Bytes Received",
+		Query: fmt.Sprintf(
+			`sum(rate(loki_distributor_structured_metadata_bytes_received_total[%s])) / %d * %d`,
+			duration, BytesToGigabytesMultiplier, SecondsPerDay,
+		),
+		Unit:       GigabytesPerDayUnit,
+		Annotation: DistributorAnnotation,
+	}
+}
+
+func IngesterGiPDStoredTotal(duration model.Duration) Measurement {
+	return Measurement{
+		Name: "Total Projected Bytes Stored",
+		Query: fmt.Sprintf(
+			`sum(rate(loki_ingester_chunk_stored_bytes_total[%s])) / %d * %d`,
+			duration, BytesToGigabytesMultiplier, SecondsPerDay,
+		),
+		Unit:       GigabytesPerDayUnit,
+		Annotation: IngesterAnnotation,
+	}
+}
+
+func DistributorBytesReceivedTotal(duration model.Duration) Measurement {
+	return Measurement{
+		Name: "Total Bytes Received",
+		Query: fmt.Sprintf(
+			`sum(increase(loki_distributor_bytes_received_total[%s])) / %d`,
+			duration, BytesToGigabytesMultiplier,
+		),
+		Unit:       GigabytesUnit,
+		Annotation: DistributorAnnotation,
+	}
+}
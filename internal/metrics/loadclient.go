@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/onsi/gomega/gmeasure"
+	"github.com/prometheus/common/model"
+)
+
+// The following measurements use the client side request metrics served by
+// the loki-loadgen pods. Unlike Loki's own request metrics they are labeled
+// with the tenant.
+
+func ClientRequestRate(
+	name, tenant, route, code string,
+	duration model.Duration,
+	annotation gmeasure.Annotation,
+) Measurement {
+	return Measurement{
+		Name: fmt.Sprintf("%s client request rate", name),
+		Query: fmt.Sprintf(
+			`sum(rate(loki_benchmarks_client_request_duration_seconds_count{tenant="%s", route=~"%s", status_code=~"%s"}[%s]))`,
+			tenant, route, code, duration,
+		),
+		Unit:       RequestsPerSecondUnit,
+		Annotation: annotation,
+	}
+}
+
+func ClientRequestErrorRate(
+	name, tenant, route string,
+	duration model.Duration,
+	annotation gmeasure.Annotation,
+) Measurement {
+	return Measurement{
+		Name: fmt.Sprintf("%s client request error rate", name),
+		Query: fmt.Sprintf(
+			`sum(rate(loki_benchmarks_client_request_duration_seconds_count{tenant="%s", route=~"%s", status_code!~"2.*"}[%s]))`,
+			tenant, route, duration,
+		),
+		Unit:       RequestsPerSecondUnit,
+		Annotation: annotation,
+	}
+}
+
+func ClientRequestDurationQuantile(
+	name, tenant, route, code string,
+	percentile int,
+	duration model.Duration,
+	annotation gmeasure.Annotation,
+) Measurement {
+	return Measurement{
+		Name: fmt.Sprintf("%s client request duration P%d", name, percentile),
+		Query: fmt.Sprintf(
+			`histogram_quantile(0.%d, sum by (le) (rate(loki_benchmarks_client_request_duration_seconds_bucket{tenant="%s", route=~"%s", status_code=~"%s"}[%s]))) * %d`,
+			percentile, tenant, route, code, duration, SecondsToMillisecondsMultiplier,
+		),
+		Unit:       MillisecondsUnit,
+		Annotation: annotation,
+	}
+}
+
+// QueryClientIntendedRate is the rate of queries the loki-querygen pods
+// scheduled, including the ones dropped because too many were in flight.
+func QueryClientIntendedRate(duration model.Duration) Measurement {
+	return Measurement{
+		Name: "Intended query rate",
+		Query: fmt.Sprintf(
+			`sum(rate(loki_benchmarks_queries_scheduled_total[%s]))`,
+			duration,
+		),
+		Unit:       QueriesPerSecondUnit,
+		Annotation: LogQLAnnotation,
+	}
+}
+
+// QueryClientAchievedRate is the rate of queries the loki-querygen pods
+// completed, successful or not.
+func QueryClientAchievedRate(duration model.Duration) Measurement {
+	return Measurement{
+		Name: "Achieved query rate",
+		Query: fmt.Sprintf(
+			`sum(rate(loki_benchmarks_query_duration_seconds_count[%s]))`,
+			duration,
+		),
+		Unit:       QueriesPerSecondUnit,
+		Annotation: LogQLAnnotation,
+	}
+}
+
+// The following measurements break the loki-querygen metrics down by the
+// name of the query. They are annotated with the query name.
+
+func NamedQueryRate(query string, duration model.Duration) Measurement {
+	return Measurement{
+		Name: "Query rate",
+		Query: fmt.Sprintf(
+			`sum(rate(loki_benchmarks_query_duration_seconds_count{query="%s"}[%s]))`,
+			query, duration,
+		),
+		Unit:       QueriesPerSecondUnit,
+		Annotation: gmeasure.Annotation(query),
+	}
+}
+
+func NamedQueryErrorRate(query string, duration model.Duration) Measurement {
+	return Measurement{
+		Name: "Query error rate",
+		Query: fmt.Sprintf(
+			`sum(rate(loki_benchmarks_query_duration_seconds_count{query="%s", status!="200"}[%s]))`,
+			query, duration,
+		),
+		Unit:       QueriesPerSecondUnit,
+		Annotation: gmeasure.Annotation(query),
+	}
+}
+
+func NamedQueryDurationQuantile(query string, percentile int, duration model.Duration) Measurement {
+	return Measurement{
+		Name: fmt.Sprintf("Query duration P%d", percentile),
+		Query: fmt.Sprintf(
+			`histogram_quantile(0.%d, sum by (le) (rate(loki_benchmarks_query_duration_seconds_bucket{query="%s", status="200"}[%s]))) * %d`,
+			percentile, query, duration, SecondsToMillisecondsMultiplier,
+		),
+		Unit:       MillisecondsUnit,
+		Annotation: gmeasure.Annotation(query),
+	}
+}
+
+func NamedQueryMBProcessedAverage(query string, duration model.Duration) Measurement {
+	numerator := fmt.Sprintf(
+		`sum(rate(loki_benchmarks_query_bytes_processed_sum{query="%s"}[%s]))`,
+		query, duration,
+	)
+
+	denomintator := fmt.Sprintf(
+		`sum(rate(loki_benchmarks_query_bytes_processed_count{query="%s"}[%s]))`,
+		query, duration,
+	)
+
+	return Measurement{
+		Name:       "Query MB processed avg",
+		Query:      fmt.Sprintf(`(%s / %s) / %d`, numerator, denomintator, BytesToMegabytesMultiplier),
+		Unit:       MegabytesUnit,
+		Annotation: gmeasure.Annotation(query),
+	}
+}
@@ -11,6 +11,17 @@ const (
 	LogQLAnnotation = gmeasure.Annotation("logql")
 )
 
+var (
+	// LogQLQueryTypes are the values of the type label of Loki's query
+	// statistics: metric queries, log queries with a filter and log queries
+	// without one.
+	LogQLQueryTypes = []string{"metric", "filter", "limited"}
+
+	// LogQLRangeTypes are the values of the range label of Loki's query
+	// statistics.
+	LogQLRangeTypes = []string{"range", "instant"}
+)
+
 func LogQLQueryRate(duration model.Duration) Measurement {
 	return Measurement{
 		Name: "LogQL query rate",
@@ -133,3 +144,43 @@ func LogQLQueryMBpSProcessedQuantile(
 		Annotation: annotation,
 	}
 }
+
+func LogQLQueryLatencyAverageByType(
+	code, pod, queryType, rangeType string,
+	duration model.Duration,
+	annotation gmeasure.Annotation,
+) Measurement {
+	numerator := fmt.Sprintf(
+		`sum(rate(loki_logql_querystats_latency_seconds_sum{pod=~"%s.*", status_code=~"%s", type="%s", range="%s"}[%s]))`,
+		pod, code, queryType, rangeType, duration,
+	)
+
+	denomintator := fmt.Sprintf(
+		`sum(rate(loki_logql_querystats_latency_seconds_count{pod=~"%s.*", status_code=~"%s", type="%s", range="%s"}[%s]))`,
+		pod, code, queryType, rangeType, duration,
+	)
+
+	return Measurement{
+		Name:       fmt.Sprintf("LogQL %s %s query latency avg", queryType, rangeType),
+		Query:      fmt.Sprintf(`(%s / %s) * %d`, numerator, denomintator, SecondsToMillisecondsMultiplier),
+		Unit:       MillisecondsUnit,
+		Annotation: annotation,
+	}
+}
+
+func LogQLQueryLatencyQuantileByType(
+	code, pod, queryType, rangeType string,
+	percentile int,
+	duration model.Duration,
+	annotation gmeasure.Annotation,
+) Measurement {
+	return Measurement{
+		Name: fmt.Sprintf("LogQL %s %s query latency P%d", queryType, rangeType, percentile),
+		Query: fmt.Sprintf(
+			`histogram_quantile(0.%d, sum by (le) (rate(loki_logql_querystats_latency_seconds_bucket{pod=~"%s.*", status_code=~"%s", type="%s", range="%s"}[%s]))) * %d`,
+			percentile, pod, code, queryType, rangeType, duration, SecondsToMillisecondsMultiplier,
+		),
+		Unit:       MillisecondsUnit,
+		Annotation: annotation,
+	}
+}
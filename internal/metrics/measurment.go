@@ -15,6 +15,7 @@ const (
 	BytesToGigabytesMultiplier      int = BytesToMegabytesMultiplier * 1000
 
 	GigabytesUnit    = gmeasure.Units("GB")
+	MegabytesUnit    = gmeasure.Units("MB")
 	MillicoresUnit   = gmeasure.Units("m")
 	MillisecondsUnit = gmeasure.Units("ms")
 
@@ -22,11 +23,15 @@ const (
 	QueriesPerSecondUnit  = gmeasure.Units("queries per second")
 	RequestsPerSecondUnit = gmeasure.Units("requests per second")
 
-	DistributorAnnotation   = gmeasure.Annotation("distributor")
-	IngesterAnnotation      = gmeasure.Annotation("ingester")
-	QuerierAnnotation       = gmeasure.Annotation("querier")
-	QueryFrontendAnnotation = gmeasure.Annotation("query-frontend")
-	IndexGatewayAnnotation  = gmeasure.Annotation("index-gateway")
+	DistributorAnnotation    = gmeasure.Annotation("distributor")
+	IngesterAnnotation       = gmeasure.Annotation("ingester")
+	QuerierAnnotation        = gmeasure.Annotation("querier")
+	QueryFrontendAnnotation  = gmeasure.Annotation("query-frontend")
+	IndexGatewayAnnotation   = gmeasure.Annotation("index-gateway")
+	QuerySchedulerAnnotation = gmeasure.Annotation("query-scheduler")
+
+	VictimAnnotation    = gmeasure.Annotation("victim")
+	AggressorAnnotation = gmeasure.Annotation("aggressor")
 )
 
 type Measurement struct {
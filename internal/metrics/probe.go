@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/onsi/gomega/gmeasure"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	VisibilityAnnotation = gmeasure.Annotation("visibility")
+
+	PercentUnit = gmeasure.Units("%")
+)
+
+// The following measurements use the metrics served by the loki-probe pod.
+
+func ProbeLinesSentRate(duration model.Duration) Measurement {
+	return Measurement{
+		Name:       "Canary lines sent rate",
+		Query:      fmt.Sprintf(`sum(rate(loki_benchmarks_probe_lines_sent_total[%s]))`, duration),
+		Unit:       LinesPerSecondUnit,
+		Annotation: VisibilityAnnotation,
+	}
+}
+
+func ProbeVisibilityDelayQuantile(percentile int, duration model.Duration) Measurement {
+	return Measurement{
+		Name: fmt.Sprintf("Canary line visibility delay P%d", percentile),
+		Query: fmt.Sprintf(
+			`histogram_quantile(0.%d, sum by (le) (rate(loki_benchmarks_probe_visibility_delay_seconds_bucket[%s]))) * %d`,
+			percentile, duration, SecondsToMillisecondsMultiplier,
+		),
+		Unit:       MillisecondsUnit,
+		Annotation: VisibilityAnnotation,
+	}
+}
+
+func ProbeMissingLinesPercentage(duration model.Duration) Measurement {
+	return Measurement{
+		Name: "Canary lines missing",
+		Query: fmt.Sprintf(
+			`sum(increase(loki_benchmarks_probe_lines_missing_total[%s])) / sum(increase(loki_benchmarks_probe_lines_sent_total[%s])) * 100`,
+			duration, duration,
+		),
+		Unit:       PercentUnit,
+		Annotation: VisibilityAnnotation,
+	}
+}
@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+
+	"github.com/onsi/gomega/gmeasure"
+	"github.com/prometheus/common/model"
+)
+
+// RecordQueryLogMetrics records the rate, errors, latency and bytes processed
+// of a named query from the query statistics Loki logged over the sample
+// range. Loki's query statistics metrics carry no label to tell the queries
+// apart. A nil summary records a query without requests.
+func RecordQueryLogMetrics(
+	e *gmeasure.Experiment,
+	query string,
+	summary *loki.QueryLogSummary,
+	sampleRange model.Duration,
+) {
+	if summary == nil {
+		summary = &loki.QueryLogSummary{}
+	}
+
+	seconds := time.Duration(sampleRange).Seconds()
+	annotation := gmeasure.Annotation(query)
+	latency := float64(summary.DurationQuantile(float64(DefaultPercentile)/100)) / float64(time.Millisecond)
+
+	e.RecordValue("Loki query rate", float64(summary.Queries)/seconds, QueriesPerSecondUnit, annotation, gmeasure.Precision(4))
+	e.RecordValue("Loki query error rate", float64(summary.Errors)/seconds, QueriesPerSecondUnit, annotation, gmeasure.Precision(4))
+	e.RecordValue(fmt.Sprintf("Loki query duration P%d", DefaultPercentile), latency, MillisecondsUnit, annotation, gmeasure.Precision(4))
+	e.RecordValue("Loki query MB processed avg", summary.BytesAverage()/float64(BytesToMegabytesMultiplier), MegabytesUnit, annotation, gmeasure.Precision(4))
+}
@@ -11,11 +11,30 @@ const (
 	HTTPGetMethod  = "GET"
 	HTTPPostMethod = "POST"
 
-	HTTPPushRoute       = "loki_api_v1_push"
-	HTTPQueryRangeRoute = "loki_api_v1_query_range"
-	HTTPReadPathRoutes  = "loki_api_v1_series|api_prom_series|api_prom_query|api_prom_label|api_prom_label_name_values|loki_api_v1_query|loki_api_v1_query_range|loki_api_v1_labels|loki_api_v1_label_name_values"
+	HTTPPushRoute        = "loki_api_v1_push"
+	HTTPQueryRoute       = "loki_api_v1_query"
+	HTTPQueryRangeRoute  = "loki_api_v1_query_range"
+	HTTPLabelsRoute      = "loki_api_v1_labels"
+	HTTPLabelValuesRoute = "loki_api_v1_label_name_values"
+	HTTPSeriesRoute      = "loki_api_v1_series"
+	HTTPIndexStatsRoute  = "loki_api_v1_index_stats"
+	HTTPVolumeRoute      = "loki_api_v1_index_volume"
+	HTTPVolumeRangeRoute = "loki_api_v1_index_volume_range"
+	HTTPReadPathRoutes   = "loki_api_v1_series|api_prom_series|api_prom_query|api_prom_label|api_prom_label_name_values|loki_api_v1_query|loki_api_v1_query_range|loki_api_v1_labels|loki_api_v1_label_name_values|loki_api_v1_index_stats|loki_api_v1_index_volume|loki_api_v1_index_volume_range"
 )
 
+// HTTPReadAPIRoutes are the read endpoints measured one by one.
+var HTTPReadAPIRoutes = []string{
+	HTTPQueryRoute,
+	HTTPQueryRangeRoute,
+	HTTPLabelsRoute,
+	HTTPLabelValuesRoute,
+	HTTPSeriesRoute,
+	HTTPIndexStatsRoute,
+	HTTPVolumeRoute,
+	HTTPVolumeRangeRoute,
+}
+
 const (
 	GRPCMethod = "gRPC"
 
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/onsi/gomega/gmeasure"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	RestartAnnotation = gmeasure.Annotation("restart")
+
+	EntriesUnit = gmeasure.Units("entries")
+)
+
+// ingesterClientPushRoute is the operation label of the pushes from the
+// distributors to the ingesters. Failed pushes have a status_code other
+// than success.
+const ingesterClientPushRoute = "/logproto.Pusher/Push"
+
+func PushErrorRate(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return RequestRate(fmt.Sprintf("5xx %s", HTTPPushRoute), job, HTTPPushRoute, "5.*", duration, annotation)
+}
+
+func IngesterClientPushErrorRate(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name: "Failed ingester pushes rate",
+		Query: fmt.Sprintf(
+			`sum(rate(loki_ingester_client_request_duration_seconds_count{pod=~"%s.*", operation="%s", status_code!="success"}[%s]))`,
+			job, ingesterClientPushRoute, duration,
+		),
+		Unit:       RequestsPerSecondUnit,
+		Annotation: annotation,
+	}
+}
+
+func WALReplayDurationMax(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name: "WAL replay duration max",
+		Query: fmt.Sprintf(
+			`max(max_over_time(loki_ingester_wal_replay_duration_seconds{pod=~"%s.*"}[%s])) * %d`,
+			job, duration, SecondsToMillisecondsMultiplier,
+		),
+		Unit:       MillisecondsUnit,
+		Annotation: annotation,
+	}
+}
+
+// WALRecoveredStreams and WALRecoveredEntries take the highest value of the
+// replay counters, which reset with the restart and stop increasing once the
+// replay finished. The duration must only cover samples of the restarted
+// ingesters.
+func WALRecoveredStreams(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name:       "WAL recovered streams",
+		Query:      fmt.Sprintf(`sum(max_over_time(loki_ingester_wal_recovered_streams_total{pod=~"%s.*"}[%s]))`, job, duration),
+		Unit:       StreamsUnit,
+		Annotation: annotation,
+	}
+}
+
+func WALRecoveredEntries(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name:       "WAL recovered entries",
+		Query:      fmt.Sprintf(`sum(max_over_time(loki_ingester_wal_recovered_entries_total{pod=~"%s.*"}[%s]))`, job, duration),
+		Unit:       EntriesUnit,
+		Annotation: annotation,
+	}
+}
+
+func IngesterStreams(job string, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name:       "Streams in memory",
+		Query:      fmt.Sprintf(`sum(loki_ingester_memory_streams{pod=~"%s.*"})`, job),
+		Unit:       StreamsUnit,
+		Annotation: annotation,
+	}
+}
@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/onsi/gomega/gmeasure"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	RulerAnnotation = gmeasure.Annotation("ruler")
+
+	RulesUnit                = gmeasure.Units("rules")
+	IterationsUnit           = gmeasure.Units("iterations")
+	EvaluationsPerSecondUnit = gmeasure.Units("evaluations per second")
+	SamplesUnit              = gmeasure.Units("samples")
+	SamplesPerSecondUnit     = gmeasure.Units("samples per second")
+	RemoteWriteShardsUnit    = gmeasure.Units("remote write shards")
+)
+
+// The rule manager metrics are prefixed cortex_ up to Loki 2.9 and loki_
+// since. The remote write metrics of the ruler WAL are prefixed
+// loki_ruler_wal_.
+const (
+	ruleEvaluationMetricRegex = "(cortex|loki)_prometheus_rule_%s"
+	remoteWriteMetricRegex    = "(loki_ruler_wal_)?prometheus_remote_storage_%s"
+)
+
+func ruleMetric(name, job string) string {
+	return fmt.Sprintf(`{__name__=~"%s", pod=~"%s.*"}`, fmt.Sprintf(ruleEvaluationMetricRegex, name), job)
+}
+
+func remoteWriteMetric(name, job string) string {
+	return fmt.Sprintf(`{__name__=~"%s", pod=~"%s.*"}`, fmt.Sprintf(remoteWriteMetricRegex, name), job)
+}
+
+func RulesLoaded(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name:       "Rules loaded",
+		Query:      fmt.Sprintf(`sum(max_over_time(%s[%s]))`, ruleMetric("group_rules", job), duration),
+		Unit:       RulesUnit,
+		Annotation: annotation,
+	}
+}
+
+func RuleEvaluationRate(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name:       "Rule evaluation rate",
+		Query:      fmt.Sprintf(`sum(rate(%s[%s]))`, ruleMetric("evaluations_total", job), duration),
+		Unit:       EvaluationsPerSecondUnit,
+		Annotation: annotation,
+	}
+}
+
+func RuleEvaluationFailureRate(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name:       "Rule evaluation failure rate",
+		Query:      fmt.Sprintf(`sum(rate(%s[%s]))`, ruleMetric("evaluation_failures_total", job), duration),
+		Unit:       EvaluationsPerSecondUnit,
+		Annotation: annotation,
+	}
+}
+
+func RuleEvaluationDurationAverage(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	numerator := fmt.Sprintf(`sum(rate(%s[%s]))`, ruleMetric("evaluation_duration_seconds_sum", job), duration)
+	denomintator := fmt.Sprintf(`sum(rate(%s[%s]))`, ruleMetric("evaluation_duration_seconds_count", job), duration)
+
+	return Measurement{
+		Name:       "Rule evaluation duration avg",
+		Query:      fmt.Sprintf(`%s / %s * %d`, numerator, denomintator, SecondsToMillisecondsMultiplier),
+		Unit:       MillisecondsUnit,
+		Annotation: annotation,
+	}
+}
+
+func RuleGroupDurationMax(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name: "Rule group evaluation duration max",
+		Query: fmt.Sprintf(
+			`max(max_over_time(%s[%s])) * %d`,
+			ruleMetric("group_last_duration_seconds", job), duration, SecondsToMillisecondsMultiplier,
+		),
+		Unit:       MillisecondsUnit,
+		Annotation: annotation,
+	}
+}
+
+func RuleGroupIterationsMissed(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name:       "Rule group iterations missed",
+		Query:      fmt.Sprintf(`sum(increase(%s[%s]))`, ruleMetric("group_iterations_missed_total", job), duration),
+		Unit:       IterationsUnit,
+		Annotation: annotation,
+	}
+}
+
+func RemoteWriteSamplesRate(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name:       "Remote write samples rate",
+		Query:      fmt.Sprintf(`sum(rate(%s[%s]))`, remoteWriteMetric("samples_total", job), duration),
+		Unit:       SamplesPerSecondUnit,
+		Annotation: annotation,
+	}
+}
+
+func RemoteWriteSamplesFailedRate(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name:       "Remote write samples failed rate",
+		Query:      fmt.Sprintf(`sum(rate(%s[%s]))`, remoteWriteMetric("samples_failed_total", job), duration),
+		Unit:       SamplesPerSecondUnit,
+		Annotation: annotation,
+	}
+}
+
+func RemoteWriteSamplesPendingAverage(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name:       "Remote write samples pending avg",
+		Query:      fmt.Sprintf(`sum(avg_over_time(%s[%s]))`, remoteWriteMetric("samples_pending", job), duration),
+		Unit:       SamplesUnit,
+		Annotation: annotation,
+	}
+}
+
+func RemoteWriteShardsMax(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name:       "Remote write shards max",
+		Query:      fmt.Sprintf(`sum(max_over_time(%s[%s]))`, remoteWriteMetric("shards", job), duration),
+		Unit:       RemoteWriteShardsUnit,
+		Annotation: annotation,
+	}
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/onsi/gomega/gmeasure"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	ShardsUnit     = gmeasure.Units("shards")
+	SubQueriesUnit = gmeasure.Units("sub-queries")
+	QueriesUnit    = gmeasure.Units("queries")
+)
+
+// The queue metrics are named cortex_* up to Loki 2.9 and loki_* since.
+const (
+	queueDurationMetric = `{__name__=~"(cortex|loki)_query_scheduler_queue_duration_seconds_bucket", pod=~"%s.*"}`
+	queueLengthMetric   = `{__name__=~"(cortex|loki)_query_scheduler_queue_length", pod=~"%s.*"}`
+	inflightSumMetric   = `{__name__=~"(cortex|loki)_query_scheduler_inflight_requests_sum", pod=~"%s.*"}`
+	inflightCountMetric = `{__name__=~"(cortex|loki)_query_scheduler_inflight_requests_count", pod=~"%s.*"}`
+)
+
+func QueryShardFactorAverage(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	numerator := fmt.Sprintf(
+		`sum(rate(loki_query_frontend_shard_factor_sum{pod=~"%s.*"}[%s]))`,
+		job, duration,
+	)
+
+	denomintator := fmt.Sprintf(
+		`sum(rate(loki_query_frontend_shard_factor_count{pod=~"%s.*"}[%s]))`,
+		job, duration,
+	)
+
+	return Measurement{
+		Name:       "Shard factor avg",
+		Query:      fmt.Sprintf(`%s / %s`, numerator, denomintator),
+		Unit:       ShardsUnit,
+		Annotation: annotation,
+	}
+}
+
+func QueryShardFactorQuantile(job string, percentile int, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name: fmt.Sprintf("Shard factor P%d", percentile),
+		Query: fmt.Sprintf(
+			`histogram_quantile(0.%d, sum by (le) (rate(loki_query_frontend_shard_factor_bucket{pod=~"%s.*"}[%s])))`,
+			percentile, job, duration,
+		),
+		Unit:       ShardsUnit,
+		Annotation: annotation,
+	}
+}
+
+func QuerySplitPartitionsAverage(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	numerator := fmt.Sprintf(
+		`sum(rate(loki_query_frontend_partitions_sum{pod=~"%s.*"}[%s]))`,
+		job, duration,
+	)
+
+	denomintator := fmt.Sprintf(
+		`sum(rate(loki_query_frontend_partitions_count{pod=~"%s.*"}[%s]))`,
+		job, duration,
+	)
+
+	return Measurement{
+		Name:       "Split by interval sub-queries avg",
+		Query:      fmt.Sprintf(`%s / %s`, numerator, denomintator),
+		Unit:       SubQueriesUnit,
+		Annotation: annotation,
+	}
+}
+
+func QuerySplitPartitionsQuantile(job string, percentile int, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name: fmt.Sprintf("Split by interval sub-queries P%d", percentile),
+		Query: fmt.Sprintf(
+			`histogram_quantile(0.%d, sum by (le) (rate(loki_query_frontend_partitions_bucket{pod=~"%s.*"}[%s])))`,
+			percentile, job, duration,
+		),
+		Unit:       SubQueriesUnit,
+		Annotation: annotation,
+	}
+}
+
+func QueryQueueWaitQuantile(job string, percentile int, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name: fmt.Sprintf("Querier queue wait P%d", percentile),
+		Query: fmt.Sprintf(
+			`histogram_quantile(0.%d, sum by (le) (rate(%s[%s]))) * %d`,
+			percentile, fmt.Sprintf(queueDurationMetric, job), duration, SecondsToMillisecondsMultiplier,
+		),
+		Unit:       MillisecondsUnit,
+		Annotation: annotation,
+	}
+}
+
+func QuerySchedulerQueueLengthAverage(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name:       "Query scheduler queue length avg",
+		Query:      fmt.Sprintf(`sum(avg_over_time(%s[%s]))`, fmt.Sprintf(queueLengthMetric, job), duration),
+		Unit:       QueriesUnit,
+		Annotation: annotation,
+	}
+}
+
+func QuerySchedulerQueueLengthMax(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	return Measurement{
+		Name:       "Query scheduler queue length max",
+		Query:      fmt.Sprintf(`max(max_over_time(%s[%s]))`, fmt.Sprintf(queueLengthMetric, job), duration),
+		Unit:       QueriesUnit,
+		Annotation: annotation,
+	}
+}
+
+func QuerySchedulerInflightAverage(job string, duration model.Duration, annotation gmeasure.Annotation) Measurement {
+	numerator := fmt.Sprintf(`sum(rate(%s[%s]))`, fmt.Sprintf(inflightSumMetric, job), duration)
+	denomintator := fmt.Sprintf(`sum(rate(%s[%s]))`, fmt.Sprintf(inflightCountMetric, job), duration)
+
+	return Measurement{
+		Name:       "Query scheduler inflight requests avg",
+		Query:      fmt.Sprintf(`%s / %s`, numerator, denomintator),
+		Unit:       QueriesUnit,
+		Annotation: annotation,
+	}
+}
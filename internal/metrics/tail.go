@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/onsi/gomega/gmeasure"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	TailAnnotation = gmeasure.Annotation("tail")
+
+	ConnectionsUnit       = gmeasure.Units("connections")
+	EntriesPerSecondUnit  = gmeasure.Units("entries per second")
+	DisconnectsPerSecUnit = gmeasure.Units("disconnects per second")
+)
+
+// The following measurements use the tail metrics served by the
+// loki-querygen pods.
+
+func TailConnections() Measurement {
+	return Measurement{
+		Name:       "Open tail connections",
+		Query:      `sum(loki_benchmarks_tail_connections)`,
+		Unit:       ConnectionsUnit,
+		Annotation: TailAnnotation,
+	}
+}
+
+func TailDisconnectRate(duration model.Duration) Measurement {
+	return Measurement{
+		Name:       "Tail disconnect rate",
+		Query:      fmt.Sprintf(`sum(rate(loki_benchmarks_tail_disconnects_total[%s]))`, duration),
+		Unit:       DisconnectsPerSecUnit,
+		Annotation: TailAnnotation,
+	}
+}
+
+func TailEntriesRate(duration model.Duration) Measurement {
+	return Measurement{
+		Name:       "Tail received entries rate",
+		Query:      fmt.Sprintf(`sum(rate(loki_benchmarks_tail_entries_total[%s]))`, duration),
+		Unit:       EntriesPerSecondUnit,
+		Annotation: TailAnnotation,
+	}
+}
+
+func TailDroppedEntriesRate(duration model.Duration) Measurement {
+	return Measurement{
+		Name:       "Tail dropped entries rate",
+		Query:      fmt.Sprintf(`sum(rate(loki_benchmarks_tail_dropped_entries_total[%s]))`, duration),
+		Unit:       EntriesPerSecondUnit,
+		Annotation: TailAnnotation,
+	}
+}
+
+func TailDeliveryLatencyQuantile(percentile int, duration model.Duration) Measurement {
+	return Measurement{
+		Name: fmt.Sprintf("Tail delivery latency P%d", percentile),
+		Query: fmt.Sprintf(
+			`histogram_quantile(0.%d, sum by (le) (rate(loki_benchmarks_tail_delivery_latency_seconds_bucket[%s]))) * %d`,
+			percentile, duration, SecondsToMillisecondsMultiplier,
+		),
+		Unit:       MillisecondsUnit,
+		Annotation: TailAnnotation,
+	}
+}
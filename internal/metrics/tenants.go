@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/onsi/gomega/gmeasure"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	LinesPerSecondUnit = gmeasure.Units("lines per second")
+)
+
+// Tenant measurements share their names across tenants and are annotated
+// with the tenant name instead of the component.
+
+func TenantDistributorGiPDReceived(tenant string, duration model.Duration) Measurement {
+	return Measurement{
+		Name: "Tenant Projected Bytes Received",
+		Query: fmt.Sprintf(
+			`sum(rate(loki_distributor_bytes_received_total{tenant="%s"}[%s])) / %d * %d`,
+			tenant, duration, BytesToGigabytesMultiplier, SecondsPerDay,
+		),
+		Unit:       GigabytesPerDayUnit,
+		Annotation: gmeasure.Annotation(tenant),
+	}
+}
+
+func TenantDistributorLinesReceivedRate(tenant string, duration model.Duration) Measurement {
+	return Measurement{
+		Name: "Tenant Lines Received",
+		Query: fmt.Sprintf(
+			`sum(rate(loki_distributor_lines_received_total{tenant="%s"}[%s]))`,
+			tenant, duration,
+		),
+		Unit:       LinesPerSecondUnit,
+		Annotation: gmeasure.Annotation(tenant),
+	}
+}
+
+func TenantDiscardedLinesRate(tenant string, duration model.Duration) Measurement {
+	return Measurement{
+		Name: "Tenant Lines Discarded",
+		Query: fmt.Sprintf(
+			`sum(rate(loki_discarded_samples_total{tenant="%s"}[%s]))`,
+			tenant, duration,
+		),
+		Unit:       LinesPerSecondUnit,
+		Annotation: gmeasure.Annotation(tenant),
+	}
+}
+
+func TenantDiscardedBytesRate(tenant string, duration model.Duration) Measurement {
+	return Measurement{
+		Name: "Tenant Bytes Discarded",
+		Query: fmt.Sprintf(
+			`sum(rate(loki_discarded_bytes_total{tenant="%s"}[%s])) / %d`,
+			tenant, duration, BytesToMegabytesMultiplier,
+		),
+		Unit:       MegabytesPerSecondUnit,
+		Annotation: gmeasure.Annotation(tenant),
+	}
+}
@@ -0,0 +1,239 @@
+// Package probe measures the end-to-end visibility latency of Loki: it pushes
+// uniquely tagged canary lines at a fixed rate and polls for them until they
+// are returned by a query.
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// ProbeLabel holds the name of the probe on the canary stream.
+	ProbeLabel = "probe"
+
+	idPrefix = "id="
+)
+
+type metrics struct {
+	sent            prometheus.Counter
+	pushErrors      prometheus.Counter
+	visible         prometheus.Counter
+	missing         prometheus.Counter
+	visibilityDelay prometheus.Histogram
+}
+
+func newMetrics(reg prometheus.Registerer) (*metrics, error) {
+	m := &metrics{
+		sent: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_benchmarks",
+			Name:      "probe_lines_sent_total",
+			Help:      "Number of canary lines accepted by Loki.",
+		}),
+		pushErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_benchmarks",
+			Name:      "probe_push_errors_total",
+			Help:      "Number of canary lines rejected by Loki. They are not polled for.",
+		}),
+		visible: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_benchmarks",
+			Name:      "probe_lines_visible_total",
+			Help:      "Number of canary lines returned by a query before the timeout.",
+		}),
+		missing: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_benchmarks",
+			Name:      "probe_lines_missing_total",
+			Help:      "Number of canary lines not returned by any query before the timeout.",
+		}),
+		visibilityDelay: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "loki_benchmarks",
+			Name:      "probe_visibility_delay_seconds",
+			Help:      "Time between the push of a canary line and the first query returning it.",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 14),
+		}),
+	}
+
+	for _, c := range []prometheus.Collector{m.sent, m.pushErrors, m.visible, m.missing, m.visibilityDelay} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+type Probe struct {
+	pushClient  *loki.Client
+	queryClient *loki.Client
+	name        string
+	metrics     *metrics
+
+	mu      sync.Mutex
+	pending map[string]time.Time
+}
+
+func NewProbe(pushClient, queryClient *loki.Client, name string, reg prometheus.Registerer) (*Probe, error) {
+	m, err := newMetrics(reg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Probe{
+		pushClient:  pushClient,
+		queryClient: queryClient,
+		name:        name,
+		metrics:     m,
+		pending:     map[string]time.Time{},
+	}, nil
+}
+
+// Selector returns the stream selector of the canary lines of the probe.
+func (p *Probe) Selector() string {
+	return fmt.Sprintf(`{%s="%s"}`, ProbeLabel, p.name)
+}
+
+// Run pushes rate canary lines per second and polls for the pending lines
+// every pollInterval until the context is done. Lines not returned within
+// timeout are counted as missing. The measured delay is rounded up to the
+// poll interval.
+func (p *Probe) Run(ctx context.Context, rate float64, pollInterval, timeout time.Duration) error {
+	log.Printf("probing %s at %g lines per second", p.Selector(), rate)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		p.push(ctx, time.Duration(float64(time.Second)/rate))
+	}()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		case <-ticker.C:
+			p.poll(ctx, rate, timeout)
+		}
+	}
+}
+
+func (p *Probe) push(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for id := 0; ; id++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		key := fmt.Sprintf("%s%d", idPrefix, id)
+
+		req := &loki.PushRequest{
+			Streams: []loki.Stream{
+				{
+					Labels:  map[string]string{ProbeLabel: p.name},
+					Entries: []loki.Entry{{Timestamp: now, Line: key}},
+				},
+			},
+		}
+
+		if err := p.pushClient.Push(ctx, req); err != nil {
+			if ctx.Err() == nil {
+				log.Printf("failed pushing canary line: %v", err)
+				p.metrics.pushErrors.Inc()
+			}
+			continue
+		}
+
+		p.metrics.sent.Inc()
+
+		p.mu.Lock()
+		p.pending[key] = now
+		p.mu.Unlock()
+	}
+}
+
+func (p *Probe) poll(ctx context.Context, rate float64, timeout time.Duration) {
+	p.mu.Lock()
+	oldest := time.Time{}
+	for _, sent := range p.pending {
+		if oldest.IsZero() || sent.Before(oldest) {
+			oldest = sent
+		}
+	}
+	pending := len(p.pending)
+	p.mu.Unlock()
+
+	if pending == 0 {
+		return
+	}
+
+	now := time.Now()
+	// The lines already seen since the oldest pending one are returned as well.
+	limit := int(now.Sub(oldest).Seconds()*rate) + 100
+
+	res, err := p.queryClient.QueryRange(loki.WithQueryName(ctx, "probe"), p.Selector(), oldest, now.Add(time.Second), 0, limit)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("failed polling canary lines: %v", err)
+		}
+	} else {
+		p.observe(res, time.Now())
+	}
+
+	p.expire(time.Now(), timeout)
+}
+
+func (p *Probe) observe(res *loki.QueryResponse, now time.Time) {
+	var streams []loki.StreamResult
+	if err := json.Unmarshal(res.Data.Result, &streams); err != nil {
+		log.Printf("failed decoding canary lines: %v", err)
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, s := range streams {
+		for _, value := range s.Values {
+			if len(value) < 2 || !strings.HasPrefix(value[1], idPrefix) {
+				continue
+			}
+
+			sent, ok := p.pending[value[1]]
+			if !ok {
+				continue
+			}
+
+			p.metrics.visible.Inc()
+			p.metrics.visibilityDelay.Observe(now.Sub(sent).Seconds())
+			delete(p.pending, value[1])
+		}
+	}
+}
+
+func (p *Probe) expire(now time.Time, timeout time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, sent := range p.pending {
+		if now.Sub(sent) > timeout {
+			p.metrics.missing.Inc()
+			delete(p.pending, key)
+		}
+	}
+}
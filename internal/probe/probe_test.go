@@ -0,0 +1,72 @@
+package probe
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func streams(t *testing.T, lines ...string) *loki.QueryResponse {
+	t.Helper()
+
+	values := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		values = append(values, []string{"1", line})
+	}
+
+	result, err := json.Marshal([]loki.StreamResult{{Labels: map[string]string{ProbeLabel: "p"}, Values: values}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &loki.QueryResponse{Data: loki.QueryData{ResultType: loki.ResultTypeStreams, Result: result}}
+}
+
+func TestObserveAndExpire(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	p, err := NewProbe(nil, nil, "p", reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := time.Unix(1700000000, 0)
+	p.pending = map[string]time.Time{
+		"id=0": sent,
+		"id=1": sent.Add(time.Second),
+		"id=2": sent.Add(2 * time.Second),
+	}
+
+	// A line seen twice, a foreign line and an unknown canary line.
+	p.observe(streams(t, "id=0", "id=0", "other", "id=9"), sent.Add(3*time.Second))
+
+	if got := testutil.ToFloat64(p.metrics.visible); got != 1 {
+		t.Errorf("got %v visible lines, want 1", got)
+	}
+	if n, err := testutil.GatherAndCount(reg, "loki_benchmarks_probe_visibility_delay_seconds"); err != nil || n != 1 {
+		t.Errorf("got %d delay histograms, %v", n, err)
+	}
+
+	p.expire(sent.Add(12*time.Second), 10*time.Second)
+
+	if got := testutil.ToFloat64(p.metrics.missing); got != 1 {
+		t.Errorf("got %v missing lines, want 1", got)
+	}
+	if _, ok := p.pending["id=2"]; !ok || len(p.pending) != 1 {
+		t.Errorf("got pending lines %v, want id=2", p.pending)
+	}
+}
+
+func TestSelector(t *testing.T) {
+	p, err := NewProbe(nil, nil, "canary", prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := p.Selector(); got != `{probe="canary"}` {
+		t.Errorf("got selector %s", got)
+	}
+}
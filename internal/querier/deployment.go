@@ -2,105 +2,228 @@ package querier
 
 import (
 	"fmt"
+	"path"
 	"strings"
 
 	"github.com/observatorium/loki-benchmarks/internal/config"
+	"github.com/observatorium/loki-benchmarks/internal/loadclient"
+	"github.com/observatorium/loki-benchmarks/internal/loadmodel"
+	"github.com/observatorium/loki-benchmarks/internal/querygen"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
-	DefaultImage = "docker.io/grafana/logcli:2.7.3-amd64"
+	// DefaultImage ships the loki-querygen binary next to loki-loadgen.
+	DefaultImage = loadclient.LoadGenImage
+
+	queriesKey  = "queries.yaml"
+	queriesPath = "/etc/loki-querygen"
+	queryGenBin = "/usr/local/bin/loki-querygen"
+
+	serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
 )
 
-func CreateQueriers(reader *config.Reader, cfg *config.Querier) []client.Object {
+// CreateQueriers returns a ConfigMap holding the query set and a
+// loki-querygen deployment for every query of the reader and for the query
+// mix.
+func CreateQueriers(reader *config.Reader, cfg *config.Querier) ([]client.Object, error) {
 	image := DefaultImage
 	if cfg.Image != "" {
 		image = cfg.Image
 	}
 
-	var dpls []client.Object
-	for id, query := range reader.Queries {
-		dpls = append(dpls, NewLogCLIDeployment(
-			fmt.Sprintf("%s-querier", strings.ToLower(id)),
-			cfg.Namespace, image, cfg.ServiceAccount, cfg.PullURL, cfg.Tenant, query, reader.QueryRange,
-			reader.Replicas,
-		),
-		)
+	sets, err := querySets(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var objs []client.Object
+	for id, set := range sets {
+		name := fmt.Sprintf("%s-querier", id)
+		if cfg.Tenant != "" {
+			name = fmt.Sprintf("%s-%s", strings.ToLower(cfg.Tenant), name)
+		}
+
+		cm, err := NewQuerySetConfigMap(name, cfg.Namespace, set)
+		if err != nil {
+			return nil, err
+		}
+
+		dpl := NewQueryGenDeployment(name, cfg.Namespace, image, cfg.ServiceAccount, cfg.PullURL, cfg.Tenant, readerArgs(reader), reader.Replicas)
+		objs = append(objs, cm, dpl)
 	}
 
-	return dpls
+	return objs, nil
 }
 
-func NewLogCLIDeployment(
-	name, namespace, image, serviceAccount, clientURL, tenantID, query, window string,
-	replicas int32,
-) *appsv1.Deployment {
-	spec := corev1.PodSpec{
-		Containers: []corev1.Container{
-			{
-				Name:  "logcli",
-				Image: image,
-				Command: []string{
-					"/bin/sh",
-				},
-				Args: []string{
-					"-c",
-					fmt.Sprintf(`while true; do logcli query '%s' --since=%s; sleep 10; done`, query, window),
-				},
-				Env: []corev1.EnvVar{
-					{
-						Name:  "LOKI_ORG_ID",
-						Value: tenantID,
-					},
+// mixID is the id of the query set of the query mix.
+const mixID = "mix"
+
+// querySets returns the query set of every query of the reader and of the
+// query mix by their lowercase id, which names the queriers. Query ids that
+// only differ in case or clash with the query mix are rejected.
+func querySets(reader *config.Reader) (map[string]*querygen.QuerySet, error) {
+	sets := map[string]*querygen.QuerySet{}
+	ids := map[string]string{}
+	if len(reader.Mix) > 0 {
+		ids[mixID] = mixID
+	}
+
+	if len(reader.Queries) > 0 {
+		window, err := reader.Range()
+		if err != nil {
+			return nil, err
+		}
+
+		for id, query := range reader.Queries {
+			key := strings.ToLower(id)
+			if other, ok := ids[key]; ok {
+				return nil, fmt.Errorf("queries %s and %s share the querier %s", id, other, key)
+			}
+			ids[key] = id
+
+			sets[key] = &querygen.QuerySet{
+				Jitter: reader.Jitter,
+				Queries: []querygen.Query{
 					{
-						Name:  "LOKI_ADDR",
-						Value: clientURL,
+						Name:  id,
+						Query: query,
+						Type:  reader.QueryType,
+						Range: window,
 					},
 				},
-			},
-		},
+			}
+		}
 	}
 
-	if serviceAccount != "" {
-		spec.ServiceAccountName = serviceAccount
-		spec.Containers[0].Env = append(spec.Containers[0].Env,
-			corev1.EnvVar{
-				Name:  "LOKI_BEARER_TOKEN_FILE",
-				Value: "/var/run/secrets/kubernetes.io/serviceaccount/token",
-			},
-			corev1.EnvVar{
-				Name:  "LOKI_CA_CERT_PATH",
-				Value: "/var/run/secrets/kubernetes.io/serviceaccount/service-ca.crt",
-			},
-		)
+	if len(reader.Mix) > 0 {
+		sets[mixID] = newMixQuerySet(reader)
+	}
+
+	for _, set := range sets {
+		if err := set.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return sets, nil
+}
+
+func newMixQuerySet(reader *config.Reader) *querygen.QuerySet {
+	set := &querygen.QuerySet{Variables: reader.Variables, Jitter: reader.Jitter}
+
+	for _, q := range reader.Mix {
+		set.Queries = append(set.Queries, querygen.Query{
+			Name:   q.Name,
+			Query:  q.Query,
+			Type:   q.Type,
+			Weight: q.Weight,
+			Range:  q.Range,
+			Step:   q.Step,
+			Limit:  q.Limit,
+			Label:  q.Label,
+		})
 	}
 
-	labels := map[string]string{
-		"app": "loki-benchmarks-querier",
+	for _, r := range reader.Ranges {
+		set.Ranges = append(set.Ranges, querygen.WeightedRange{Range: r.Range, Weight: r.Weight})
 	}
 
-	return &appsv1.Deployment{
+	return set
+}
+
+func NewQuerySetConfigMap(name, namespace string, set *querygen.QuerySet) (*corev1.ConfigMap, error) {
+	data, err := set.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels:    labels,
-		},
-		Spec: appsv1.DeploymentSpec{
-			Replicas: pointer.Int32(replicas),
-			Selector: &metav1.LabelSelector{
-				MatchLabels: labels,
-			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: labels,
-				},
-				Spec: spec,
+			Labels: map[string]string{
+				"app": "loki-benchmarks-querier",
 			},
 		},
+		Data: map[string]string{
+			queriesKey: data,
+		},
+	}, nil
+}
+
+func readerArgs(reader *config.Reader) []string {
+	var args []string
+
+	if qps := reader.Rate(); qps > 0 {
+		args = append(args, fmt.Sprintf("--%s=%g", "qps", qps))
 	}
+
+	if reader.IsOpenLoop() {
+		args = append(args, fmt.Sprintf("--%s=%s", "loop", loadmodel.LoopOpen))
+		if reader.Arrival != "" {
+			args = append(args, fmt.Sprintf("--%s=%s", "arrival", reader.Arrival))
+		}
+		if reader.Concurrency > 0 {
+			args = append(args, fmt.Sprintf("--%s=%d", "max-inflight", reader.Concurrency))
+		}
+	} else if reader.Concurrency > 0 {
+		args = append(args, fmt.Sprintf("--%s=%d", "concurrency", reader.Concurrency))
+	}
+
+	return args
+}
+
+// NewQueryGenDeployment returns a deployment running loki-querygen with the
+// query set of the ConfigMap of the same name.
+func NewQueryGenDeployment(
+	name, namespace, image, serviceAccount, clientURL, tenantID string,
+	extraArgs []string,
+	replicas int32,
+) *appsv1.Deployment {
+	args := []string{
+		fmt.Sprintf("--%s=%s", "url", clientURL),
+		fmt.Sprintf("--%s=%s", "tenant", tenantID),
+		fmt.Sprintf("--%s=%s", "queries-file", path.Join(queriesPath, queriesKey)),
+	}
+	args = append(args, extraArgs...)
+
+	if serviceAccount != "" {
+		args = append(args, fmt.Sprintf("--%s=%s", "bearer-token-file", serviceAccountTokenFile))
+	}
+
+	dpl := loadclient.NewLoadClientDeployment(name, namespace, image, serviceAccount, args, replicas)
+	// The labels map is shared by the deployment, its selector and the pod template.
+	delete(dpl.Labels, "generator")
+	dpl.Labels["app"] = "loki-benchmarks-querier"
+	dpl.Labels["querier"] = name
+
+	mountQuerySet(&dpl.Spec.Template.Spec, name)
+	loadclient.ExposeMetrics(&dpl.Spec.Template)
+
+	return dpl
+}
+
+// mountQuerySet runs the loki-querygen binary of the pod with the query set
+// of the ConfigMap of the given name.
+func mountQuerySet(spec *corev1.PodSpec, name string) {
+	spec.Containers[0].Name = "querygen"
+	spec.Containers[0].Command = []string{queryGenBin}
+	spec.Containers[0].VolumeMounts = append(spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      "queries",
+		MountPath: queriesPath,
+		ReadOnly:  true,
+	})
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: "queries",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			},
+		},
+	})
 }
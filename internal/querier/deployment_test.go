@@ -0,0 +1,135 @@
+package querier
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+	"github.com/observatorium/loki-benchmarks/internal/querygen"
+)
+
+func TestQuerySets(t *testing.T) {
+	mix := []*config.MixQuery{{Name: "logs", Query: `{job="app"}`, Range: time.Hour}}
+
+	tests := []struct {
+		name   string
+		reader *config.Reader
+		want   []string
+		err    string
+	}{
+		{
+			name:   "queries and mix",
+			reader: &config.Reader{Queries: map[string]string{"sumRateByLevel": `sum(rate({job="app"}[1m]))`}, QueryRange: "1h", Mix: mix},
+			want:   []string{"mix", "sumratebylevel"},
+		},
+		{
+			name:   "query named mix without a mix",
+			reader: &config.Reader{Queries: map[string]string{"mix": `{job="app"}`}, QueryRange: "1h"},
+			want:   []string{"mix"},
+		},
+		{
+			name:   "query named mix",
+			reader: &config.Reader{Queries: map[string]string{"Mix": `{job="app"}`}, QueryRange: "1h", Mix: mix},
+			err:    "share the querier mix",
+		},
+		{
+			name:   "ids differing in case",
+			reader: &config.Reader{Queries: map[string]string{"errors": `{job="app"} |= "error"`, "Errors": `{job="app"} |= "Error"`}, QueryRange: "1h"},
+			err:    "share the querier errors",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sets, err := querySets(tt.reader)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []string
+			for id := range sets {
+				got = append(got, id)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got query sets %v, want %v", got, tt.want)
+			}
+			for _, id := range tt.want {
+				if _, ok := sets[id]; !ok {
+					t.Errorf("missing query set %s in %v", id, got)
+				}
+			}
+		})
+	}
+}
+
+// TestNewMixQuerySet fails when a field is added to the mix of the reader
+// config without copying it into the query set.
+func TestNewMixQuerySet(t *testing.T) {
+	q := &config.MixQuery{}
+	setFields(t, reflect.ValueOf(q).Elem())
+	r := &config.WeightedRange{}
+	setFields(t, reflect.ValueOf(r).Elem())
+
+	set := newMixQuerySet(&config.Reader{Mix: []*config.MixQuery{q}, Ranges: []*config.WeightedRange{r}})
+	if len(set.Queries) != 1 || len(set.Ranges) != 1 {
+		t.Fatalf("got query set %+v", set)
+	}
+
+	assertFields(t, reflect.ValueOf(*q), reflect.ValueOf(set.Queries[0]))
+	assertFields(t, reflect.ValueOf(*r), reflect.ValueOf(set.Ranges[0]))
+
+	// The query set must not drop what the reader config cannot express.
+	if n, m := reflect.TypeOf(querygen.Query{}).NumField(), reflect.TypeOf(*q).NumField(); n != m {
+		t.Errorf("querygen.Query has %d fields, config.MixQuery %d", n, m)
+	}
+	if n, m := reflect.TypeOf(querygen.WeightedRange{}).NumField(), reflect.TypeOf(*r).NumField(); n != m {
+		t.Errorf("querygen.WeightedRange has %d fields, config.WeightedRange %d", n, m)
+	}
+}
+
+// setFields sets every field of v to a distinct non-zero value.
+func setFields(t *testing.T, v reflect.Value) {
+	t.Helper()
+
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.String:
+			f.SetString(v.Type().Field(i).Name)
+		case reflect.Int, reflect.Int64:
+			f.SetInt(int64(i + 1))
+		default:
+			t.Fatalf("unsupported field %s of kind %s", v.Type().Field(i).Name, f.Kind())
+		}
+	}
+}
+
+// assertFields compares every field of want with the field of the same name
+// and yaml tag in got.
+func assertFields(t *testing.T, want, got reflect.Value) {
+	t.Helper()
+
+	for i := 0; i < want.NumField(); i++ {
+		field := want.Type().Field(i)
+
+		gotField, ok := got.Type().FieldByName(field.Name)
+		if !ok {
+			t.Errorf("%s has no field %s", got.Type(), field.Name)
+			continue
+		}
+		if gotField.Tag.Get("yaml") != field.Tag.Get("yaml") {
+			t.Errorf("field %s: got yaml tag %q, want %q", field.Name, gotField.Tag.Get("yaml"), field.Tag.Get("yaml"))
+		}
+		if g, w := got.FieldByName(field.Name).Interface(), want.Field(i).Interface(); g != w {
+			t.Errorf("field %s: got %v, want %v", field.Name, g, w)
+		}
+	}
+}
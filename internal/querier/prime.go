@@ -0,0 +1,61 @@
+package querier
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+	"github.com/observatorium/loki-benchmarks/internal/loadclient"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CreatePrimers returns a ConfigMap holding the query set and a
+// loki-querygen job sending every query once for every query of the reader
+// and for the query mix. The jobs complete once all queries returned, so
+// that the caches hold their results.
+func CreatePrimers(reader *config.Reader, cfg *config.Querier) ([]client.Object, error) {
+	image := DefaultImage
+	if cfg.Image != "" {
+		image = cfg.Image
+	}
+
+	sets, err := querySets(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var objs []client.Object
+	for id, set := range sets {
+		name := fmt.Sprintf("%s-primer", id)
+		if cfg.Tenant != "" {
+			name = fmt.Sprintf("%s-%s", strings.ToLower(cfg.Tenant), name)
+		}
+
+		cm, err := NewQuerySetConfigMap(name, cfg.Namespace, set)
+		if err != nil {
+			return nil, err
+		}
+
+		args := []string{
+			"--once",
+			fmt.Sprintf("--%s=%s", "url", cfg.PullURL),
+			fmt.Sprintf("--%s=%s", "tenant", cfg.Tenant),
+			fmt.Sprintf("--%s=%s", "queries-file", path.Join(queriesPath, queriesKey)),
+			fmt.Sprintf("--%s=%s", "metrics-addr", ""),
+		}
+		if cfg.ServiceAccount != "" {
+			args = append(args, fmt.Sprintf("--%s=%s", "bearer-token-file", serviceAccountTokenFile))
+		}
+
+		job := loadclient.NewLoadClientJob(name, cfg.Namespace, image, cfg.ServiceAccount, args, 1)
+		// The labels map is shared by the job and its pod template.
+		job.Labels["app"] = "loki-benchmarks-querier"
+
+		mountQuerySet(&job.Spec.Template.Spec, name)
+		objs = append(objs, cm, job)
+	}
+
+	return objs, nil
+}
@@ -0,0 +1,118 @@
+package querier
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+	"github.com/observatorium/loki-benchmarks/internal/loadclient"
+	"github.com/observatorium/loki-benchmarks/internal/ruler"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	rulesKey  = "rules.yaml"
+	rulesPath = "/etc/loki-rules"
+	rulesBin  = "/usr/local/bin/loki-rules"
+)
+
+// CreateRuleLoader returns a ConfigMap holding the rule set and a loki-rules
+// deployment loading the generated rules until it is deleted.
+func CreateRuleLoader(scenario *config.Ruler, cfg *config.Querier) ([]client.Object, error) {
+	image := DefaultImage
+	if cfg.Image != "" {
+		image = cfg.Image
+	}
+
+	set := &ruler.RuleSet{
+		Namespace:     scenario.RuleNamespace(),
+		Groups:        scenario.Groups,
+		RulesPerGroup: scenario.RulesPerGroup,
+		Interval:      scenario.Interval,
+		Variables:     scenario.Variables,
+	}
+	for _, t := range scenario.Templates {
+		set.Templates = append(set.Templates, ruler.Template{
+			Record: t.Record,
+			Alert:  t.Alert,
+			Expr:   t.Expr,
+			For:    t.For,
+			Labels: t.Labels,
+		})
+	}
+
+	if err := set.Validate(); err != nil {
+		return nil, err
+	}
+
+	data, err := set.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	name := "rules-loader"
+	if cfg.Tenant != "" {
+		name = fmt.Sprintf("%s-%s", strings.ToLower(cfg.Tenant), name)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cfg.Namespace,
+			Labels: map[string]string{
+				"app": "loki-benchmarks-querier",
+			},
+		},
+		Data: map[string]string{
+			rulesKey: data,
+		},
+	}
+
+	url := cfg.PullURL
+	if scenario.URL != "" {
+		url = scenario.URL
+	}
+
+	args := []string{
+		fmt.Sprintf("--%s=%s", "url", url),
+		fmt.Sprintf("--%s=%s", "tenant", cfg.Tenant),
+		fmt.Sprintf("--%s=%s", "rules-file", path.Join(rulesPath, rulesKey)),
+	}
+
+	if scenario.ConfigMap != nil {
+		args = append(args,
+			fmt.Sprintf("--%s=%s", "configmap", scenario.ConfigMap.Name),
+			fmt.Sprintf("--%s=%s", "configmap-namespace", scenario.ConfigMap.Namespace),
+		)
+	}
+	if cfg.ServiceAccount != "" {
+		args = append(args, fmt.Sprintf("--%s=%s", "bearer-token-file", serviceAccountTokenFile))
+	}
+
+	dpl := loadclient.NewLoadClientDeployment(name, cfg.Namespace, image, cfg.ServiceAccount, args, 1)
+	// The labels map is shared by the deployment, its selector and the pod template.
+	dpl.Labels["app"] = "loki-benchmarks-rules"
+
+	spec := &dpl.Spec.Template.Spec
+	spec.Containers[0].Name = "rules"
+	spec.Containers[0].Command = []string{rulesBin}
+	spec.Containers[0].VolumeMounts = append(spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      "rules",
+		MountPath: rulesPath,
+		ReadOnly:  true,
+	})
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: "rules",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			},
+		},
+	})
+
+	return []client.Object{cm, dpl}, nil
+}
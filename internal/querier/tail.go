@@ -0,0 +1,53 @@
+package querier
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+	"github.com/observatorium/loki-benchmarks/internal/querygen"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CreateTailers returns a ConfigMap holding the tail queries and a
+// loki-querygen deployment keeping the websocket tails open.
+func CreateTailers(tailer *config.Tailer, cfg *config.Querier) ([]client.Object, error) {
+	image := DefaultImage
+	if cfg.Image != "" {
+		image = cfg.Image
+	}
+
+	set := &querygen.QuerySet{}
+	for id, query := range tailer.Queries {
+		set.Queries = append(set.Queries, querygen.Query{
+			Name:  id,
+			Query: query,
+			Type:  querygen.QueryTypeTail,
+			Limit: tailer.Limit,
+		})
+	}
+
+	if err := set.Validate(); err != nil {
+		return nil, err
+	}
+
+	name := "tailer"
+	if cfg.Tenant != "" {
+		name = fmt.Sprintf("%s-%s", strings.ToLower(cfg.Tenant), name)
+	}
+
+	cm, err := NewQuerySetConfigMap(name, cfg.Namespace, set)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{fmt.Sprintf("--%s=%d", "tails", tailer.Connections)}
+	if tailer.DelayFor > 0 {
+		args = append(args, fmt.Sprintf("--%s=%s", "tail-delay-for", tailer.DelayFor))
+	}
+
+	dpl := NewQueryGenDeployment(name, cfg.Namespace, image, cfg.ServiceAccount, cfg.PullURL, cfg.Tenant, args, tailer.Replicas)
+
+	return []client.Object{cm, dpl}, nil
+}
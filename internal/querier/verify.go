@@ -0,0 +1,64 @@
+package querier
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+	"github.com/observatorium/loki-benchmarks/internal/loadclient"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CreateVerifier returns a loki-querygen deployment verifying the query
+// results over the deterministic dataset pushed since start. It does not
+// need a query set.
+func CreateVerifier(verification *config.Verification, cfg *config.Querier, start time.Time) client.Object {
+	image := DefaultImage
+	if cfg.Image != "" {
+		image = cfg.Image
+	}
+
+	name := "verifier"
+	if cfg.Tenant != "" {
+		name = fmt.Sprintf("%s-%s", strings.ToLower(cfg.Tenant), name)
+	}
+
+	args := []string{
+		"--verify",
+		fmt.Sprintf("--%s=%s", "url", cfg.PullURL),
+		fmt.Sprintf("--%s=%s", "tenant", cfg.Tenant),
+		fmt.Sprintf("--%s=%d", "dataset-seed", verification.Seed),
+		fmt.Sprintf("--%s=%d", "dataset-streams", verification.Streams),
+		fmt.Sprintf("--%s=%s", "dataset-start", start.UTC().Format(time.RFC3339)),
+	}
+
+	if verification.LinesPerSecond > 0 {
+		args = append(args, fmt.Sprintf("--%s=%d", "dataset-lines-per-second", verification.LinesPerSecond))
+	}
+	if verification.Window > 0 {
+		args = append(args, fmt.Sprintf("--%s=%s", "verify-window", verification.Window))
+	}
+	if verification.Lag > 0 {
+		args = append(args, fmt.Sprintf("--%s=%s", "verify-lag", verification.Lag))
+	}
+	if verification.Interval > 0 {
+		args = append(args, fmt.Sprintf("--%s=%s", "verify-interval", verification.Interval))
+	}
+	if cfg.ServiceAccount != "" {
+		args = append(args, fmt.Sprintf("--%s=%s", "bearer-token-file", serviceAccountTokenFile))
+	}
+
+	dpl := loadclient.NewLoadClientDeployment(name, cfg.Namespace, image, cfg.ServiceAccount, args, 1)
+	// The labels map is shared by the deployment, its selector and the pod template.
+	dpl.Labels["app"] = "loki-benchmarks-querier"
+
+	spec := &dpl.Spec.Template.Spec
+	spec.Containers[0].Name = "querygen"
+	spec.Containers[0].Command = []string{queryGenBin}
+
+	loadclient.ExposeMetrics(&dpl.Spec.Template)
+
+	return dpl
+}
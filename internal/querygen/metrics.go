@@ -0,0 +1,71 @@
+package querygen
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	queriesScheduled *prometheus.CounterVec
+	queriesDropped   *prometheus.CounterVec
+	queryDuration    *prometheus.HistogramVec
+	resultEntries    *prometheus.HistogramVec
+	bytesProcessed   *prometheus.HistogramVec
+}
+
+// newMetrics registers the per query metrics. The tenant is added as a
+// constant label by the registerer of the caller.
+func newMetrics(reg prometheus.Registerer) (*metrics, error) {
+	m := &metrics{
+		queriesScheduled: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "loki_benchmarks",
+				Name:      "queries_scheduled_total",
+				Help:      "Number of queries the client intended to send.",
+			},
+			[]string{"query"},
+		),
+		queriesDropped: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "loki_benchmarks",
+				Name:      "queries_dropped_total",
+				Help:      "Number of scheduled queries not sent because too many queries were in flight.",
+			},
+			[]string{"query"},
+		),
+		queryDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "loki_benchmarks",
+				Name:      "query_duration_seconds",
+				Help:      "Client side latency of the queries by query name and status. In an open loop it is measured from the scheduled send time.",
+				Buckets:   prometheus.ExponentialBuckets(0.005, 2, 15),
+			},
+			[]string{"query", "status"},
+		),
+		resultEntries: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "loki_benchmarks",
+				Name:      "query_result_entries",
+				Help:      "Number of log lines, samples, label values or series returned by the queries.",
+				Buckets:   prometheus.ExponentialBuckets(1, 4, 12),
+			},
+			[]string{"query"},
+		),
+		bytesProcessed: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "loki_benchmarks",
+				Name:      "query_bytes_processed",
+				Help:      "Bytes processed by Loki for the queries as reported in the query statistics, or the bytes matched by index stats queries.",
+				Buckets:   prometheus.ExponentialBuckets(1024, 4, 14),
+			},
+			[]string{"query"},
+		),
+	}
+
+	for _, c := range []prometheus.Collector{m.queriesScheduled, m.queriesDropped, m.queryDuration, m.resultEntries, m.bytesProcessed} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
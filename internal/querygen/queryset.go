@@ -0,0 +1,146 @@
+package querygen
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The query types cover the read API of Loki. Range, instant and tail
+// queries run LogQL, the others take the query as stream selector. Tail
+// queries are only run by the Tailer.
+const (
+	QueryTypeRange       = "range"
+	QueryTypeInstant     = "instant"
+	QueryTypeLabels      = "labels"
+	QueryTypeLabelValues = "label_values"
+	QueryTypeSeries      = "series"
+	QueryTypeIndexStats  = "index_stats"
+	QueryTypeVolume      = "volume"
+	QueryTypeVolumeRange = "volume_range"
+	QueryTypeTail        = "tail"
+)
+
+// QuerySet is the query mix executed by loki-querygen. It is read from a
+// file, usually mounted from a ConfigMap.
+//
+// Every query is picked with a probability proportional to its weight. Range
+// queries without a range of their own draw it from the weighted Ranges.
+// Variables maps template variables to label names, e.g. namespace to
+// kubernetes_namespace_name. Every $namespace or ${namespace} in a query is
+// replaced by a random value of the label, as returned by the labels API.
+// With a Jitter, every query ends a random duration of up to Jitter before
+// now, so that it rarely matches results cached for an earlier query.
+type QuerySet struct {
+	Queries   []Query           `yaml:"queries"`
+	Ranges    []WeightedRange   `yaml:"ranges,omitempty"`
+	Variables map[string]string `yaml:"variables,omitempty"`
+	Jitter    time.Duration     `yaml:"jitter,omitempty"`
+}
+
+type Query struct {
+	Name   string        `yaml:"name"`
+	Query  string        `yaml:"query"`
+	Type   string        `yaml:"type,omitempty"`
+	Weight int           `yaml:"weight,omitempty"`
+	Range  time.Duration `yaml:"range,omitempty"`
+	Step   time.Duration `yaml:"step,omitempty"`
+	Limit  int           `yaml:"limit,omitempty"`
+	Label  string        `yaml:"label,omitempty"`
+}
+
+func (q *Query) queryType() string {
+	if q.Type == "" {
+		return QueryTypeRange
+	}
+	return q.Type
+}
+
+type WeightedRange struct {
+	Range  time.Duration `yaml:"range"`
+	Weight int           `yaml:"weight"`
+}
+
+// QueryWeight returns the weight of the query, which defaults to 1.
+func (q *Query) QueryWeight() int {
+	if q.Weight <= 0 {
+		return 1
+	}
+	return q.Weight
+}
+
+func LoadQuerySet(path string) (*QuerySet, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading query set: %w", err)
+	}
+
+	set := &QuerySet{}
+	if err := yaml.Unmarshal(b, set); err != nil {
+		return nil, fmt.Errorf("failed decoding query set: %w", err)
+	}
+
+	if err := set.Validate(); err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+func (s *QuerySet) Validate() error {
+	if len(s.Queries) == 0 {
+		return fmt.Errorf("query set contains no queries")
+	}
+
+	if s.Jitter < 0 {
+		return fmt.Errorf("jitter must not be negative")
+	}
+
+	for _, r := range s.Ranges {
+		if r.Range <= 0 || r.Weight <= 0 {
+			return fmt.Errorf("ranges require a positive range and weight")
+		}
+	}
+
+	for i, q := range s.Queries {
+		if q.Name == "" {
+			return fmt.Errorf("query %d: name is required", i)
+		}
+
+		switch q.Type {
+		case QueryTypeTail:
+			if q.Query == "" {
+				return fmt.Errorf("query %s: query is required", q.Name)
+			}
+			continue
+		case "", QueryTypeRange, QueryTypeInstant, QueryTypeSeries, QueryTypeIndexStats, QueryTypeVolume, QueryTypeVolumeRange:
+			if q.Query == "" {
+				return fmt.Errorf("query %s: query is required", q.Name)
+			}
+		case QueryTypeLabelValues:
+			if q.Label == "" {
+				return fmt.Errorf("query %s: label values queries require a label", q.Name)
+			}
+		case QueryTypeLabels:
+		default:
+			return fmt.Errorf("query %s: unsupported type %q", q.Name, q.Type)
+		}
+
+		if q.Type != QueryTypeInstant && q.Range <= 0 && len(s.Ranges) == 0 {
+			return fmt.Errorf("query %s: %s queries require a positive range", q.Name, q.queryType())
+		}
+	}
+
+	return nil
+}
+
+func (s *QuerySet) Marshal() (string, error) {
+	b, err := yaml.Marshal(s)
+	if err != nil {
+		return "", fmt.Errorf("failed encoding query set: %w", err)
+	}
+
+	return string(b), nil
+}
@@ -0,0 +1,130 @@
+package querygen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuerySetValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		set  QuerySet
+		err  string
+	}{
+		{
+			name: "range query",
+			set:  QuerySet{Queries: []Query{{Name: "a", Query: `{job="a"}`, Range: time.Hour}}},
+		},
+		{
+			name: "instant query without range",
+			set:  QuerySet{Queries: []Query{{Name: "a", Query: `count_over_time({job="a"}[1m])`, Type: QueryTypeInstant}}},
+		},
+		{
+			name: "no queries",
+			set:  QuerySet{},
+			err:  "query set contains no queries",
+		},
+		{
+			name: "missing name",
+			set:  QuerySet{Queries: []Query{{Query: `{job="a"}`, Range: time.Hour}}},
+			err:  "query 0: name",
+		},
+		{
+			name: "missing query",
+			set:  QuerySet{Queries: []Query{{Name: "a", Range: time.Hour}}},
+			err:  "query a: query is required",
+		},
+		{
+			name: "missing range",
+			set:  QuerySet{Queries: []Query{{Name: "a", Query: `{job="a"}`}}},
+			err:  "query a: range queries require a positive range",
+		},
+		{
+			name: "unsupported type",
+			set:  QuerySet{Queries: []Query{{Name: "a", Query: `{job="a"}`, Type: "explain", Range: time.Hour}}},
+			err:  `query a: unsupported type "explain"`,
+		},
+		{
+			name: "range drawn from the ranges",
+			set: QuerySet{
+				Queries: []Query{{Name: "a", Query: `{job="a"}`}},
+				Ranges:  []WeightedRange{{Range: time.Hour, Weight: 1}},
+			},
+		},
+		{
+			name: "range without weight",
+			set: QuerySet{
+				Queries: []Query{{Name: "a", Query: `{job="a"}`}},
+				Ranges:  []WeightedRange{{Range: time.Hour}},
+			},
+			err: "ranges require a positive range and weight",
+		},
+		{
+			name: "labels query without selector",
+			set:  QuerySet{Queries: []Query{{Name: "a", Type: QueryTypeLabels, Range: time.Hour}}},
+		},
+		{
+			name: "label values query without label",
+			set:  QuerySet{Queries: []Query{{Name: "a", Type: QueryTypeLabelValues, Range: time.Hour}}},
+			err:  "query a: label values queries require a label",
+		},
+		{
+			name: "series query without range",
+			set:  QuerySet{Queries: []Query{{Name: "a", Query: `{job="a"}`, Type: QueryTypeSeries}}},
+			err:  "query a: series queries require a positive range",
+		},
+		{
+			name: "tail query without range",
+			set:  QuerySet{Queries: []Query{{Name: "a", Query: `{job="a"}`, Type: QueryTypeTail}}},
+		},
+		{
+			name: "negative jitter",
+			set:  QuerySet{Queries: []Query{{Name: "a", Query: `{job="a"}`, Range: time.Hour}}, Jitter: -time.Second},
+			err:  "jitter must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.set.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestQuerySetMarshal(t *testing.T) {
+	set := &QuerySet{
+		Queries:   []Query{{Name: "a", Query: `{job="a"}`, Weight: 2}},
+		Ranges:    []WeightedRange{{Range: time.Hour, Weight: 1}},
+		Variables: map[string]string{"namespace": "kubernetes_namespace_name"},
+	}
+
+	out, err := set.Marshal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "queries.yaml")
+	if err := os.WriteFile(path, []byte(out), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadQuerySet(path)
+	if err != nil {
+		t.Fatalf("failed loading query set: %v", err)
+	}
+	if got.Queries[0].QueryWeight() != 2 || got.Ranges[0].Range != time.Hour || got.Variables["namespace"] != "kubernetes_namespace_name" {
+		t.Errorf("got %+v after a round trip", got)
+	}
+}
@@ -0,0 +1,374 @@
+package querygen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/loadmodel"
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Runner executes the queries of a query set picked by their weight.
+type Runner struct {
+	client    *loki.Client
+	set       *QuerySet
+	variables *variables
+	metrics   *metrics
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+func NewRunner(client *loki.Client, set *QuerySet, reg prometheus.Registerer) (*Runner, error) {
+	m, err := newMetrics(reg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Runner{
+		client:    client,
+		set:       set,
+		variables: newVariables(client, set.Variables),
+		metrics:   m,
+		rnd:       rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec
+	}, nil
+}
+
+// LoadVariables fetches the values of the template variables seen within
+// lookback and refreshes them every interval until the context is done.
+func (r *Runner) LoadVariables(ctx context.Context, interval, lookback time.Duration) error {
+	if len(r.set.Variables) == 0 {
+		return nil
+	}
+
+	if err := r.variables.refresh(ctx, lookback); err != nil {
+		return err
+	}
+
+	go r.variables.refreshEvery(ctx, interval, lookback)
+	return nil
+}
+
+// RunConcurrency keeps n queries in flight, each worker sending its next
+// query as soon as the previous one returned.
+func (r *Runner) RunConcurrency(ctx context.Context, n int) error {
+	log.Printf("running %d queries with a concurrency of %d", len(r.set.Queries), n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				q := r.nextQuery()
+				r.metrics.queriesScheduled.WithLabelValues(q.Name).Inc()
+				r.execute(ctx, q, time.Now())
+			}
+		}()
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// RunQPS sends queries at the target rate with at most maxConcurrency of
+// them in flight. Once the limit is reached the next query waits for a free
+// slot and the slots missed meanwhile are dropped, i.e. a slow Loki lowers
+// the achieved rate.
+func (r *Runner) RunQPS(ctx context.Context, qps float64, maxConcurrency int) error {
+	log.Printf("running %d queries at %.2f queries per second in a closed loop", len(r.set.Queries), qps)
+
+	interval := time.Duration(float64(time.Second) / qps)
+	slots := make(chan struct{}, maxConcurrency)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for next := time.Now(); ; {
+		if !sleepUntil(ctx, next) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case slots <- struct{}{}:
+		}
+
+		q := r.nextQuery()
+		r.metrics.queriesScheduled.WithLabelValues(q.Name).Inc()
+
+		for next = next.Add(interval); time.Now().After(next); next = next.Add(interval) {
+			missed := r.nextQuery()
+			r.metrics.queriesScheduled.WithLabelValues(missed.Name).Inc()
+			r.metrics.queriesDropped.WithLabelValues(missed.Name).Inc()
+		}
+
+		wg.Add(1)
+		go func(q Query) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			r.execute(ctx, q, time.Now())
+		}(q)
+	}
+}
+
+// RunOpenLoop sends queries at the target rate regardless of the latency of
+// Loki. The gaps between two queries are either constant or exponentially
+// distributed (Poisson arrivals). The latency is measured from the scheduled
+// send time, so that queueing in the client is not hidden (coordinated
+// omission). Queries scheduled while maxInflight queries are in flight are
+// dropped.
+func (r *Runner) RunOpenLoop(ctx context.Context, qps float64, arrival string, maxInflight int) error {
+	log.Printf("running %d queries at %.2f queries per second in an open loop with %s arrivals", len(r.set.Queries), qps, arrival)
+
+	gap := func() time.Duration {
+		if arrival == loadmodel.ArrivalPoisson {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			return time.Duration(r.rnd.ExpFloat64() / qps * float64(time.Second))
+		}
+		return time.Duration(float64(time.Second) / qps)
+	}
+
+	slots := make(chan struct{}, maxInflight)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for next := time.Now(); ; next = next.Add(gap()) {
+		if !sleepUntil(ctx, next) {
+			return nil
+		}
+
+		q := r.nextQuery()
+		r.metrics.queriesScheduled.WithLabelValues(q.Name).Inc()
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			r.metrics.queriesDropped.WithLabelValues(q.Name).Inc()
+			continue
+		}
+
+		wg.Add(1)
+		go func(q Query, scheduled time.Time) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			r.execute(ctx, q, scheduled)
+		}(q, next)
+	}
+}
+
+// RunOnce sends every query of the set once, one after the other, e.g. to
+// prime the caches. Queries without a range are sent once per range of the
+// set. It fails if any query failed.
+func (r *Runner) RunOnce(ctx context.Context) error {
+	var queries []Query
+	for _, q := range r.set.Queries {
+		if q.Range > 0 || len(r.set.Ranges) == 0 {
+			queries = append(queries, q)
+			continue
+		}
+
+		for _, wr := range r.set.Ranges {
+			q := q
+			q.Range = wr.Range
+			queries = append(queries, q)
+		}
+	}
+
+	log.Printf("running %d queries once", len(queries))
+
+	failed := 0
+	for _, q := range queries {
+		r.mu.Lock()
+		q.Query = r.variables.expand(q.Query, r.rnd)
+		r.mu.Unlock()
+
+		if _, _, err := r.do(ctx, q); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("query %s failed: %v", q.Name, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d queries failed", failed, len(queries))
+	}
+	return nil
+}
+
+// sleepUntil returns immediately if t is in the past and false if the
+// context is done first.
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// nextQuery picks a query by its weight, draws its range if it has none and
+// fills in the template variables.
+func (r *Runner) nextQuery() Query {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	total := 0
+	for i := range r.set.Queries {
+		total += r.set.Queries[i].QueryWeight()
+	}
+
+	n := r.rnd.Intn(total)
+	q := r.set.Queries[len(r.set.Queries)-1]
+	for i := range r.set.Queries {
+		if n -= r.set.Queries[i].QueryWeight(); n < 0 {
+			q = r.set.Queries[i]
+			break
+		}
+	}
+
+	if q.Range <= 0 && len(r.set.Ranges) > 0 {
+		q.Range = r.nextRange()
+	}
+	q.Query = r.variables.expand(q.Query, r.rnd)
+
+	return q
+}
+
+// nextJitter returns a random offset of the query end of up to the jitter of
+// the set. The offset has nanosecond precision, so that it is not aligned to
+// the step or the split interval of the cached results.
+func (r *Runner) nextJitter() time.Duration {
+	if r.set.Jitter <= 0 {
+		return 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return time.Duration(r.rnd.Int63n(int64(r.set.Jitter)))
+}
+
+func (r *Runner) nextRange() time.Duration {
+	total := 0
+	for _, wr := range r.set.Ranges {
+		total += wr.Weight
+	}
+
+	n := r.rnd.Intn(total)
+	for _, wr := range r.set.Ranges {
+		if n -= wr.Weight; n < 0 {
+			return wr.Range
+		}
+	}
+
+	return r.set.Ranges[len(r.set.Ranges)-1].Range
+}
+
+// execute runs the query and records its latency since scheduled.
+func (r *Runner) execute(ctx context.Context, q Query, scheduled time.Time) {
+	entries, bytes, err := r.do(ctx, q)
+	elapsed := time.Since(scheduled)
+
+	if ctx.Err() != nil {
+		// Do not record queries cancelled on shutdown.
+		return
+	}
+
+	r.metrics.queryDuration.WithLabelValues(q.Name, status(err)).Observe(elapsed.Seconds())
+	if err != nil {
+		log.Printf("query %s failed: %v", q.Name, err)
+		return
+	}
+
+	r.metrics.resultEntries.WithLabelValues(q.Name).Observe(float64(entries))
+	if bytes >= 0 {
+		r.metrics.bytesProcessed.WithLabelValues(q.Name).Observe(float64(bytes))
+	}
+}
+
+// do sends the query to the read API endpoint of its type. It returns the
+// number of entries of the result, e.g. log lines, samples, label values or
+// series, and the bytes processed or -1 if the endpoint does not report them.
+func (r *Runner) do(ctx context.Context, q Query) (int, int64, error) {
+	ctx = loki.WithQueryName(ctx, q.Name)
+	end := time.Now().Add(-r.nextJitter())
+	start := end.Add(-q.Range)
+
+	switch q.Type {
+	case QueryTypeLabels:
+		labels, err := r.client.Labels(ctx, q.Query, start, end)
+		return len(labels), -1, err
+	case QueryTypeLabelValues:
+		values, err := r.client.LabelValues(ctx, q.Label, q.Query, start, end)
+		return len(values), -1, err
+	case QueryTypeSeries:
+		series, err := r.client.Series(ctx, q.Query, start, end)
+		return len(series), -1, err
+	case QueryTypeIndexStats:
+		stats, err := r.client.IndexStats(ctx, q.Query, start, end)
+		if err != nil {
+			return 0, -1, err
+		}
+		return int(stats.Streams), stats.Bytes, nil
+	}
+
+	var (
+		res *loki.QueryResponse
+		err error
+	)
+
+	switch q.Type {
+	case QueryTypeInstant:
+		res, err = r.client.Query(ctx, q.Query, end, q.Limit)
+	case QueryTypeVolume:
+		res, err = r.client.Volume(ctx, q.Query, start, end, q.Limit)
+	case QueryTypeVolumeRange:
+		res, err = r.client.VolumeRange(ctx, q.Query, start, end, q.Step, q.Limit)
+	default:
+		res, err = r.client.QueryRange(ctx, q.Query, start, end, q.Step, q.Limit)
+	}
+	if err != nil {
+		return 0, -1, err
+	}
+
+	entries, err := res.Data.Entries()
+	if err != nil {
+		return 0, -1, fmt.Errorf("failed decoding result: %w", err)
+	}
+
+	return entries, res.Data.Stats.Summary.TotalBytesProcessed, nil
+}
+
+func status(err error) string {
+	if err == nil {
+		return "200"
+	}
+
+	var statusErr *loki.StatusError
+	if errors.As(err, &statusErr) {
+		return strconv.Itoa(statusErr.Code)
+	}
+
+	return "error"
+}
@@ -0,0 +1,167 @@
+package querygen
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newTestRunner(t *testing.T, addr string, set *QuerySet) *Runner {
+	t.Helper()
+
+	client, err := loki.NewClient(addr, "tenant", "", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewRunner(client, set, prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.rnd = rand.New(rand.NewSource(1)) //nolint:gosec
+
+	return r
+}
+
+func TestNextQueryWeights(t *testing.T) {
+	set := &QuerySet{
+		Queries: []Query{
+			{Name: "light", Query: `{job="a"}`, Range: time.Hour},
+			{Name: "heavy", Query: `{job="b"}`, Weight: 3},
+		},
+		Ranges: []WeightedRange{
+			{Range: time.Minute, Weight: 1},
+			{Range: 24 * time.Hour, Weight: 1},
+		},
+	}
+	r := newTestRunner(t, "http://localhost", set)
+
+	const n = 10000
+	names := map[string]int{}
+	ranges := map[time.Duration]int{}
+	for i := 0; i < n; i++ {
+		q := r.nextQuery()
+		names[q.Name]++
+		if q.Name == "light" && q.Range != time.Hour {
+			t.Fatalf("query with a range of its own got range %s", q.Range)
+		}
+		if q.Name == "heavy" {
+			ranges[q.Range]++
+		}
+	}
+
+	if got := float64(names["heavy"]) / n; math.Abs(got-0.75) > 0.03 {
+		t.Errorf("got heavy share %.3f, want 0.75", got)
+	}
+	if got := float64(ranges[time.Minute]) / float64(names["heavy"]); math.Abs(got-0.5) > 0.03 {
+		t.Errorf("got 1m range share %.3f, want 0.5", got)
+	}
+	if len(ranges) != 2 {
+		t.Errorf("got ranges %v, want 1m and 24h", ranges)
+	}
+}
+
+func TestNextJitter(t *testing.T) {
+	tests := []struct {
+		name   string
+		jitter time.Duration
+	}{
+		{name: "without jitter"},
+		{name: "with jitter", jitter: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := &QuerySet{Queries: []Query{{Name: "a", Query: `{job="a"}`, Range: time.Hour}}, Jitter: tt.jitter}
+			r := newTestRunner(t, "http://localhost", set)
+
+			varied := false
+			first := r.nextJitter()
+			for i := 0; i < 100; i++ {
+				j := r.nextJitter()
+				if j < 0 || (tt.jitter > 0 && j >= tt.jitter) || (tt.jitter == 0 && j != 0) {
+					t.Fatalf("got jitter %s out of [0, %s)", j, tt.jitter)
+				}
+				varied = varied || j != first
+			}
+			if tt.jitter > 0 && !varied {
+				t.Error("jitter does not vary")
+			}
+		})
+	}
+}
+
+func TestRunOnce(t *testing.T) {
+	set := &QuerySet{
+		Queries: []Query{
+			{Name: "logs", Query: `{job="a"}`, Range: time.Hour},
+			{Name: "rate", Query: `rate({job="a"}[1m])`},
+			{Name: "labels", Type: QueryTypeLabels, Range: time.Hour},
+		},
+		Ranges: []WeightedRange{
+			{Range: time.Minute, Weight: 1},
+			{Range: 24 * time.Hour, Weight: 1},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		status int
+		err    string
+	}{
+		{name: "all queries succeed", status: http.StatusOK},
+		{name: "queries fail", status: http.StatusBadRequest, err: "4 of 4 queries failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				requests []string
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				requests = append(requests, r.URL.Path+" "+r.Header.Get("X-Query-Tags"))
+				mu.Unlock()
+
+				if tt.status != http.StatusOK {
+					http.Error(w, "bad request", tt.status)
+					return
+				}
+				if r.URL.Path == loki.LabelsPath {
+					_, _ = w.Write([]byte(`{"status":"success","data":["job"]}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[]}}`))
+			}))
+			defer srv.Close()
+
+			err := newTestRunner(t, srv.URL, set).RunOnce(context.Background())
+			if tt.err == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+
+			want := []string{
+				loki.QueryRangePath + " Source=logs",
+				loki.QueryRangePath + " Source=rate",
+				loki.QueryRangePath + " Source=rate",
+				loki.LabelsPath + " Source=labels",
+			}
+			if strings.Join(requests, ",") != strings.Join(want, ",") {
+				t.Errorf("got requests %v, want %v", requests, want)
+			}
+		})
+	}
+}
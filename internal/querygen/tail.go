@@ -0,0 +1,175 @@
+package querygen
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	tailMinBackoff = time.Second
+	tailMaxBackoff = 30 * time.Second
+)
+
+type tailMetrics struct {
+	connections     *prometheus.GaugeVec
+	disconnects     *prometheus.CounterVec
+	entries         *prometheus.CounterVec
+	droppedEntries  *prometheus.CounterVec
+	deliveryLatency *prometheus.HistogramVec
+}
+
+func newTailMetrics(reg prometheus.Registerer) (*tailMetrics, error) {
+	m := &tailMetrics{
+		connections: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "loki_benchmarks",
+				Name:      "tail_connections",
+				Help:      "Number of open tail connections.",
+			},
+			[]string{"query"},
+		),
+		disconnects: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "loki_benchmarks",
+				Name:      "tail_disconnects_total",
+				Help:      "Number of tail connections that failed or were closed by Loki.",
+			},
+			[]string{"query"},
+		),
+		entries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "loki_benchmarks",
+				Name:      "tail_entries_total",
+				Help:      "Number of log lines received by the tails.",
+			},
+			[]string{"query"},
+		),
+		droppedEntries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "loki_benchmarks",
+				Name:      "tail_dropped_entries_total",
+				Help:      "Number of log lines Loki reported as dropped to the tails.",
+			},
+			[]string{"query"},
+		),
+		deliveryLatency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "loki_benchmarks",
+				Name:      "tail_delivery_latency_seconds",
+				Help:      "Time between the timestamp of a log line and its reception by the tail.",
+				Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+			},
+			[]string{"query"},
+		),
+	}
+
+	for _, c := range []prometheus.Collector{m.connections, m.disconnects, m.entries, m.droppedEntries, m.deliveryLatency} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// Tailer keeps websocket tails of the queries of a query set open.
+type Tailer struct {
+	client  *loki.Client
+	set     *QuerySet
+	metrics *tailMetrics
+}
+
+func NewTailer(client *loki.Client, set *QuerySet, reg prometheus.Registerer) (*Tailer, error) {
+	m, err := newTailMetrics(reg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tailer{client: client, set: set, metrics: m}, nil
+}
+
+// Run opens n tails spread round robin over the queries and reopens them
+// with a backoff when they fail, until the context is done. The delivery
+// latency is measured against the timestamps of the log lines. The
+// generator spreads them over the batch interval before each push, so the
+// latency includes up to one batch interval.
+func (t *Tailer) Run(ctx context.Context, n int, delayFor time.Duration) error {
+	log.Printf("running %d tails over %d queries", n, len(t.set.Queries))
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(q Query) {
+			defer wg.Done()
+			t.tail(ctx, q, delayFor)
+		}(t.set.Queries[i%len(t.set.Queries)])
+	}
+
+	wg.Wait()
+	return nil
+}
+
+func (t *Tailer) tail(ctx context.Context, q Query, delayFor time.Duration) {
+	ctx = loki.WithQueryName(ctx, q.Name)
+	backoff := tailMinBackoff
+
+	for ctx.Err() == nil {
+		connected := false
+		err := t.client.Tail(ctx, q.Query, delayFor, q.Limit,
+			func() {
+				connected = true
+				backoff = tailMinBackoff
+				t.metrics.connections.WithLabelValues(q.Name).Inc()
+			},
+			func(res *loki.TailResponse, received time.Time) {
+				t.observe(q.Name, res, received)
+			},
+		)
+		if connected {
+			t.metrics.connections.WithLabelValues(q.Name).Dec()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		t.metrics.disconnects.WithLabelValues(q.Name).Inc()
+		log.Printf("tail %s closed: %v", q.Name, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > tailMaxBackoff {
+			backoff = tailMaxBackoff
+		}
+	}
+}
+
+func (t *Tailer) observe(name string, res *loki.TailResponse, received time.Time) {
+	for _, stream := range res.Streams {
+		for _, value := range stream.Values {
+			if len(value) == 0 {
+				continue
+			}
+
+			t.metrics.entries.WithLabelValues(name).Inc()
+
+			ns, err := strconv.ParseInt(value[0], 10, 64)
+			if err != nil {
+				continue
+			}
+			t.metrics.deliveryLatency.WithLabelValues(name).Observe(received.Sub(time.Unix(0, ns)).Seconds())
+		}
+	}
+
+	t.metrics.droppedEntries.WithLabelValues(name).Add(float64(len(res.DroppedEntries)))
+}
@@ -0,0 +1,96 @@
+package querygen
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+)
+
+// variablePattern matches $name and ${name}.
+var variablePattern = regexp.MustCompile(`\$\{(\w+)\}|\$(\w+)`)
+
+// variables holds the label values the template variables are filled with.
+type variables struct {
+	client *loki.Client
+	labels map[string]string
+
+	mu     sync.RWMutex
+	values map[string][]string
+}
+
+func newVariables(client *loki.Client, labels map[string]string) *variables {
+	return &variables{client: client, labels: labels, values: map[string][]string{}}
+}
+
+// refresh fetches the values of every label seen within lookback.
+func (v *variables) refresh(ctx context.Context, lookback time.Duration) error {
+	end := time.Now()
+
+	values := make(map[string][]string, len(v.labels))
+	for name, label := range v.labels {
+		vals, err := v.client.LabelValues(ctx, label, "", end.Add(-lookback), end)
+		if err != nil {
+			return fmt.Errorf("failed fetching values of label %s: %w", label, err)
+		}
+		if len(vals) == 0 {
+			return fmt.Errorf("no values for label %s of variable %s", label, name)
+		}
+
+		values[name] = vals
+	}
+
+	v.mu.Lock()
+	v.values = values
+	v.mu.Unlock()
+
+	return nil
+}
+
+// refreshEvery keeps the values up to date until the context is done. The
+// previous values are kept when a refresh fails.
+func (v *variables) refreshEvery(ctx context.Context, interval, lookback time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := v.refresh(ctx, lookback); err != nil && ctx.Err() == nil {
+				log.Printf("failed refreshing template variables: %v", err)
+			}
+		}
+	}
+}
+
+// expand replaces the known variables of the query by a random value.
+// Unknown variables, e.g. a $ anchor in a regular expression, are kept.
+func (v *variables) expand(query string, rnd *rand.Rand) string {
+	if len(v.labels) == 0 {
+		return query
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return variablePattern.ReplaceAllStringFunc(query, func(match string) string {
+		groups := variablePattern.FindStringSubmatch(match)
+		name := groups[1]
+		if name == "" {
+			name = groups[2]
+		}
+
+		vals, ok := v.values[name]
+		if !ok {
+			return match
+		}
+		return vals[rnd.Intn(len(vals))]
+	})
+}
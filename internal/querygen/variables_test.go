@@ -0,0 +1,109 @@
+package querygen
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+)
+
+func TestVariablesExpand(t *testing.T) {
+	v := newVariables(nil, map[string]string{"namespace": "kubernetes_namespace_name", "pod": "kubernetes_pod_name"})
+	v.values = map[string][]string{"namespace": {"ns"}, "pod": {"pod-0"}}
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "plain variable",
+			query: `{kubernetes_namespace_name="$namespace"}`,
+			want:  `{kubernetes_namespace_name="ns"}`,
+		},
+		{
+			name:  "braced variable",
+			query: `{kubernetes_pod_name="${pod}-x"}`,
+			want:  `{kubernetes_pod_name="pod-0-x"}`,
+		},
+		{
+			name:  "several variables",
+			query: `{kubernetes_namespace_name="$namespace", kubernetes_pod_name="$pod"}`,
+			want:  `{kubernetes_namespace_name="ns", kubernetes_pod_name="pod-0"}`,
+		},
+		{
+			name:  "unknown variable and regexp anchor",
+			query: `{job=~"$unknown"} |~ "error$"`,
+			want:  `{job=~"$unknown"} |~ "error$"`,
+		},
+	}
+
+	rnd := rand.New(rand.NewSource(1)) //nolint:gosec
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := v.expand(tt.query, rnd); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVariablesExpandWithoutVariables(t *testing.T) {
+	v := newVariables(nil, nil)
+
+	query := `{job="$namespace"}`
+	if got := v.expand(query, rand.New(rand.NewSource(1))); got != query { //nolint:gosec
+		t.Errorf("got %s, want %s", got, query)
+	}
+}
+
+func TestVariablesRefresh(t *testing.T) {
+	tests := []struct {
+		name   string
+		values string
+		err    string
+	}{
+		{name: "values found", values: `["a","b"]`},
+		{name: "no values", values: `[]`, err: "no values for label kubernetes_namespace_name of variable namespace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				_, _ = w.Write([]byte(`{"status":"success","data":` + tt.values + `}`))
+			}))
+			defer srv.Close()
+
+			client, err := loki.NewClient(srv.URL, "tenant", "", time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := newVariables(client, map[string]string{"namespace": "kubernetes_namespace_name"})
+			err = v.refresh(context.Background(), time.Hour)
+
+			if path != "/loki/api/v1/label/kubernetes_namespace_name/values" {
+				t.Errorf("got request to %s", path)
+			}
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := strings.Join(v.values["namespace"], ","); got != "a,b" {
+				t.Errorf("got values %s, want a,b", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package querygen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/dataset"
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Checks lists the verification checks in the order they run.
+var Checks = []string{CheckCount, CheckRate, CheckLines, CheckSeries, CheckLabelValues}
+
+const (
+	CheckCount       = "count"
+	CheckRate        = "rate"
+	CheckLines       = "lines"
+	CheckSeries      = "series"
+	CheckLabelValues = "label_values"
+
+	// rateTolerance absorbs the float formatting of the rate values.
+	rateTolerance = 1e-6
+)
+
+// Verifier compares query results over past windows of the deterministic
+// dataset with the expected values.
+type Verifier struct {
+	client  *loki.Client
+	dataset *dataset.Dataset
+
+	checks     *prometheus.CounterVec
+	mismatches *prometheus.CounterVec
+}
+
+func NewVerifier(client *loki.Client, d *dataset.Dataset, reg prometheus.Registerer) (*Verifier, error) {
+	v := &Verifier{
+		client:  client,
+		dataset: d,
+		checks: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "loki_benchmarks",
+				Name:      "verification_checks_total",
+				Help:      "Number of compared query results by check.",
+			},
+			[]string{"check"},
+		),
+		mismatches: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "loki_benchmarks",
+				Name:      "verification_mismatches_total",
+				Help:      "Number of query results that differ from the expected values by check.",
+			},
+			[]string{"check"},
+		),
+	}
+
+	for _, c := range []prometheus.Collector{v.checks, v.mismatches} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return v, nil
+}
+
+// Run verifies the window ending lag ago every interval until the context is
+// done. The lag must cover the push interval and retries of the generator.
+// Windows starting before the dataset was pushed from start on are skipped.
+func (v *Verifier) Run(ctx context.Context, start time.Time, interval, window, lag time.Duration) error {
+	log.Printf("verifying dataset %d every %s over %s windows", v.dataset.Seed, interval, window)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			end := now.Add(-lag).Truncate(time.Second)
+			if end.Add(-window).Before(start) {
+				continue
+			}
+			v.verify(ctx, end.Add(-window), end)
+		}
+	}
+}
+
+func (v *Verifier) verify(ctx context.Context, start, end time.Time) {
+	checks := []struct {
+		name string
+		fn   func(ctx context.Context, start, end time.Time) (int, int, error)
+	}{
+		{name: CheckCount, fn: v.verifyCount},
+		{name: CheckRate, fn: v.verifyRate},
+		{name: CheckLines, fn: v.verifyLines},
+		{name: CheckSeries, fn: v.verifySeries},
+		{name: CheckLabelValues, fn: v.verifyLabelValues},
+	}
+
+	for _, check := range checks {
+		total, mismatches, err := check.fn(loki.WithQueryName(ctx, "verify_"+check.name), start, end)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			// A failed query is a mismatch as well, the data is not readable.
+			log.Printf("verification %s failed: %v", check.name, err)
+			total, mismatches = 1, 1
+		}
+
+		v.checks.WithLabelValues(check.name).Add(float64(total))
+		v.mismatches.WithLabelValues(check.name).Add(float64(mismatches))
+
+		if mismatches > 0 {
+			log.Printf("verification %s: %d/%d mismatches between %s and %s",
+				check.name, mismatches, total, start.Format(time.RFC3339), end.Format(time.RFC3339))
+		}
+	}
+}
+
+// verifyCount compares the line count of every stream.
+func (v *Verifier) verifyCount(ctx context.Context, start, end time.Time) (int, int, error) {
+	query := fmt.Sprintf(`sum by (%s) (count_over_time(%s[%s]))`, dataset.StreamLabel, v.dataset.Selector(), rangeSelector(end.Sub(start)))
+
+	got, err := v.vector(ctx, query, end, dataset.StreamLabel)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	mismatches := 0
+	for i := 0; i < v.dataset.Streams; i++ {
+		expected := float64(v.dataset.Count(i, start, end, ""))
+		if got[strconv.Itoa(i)] != expected {
+			mismatches++
+		}
+	}
+
+	return v.dataset.Streams, mismatches, nil
+}
+
+// verifyRate compares the rate of the lines of every level.
+func (v *Verifier) verifyRate(ctx context.Context, start, end time.Time) (int, int, error) {
+	window := end.Sub(start)
+	mismatches := 0
+
+	for _, level := range dataset.Levels {
+		query := fmt.Sprintf(`sum(rate(%s |= "level=%s" [%s]))`, v.dataset.Selector(), level, rangeSelector(window))
+
+		got, err := v.vector(ctx, query, end, "")
+		if err != nil {
+			return 0, 0, err
+		}
+
+		count := 0
+		for i := 0; i < v.dataset.Streams; i++ {
+			count += v.dataset.Count(i, start, end, level)
+		}
+
+		expected := float64(count) / window.Seconds()
+		if math.Abs(got[""]-expected) > rateTolerance*math.Max(1, expected) {
+			mismatches++
+		}
+	}
+
+	return len(dataset.Levels), mismatches, nil
+}
+
+// verifyLines compares the log lines of the first stream one by one.
+func (v *Verifier) verifyLines(ctx context.Context, start, end time.Time) (int, int, error) {
+	expected := v.dataset.Entries(0, start, end)
+
+	// Loki returns the lines in [start, end), the dataset counts (start, end].
+	res, err := v.client.QueryRange(ctx, v.dataset.StreamSelector(0), start.Add(1), end.Add(1), 0, len(expected)+1)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var streams []loki.StreamResult
+	if err := json.Unmarshal(res.Data.Result, &streams); err != nil {
+		return 0, 0, fmt.Errorf("failed decoding lines: %w", err)
+	}
+
+	got := map[string]string{}
+	for _, s := range streams {
+		for _, value := range s.Values {
+			if len(value) >= 2 {
+				got[value[0]] = value[1]
+			}
+		}
+	}
+
+	mismatches := 0
+	for _, e := range expected {
+		if got[strconv.FormatInt(e.Timestamp.UnixNano(), 10)] != e.Line {
+			mismatches++
+		}
+	}
+	// Lines Loki returned on top of the expected ones.
+	if extra := len(got) - (len(expected) - mismatches); extra > 0 {
+		mismatches += extra
+	}
+
+	return len(expected), mismatches, nil
+}
+
+// verifySeries compares the label sets of the streams. Only the labels of the
+// dataset are compared, Loki adds others like service_name on ingestion.
+func (v *Verifier) verifySeries(ctx context.Context, start, end time.Time) (int, int, error) {
+	series, err := v.client.Series(ctx, v.dataset.Selector(), start, end)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	got := make([]string, 0, len(series))
+	for _, s := range series {
+		labels := map[string]string{}
+		for _, name := range []string{dataset.DatasetLabel, dataset.StreamLabel} {
+			if value, ok := s[name]; ok {
+				labels[name] = value
+			}
+		}
+		got = append(got, labelSet(labels))
+	}
+
+	expected := make([]string, 0, v.dataset.Streams)
+	for i := 0; i < v.dataset.Streams; i++ {
+		expected = append(expected, labelSet(v.dataset.Labels(i)))
+	}
+
+	return v.dataset.Streams, diff(expected, got), nil
+}
+
+// verifyLabelValues compares the values of the stream label.
+func (v *Verifier) verifyLabelValues(ctx context.Context, start, end time.Time) (int, int, error) {
+	got, err := v.client.LabelValues(ctx, dataset.StreamLabel, v.dataset.Selector(), start, end)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	expected := make([]string, 0, v.dataset.Streams)
+	for i := 0; i < v.dataset.Streams; i++ {
+		expected = append(expected, strconv.Itoa(i))
+	}
+
+	return v.dataset.Streams, diff(expected, got), nil
+}
+
+// vector runs an instant query and returns the sample values by the value
+// of the label.
+func (v *Verifier) vector(ctx context.Context, query string, ts time.Time, label string) (map[string]float64, error) {
+	res, err := v.client.Query(ctx, query, ts, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var vector []loki.VectorResult
+	if err := json.Unmarshal(res.Data.Result, &vector); err != nil {
+		return nil, fmt.Errorf("failed decoding vector: %w", err)
+	}
+
+	values := map[string]float64{}
+	for _, sample := range vector {
+		if len(sample.Value) < 2 {
+			continue
+		}
+
+		raw, ok := sample.Value[1].(string)
+		if !ok {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing sample value: %w", err)
+		}
+
+		values[sample.Metric[label]] = value
+	}
+
+	return values, nil
+}
+
+// diff returns the number of elements only present in one of both lists.
+func diff(expected, got []string) int {
+	seen := map[string]int{}
+	for _, e := range expected {
+		seen[e]++
+	}
+	for _, g := range got {
+		seen[g]--
+	}
+
+	n := 0
+	for _, c := range seen {
+		if c != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func labelSet(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", name, labels[name]))
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// rangeSelector formats the window as LogQL duration in seconds.
+func rangeSelector(window time.Duration) string {
+	return fmt.Sprintf("%ds", int(window.Seconds()))
+}
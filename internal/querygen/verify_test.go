@@ -0,0 +1,182 @@
+package querygen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/dataset"
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var levelFilter = regexp.MustCompile(`level=(\w+)`)
+
+// fakeLoki answers the verification queries over [start, end] from the
+// dataset, leaving out the streams listed in lost.
+func fakeLoki(t *testing.T, d *dataset.Dataset, start, end time.Time, lost map[int]bool) *httptest.Server {
+	t.Helper()
+
+	vector := func(samples []loki.VectorResult) interface{} {
+		return map[string]interface{}{"status": "success", "data": map[string]interface{}{"resultType": "vector", "result": samples}}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var out interface{}
+
+		switch r.URL.Path {
+		case loki.QueryPath:
+			query := r.URL.Query().Get("query")
+			if m := levelFilter.FindStringSubmatch(query); m != nil {
+				count := 0
+				for i := 0; i < d.Streams; i++ {
+					if !lost[i] {
+						count += d.Count(i, start, end, m[1])
+					}
+				}
+				rate := float64(count) / end.Sub(start).Seconds()
+				out = vector([]loki.VectorResult{{Metric: map[string]string{}, Value: []interface{}{1, strconv.FormatFloat(rate, 'f', -1, 64)}}})
+				break
+			}
+
+			var samples []loki.VectorResult
+			for i := 0; i < d.Streams; i++ {
+				if !lost[i] {
+					samples = append(samples, loki.VectorResult{
+						Metric: map[string]string{dataset.StreamLabel: strconv.Itoa(i)},
+						Value:  []interface{}{1, strconv.Itoa(d.Count(i, start, end, ""))},
+					})
+				}
+			}
+			out = vector(samples)
+		case loki.QueryRangePath:
+			var values [][]string
+			if !lost[0] {
+				for _, e := range d.Entries(0, start, end) {
+					values = append(values, []string{strconv.FormatInt(e.Timestamp.UnixNano(), 10), e.Line})
+				}
+			}
+			out = map[string]interface{}{"status": "success", "data": map[string]interface{}{
+				"resultType": "streams",
+				"result":     []loki.StreamResult{{Labels: d.Labels(0), Values: values}},
+			}}
+		case loki.SeriesPath:
+			var series []map[string]string
+			for i := 0; i < d.Streams; i++ {
+				if !lost[i] {
+					labels := d.Labels(i)
+					labels["service_name"] = "unknown_service"
+					series = append(series, labels)
+				}
+			}
+			out = loki.SeriesResponse{Status: "success", Data: series}
+		case fmt.Sprintf(loki.LabelValuesPath, dataset.StreamLabel):
+			var values []string
+			for i := 0; i < d.Streams; i++ {
+				if !lost[i] {
+					values = append(values, strconv.Itoa(i))
+				}
+			}
+			out = loki.LabelResponse{Status: "success", Data: values}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			t.Errorf("failed encoding response: %v", err)
+		}
+	}))
+}
+
+func TestVerify(t *testing.T) {
+	d := &dataset.Dataset{Seed: 42, Streams: 3, LinesPerSecond: 2}
+	end := time.Unix(1700000000, 0)
+	start := end.Add(-time.Minute)
+
+	tests := []struct {
+		name       string
+		lost       map[int]bool
+		mismatches map[string]float64
+	}{
+		{
+			name:       "all lines readable",
+			mismatches: map[string]float64{},
+		},
+		{
+			name: "first stream lost",
+			lost: map[int]bool{0: true},
+			mismatches: map[string]float64{
+				CheckCount:       1,
+				CheckRate:        float64(len(dataset.Levels)),
+				CheckLines:       float64(d.Count(0, start, end, "")),
+				CheckSeries:      1,
+				CheckLabelValues: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := fakeLoki(t, d, start, end, tt.lost)
+			defer srv.Close()
+
+			client, err := loki.NewClient(srv.URL, "tenant", "", time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v, err := NewVerifier(client, d, prometheus.NewRegistry())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v.verify(context.Background(), start, end)
+
+			checks := map[string]float64{
+				CheckCount:       float64(d.Streams),
+				CheckRate:        float64(len(dataset.Levels)),
+				CheckLines:       float64(d.Count(0, start, end, "")),
+				CheckSeries:      float64(d.Streams),
+				CheckLabelValues: float64(d.Streams),
+			}
+			for _, check := range Checks {
+				if got := testutil.ToFloat64(v.checks.WithLabelValues(check)); got != checks[check] {
+					t.Errorf("got %v %s checks, want %v", got, check, checks[check])
+				}
+				if got := testutil.ToFloat64(v.mismatches.WithLabelValues(check)); got != tt.mismatches[check] {
+					t.Errorf("got %v %s mismatches, want %v", got, check, tt.mismatches[check])
+				}
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []string
+		got      []string
+		want     int
+	}{
+		{name: "equal", expected: []string{"a", "b"}, got: []string{"b", "a"}, want: 0},
+		{name: "missing", expected: []string{"a", "b"}, got: []string{"a"}, want: 1},
+		{name: "extra", expected: []string{"a"}, got: []string{"a", "c"}, want: 1},
+		{name: "different", expected: []string{"a", "b"}, got: []string{"c", "d"}, want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diff(tt.expected, tt.got); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
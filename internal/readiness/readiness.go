@@ -0,0 +1,145 @@
+package readiness
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ConditionReady is the condition custom resources like LokiStack report
+// their readiness with.
+const ConditionReady = "Ready"
+
+// LokiStackGVK is the kind of the LokiStack custom resource of the Loki
+// operator.
+var LokiStackGVK = schema.GroupVersionKind{Group: "loki.grafana.com", Version: "v1", Kind: "LokiStack"}
+
+// Status is the readiness of an object. Reason explains why it is not ready.
+type Status struct {
+	Ready  bool
+	Reason string
+}
+
+func ready() Status {
+	return Status{Ready: true}
+}
+
+func notReady(format string, args ...interface{}) Status {
+	return Status{Reason: fmt.Sprintf(format, args...)}
+}
+
+// LokiStack returns an unstructured LokiStack to wait for.
+func LokiStack(name, namespace string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(LokiStackGVK)
+	u.SetName(name)
+	u.SetNamespace(namespace)
+	return u
+}
+
+// Of returns the readiness of a Deployment, a StatefulSet or of an
+// unstructured custom resource by its Ready condition.
+func Of(obj client.Object) (Status, error) {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return Deployment(o), nil
+	case *appsv1.StatefulSet:
+		return StatefulSet(o), nil
+	case *unstructured.Unstructured:
+		return Condition(o, ConditionReady)
+	default:
+		return Status{}, fmt.Errorf("unsupported object %T", obj)
+	}
+}
+
+// Deployment is ready once the controller observed the latest spec and all
+// replicas are updated and available.
+func Deployment(d *appsv1.Deployment) Status {
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+
+	s := d.Status
+	switch {
+	case s.ObservedGeneration < d.Generation:
+		return notReady("generation %d not observed yet", d.Generation)
+	case s.UpdatedReplicas < replicas:
+		return notReady("%d of %d replicas updated", s.UpdatedReplicas, replicas)
+	case s.Replicas > s.UpdatedReplicas:
+		return notReady("%d old replicas pending termination", s.Replicas-s.UpdatedReplicas)
+	case s.AvailableReplicas < replicas:
+		return notReady("%d of %d replicas available", s.AvailableReplicas, replicas)
+	default:
+		return ready()
+	}
+}
+
+// StatefulSet is ready once the controller observed the latest spec and all
+// replicas run the update revision and are ready.
+func StatefulSet(sts *appsv1.StatefulSet) Status {
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+
+	s := sts.Status
+	switch {
+	case s.ObservedGeneration < sts.Generation:
+		return notReady("generation %d not observed yet", sts.Generation)
+	case s.UpdatedReplicas < replicas:
+		return notReady("%d of %d replicas updated", s.UpdatedReplicas, replicas)
+	case s.UpdateRevision != "" && s.CurrentRevision != s.UpdateRevision:
+		return notReady("revision %s not rolled out yet", s.UpdateRevision)
+	case s.ReadyReplicas < replicas:
+		return notReady("%d of %d replicas ready", s.ReadyReplicas, replicas)
+	default:
+		return ready()
+	}
+}
+
+// Condition returns whether the status condition of type conditionType of
+// the custom resource is true. A condition with an observedGeneration older
+// than the resource does not count.
+func Condition(u *unstructured.Unstructured, conditionType string) (Status, error) {
+	conditions, found, err := unstructured.NestedSlice(u.Object, "status", "conditions")
+	if err != nil {
+		return Status{}, fmt.Errorf("failed reading status conditions: %w", err)
+	}
+	if !found {
+		return notReady("no status conditions"), nil
+	}
+
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if t, _, _ := unstructured.NestedString(condition, "type"); t != conditionType {
+			continue
+		}
+
+		status, _, _ := unstructured.NestedString(condition, "status")
+		reason, _, _ := unstructured.NestedString(condition, "reason")
+		message, _, _ := unstructured.NestedString(condition, "message")
+
+		if generation, found, _ := unstructured.NestedInt64(condition, "observedGeneration"); found && generation < u.GetGeneration() {
+			return notReady("condition %s of generation %d not observed yet", conditionType, u.GetGeneration()), nil
+		}
+
+		if status == "True" {
+			return ready(), nil
+		}
+
+		if message != "" {
+			return notReady("condition %s is %s: %s: %s", conditionType, status, reason, message), nil
+		}
+		return notReady("condition %s is %s: %s", conditionType, status, reason), nil
+	}
+
+	return notReady("condition %s not reported", conditionType), nil
+}
@@ -0,0 +1,269 @@
+package readiness_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/readiness"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	interval = 10 * time.Millisecond
+	timeout  = 100 * time.Millisecond
+)
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	gvk := readiness.LokiStackGVK
+	scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(gvk.GroupVersion().WithKind(gvk.Kind+"List"), &unstructured.UnstructuredList{})
+
+	return scheme
+}
+
+func deployment(status appsv1.DeploymentStatus) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "querier", Namespace: "loki", Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32(3)},
+		Status:     status,
+	}
+}
+
+func statefulSet(status appsv1.StatefulSetStatus) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingester", Namespace: "loki", Generation: 2},
+		Spec:       appsv1.StatefulSetSpec{Replicas: pointer.Int32(3)},
+		Status:     status,
+	}
+}
+
+func lokiStack(generation int64, conditions ...interface{}) *unstructured.Unstructured {
+	u := readiness.LokiStack("lokistack-dev", "loki")
+	u.SetGeneration(generation)
+	if conditions != nil {
+		if err := unstructured.SetNestedSlice(u.Object, conditions, "status", "conditions"); err != nil {
+			panic(err)
+		}
+	}
+	return u
+}
+
+func condition(conditionType, status, reason string, generation int64) interface{} {
+	return map[string]interface{}{
+		"type":               conditionType,
+		"status":             status,
+		"reason":             reason,
+		"observedGeneration": generation,
+	}
+}
+
+func TestOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		obj    client.Object
+		ready  bool
+		reason string
+	}{
+		{
+			name:  "deployment ready",
+			obj:   deployment(appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3}),
+			ready: true,
+		},
+		{
+			name:   "deployment generation not observed",
+			obj:    deployment(appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3}),
+			reason: "generation 2 not observed yet",
+		},
+		{
+			name:   "deployment rolling out",
+			obj:    deployment(appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 1, AvailableReplicas: 3}),
+			reason: "1 of 3 replicas updated",
+		},
+		{
+			name:   "deployment old replicas terminating",
+			obj:    deployment(appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 3, AvailableReplicas: 3}),
+			reason: "1 old replicas pending termination",
+		},
+		{
+			name:   "deployment unavailable",
+			obj:    deployment(appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 2}),
+			reason: "2 of 3 replicas available",
+		},
+		{
+			name:  "statefulset ready",
+			obj:   statefulSet(appsv1.StatefulSetStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 3, CurrentRevision: "r2", UpdateRevision: "r2"}),
+			ready: true,
+		},
+		{
+			name:   "statefulset generation not observed",
+			obj:    statefulSet(appsv1.StatefulSetStatus{ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 3}),
+			reason: "generation 2 not observed yet",
+		},
+		{
+			name:   "statefulset rolling out",
+			obj:    statefulSet(appsv1.StatefulSetStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 1, ReadyReplicas: 3, CurrentRevision: "r1", UpdateRevision: "r2"}),
+			reason: "1 of 3 replicas updated",
+		},
+		{
+			name:   "statefulset revision not current",
+			obj:    statefulSet(appsv1.StatefulSetStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 3, CurrentRevision: "r1", UpdateRevision: "r2"}),
+			reason: "revision r2 not rolled out yet",
+		},
+		{
+			name:   "statefulset not ready",
+			obj:    statefulSet(appsv1.StatefulSetStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 1, CurrentRevision: "r2", UpdateRevision: "r2"}),
+			reason: "1 of 3 replicas ready",
+		},
+		{
+			name:  "lokistack ready",
+			obj:   lokiStack(1, condition("Ready", "True", "ReadyComponents", 1)),
+			ready: true,
+		},
+		{
+			name:   "lokistack without conditions",
+			obj:    lokiStack(1),
+			reason: "no status conditions",
+		},
+		{
+			name:   "lokistack pending",
+			obj:    lokiStack(1, condition("Pending", "True", "PendingComponents", 1), condition("Ready", "False", "PendingComponents", 1)),
+			reason: "condition Ready is False: PendingComponents",
+		},
+		{
+			name:   "lokistack outdated condition",
+			obj:    lokiStack(2, condition("Ready", "True", "ReadyComponents", 1)),
+			reason: "condition Ready of generation 2 not observed yet",
+		},
+		{
+			name:   "lokistack condition missing",
+			obj:    lokiStack(1, condition("Pending", "True", "PendingComponents", 1)),
+			reason: "condition Ready not reported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := readiness.Of(tt.obj)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if s.Ready != tt.ready || s.Reason != tt.reason {
+				t.Errorf("got %+v, want ready %t and reason %q", s, tt.ready, tt.reason)
+			}
+		})
+	}
+}
+
+func TestOfUnsupported(t *testing.T) {
+	if _, err := readiness.Of(&appsv1.DaemonSet{}); err == nil {
+		t.Error("expected an error for a DaemonSet")
+	}
+}
+
+func TestWaiter(t *testing.T) {
+	tests := []struct {
+		name     string
+		objs     []client.Object
+		wait     client.Object
+		update   func(c client.Client) error
+		timeout  time.Duration
+		err      string
+		progress []readiness.Status
+	}{
+		{
+			name:     "ready",
+			objs:     []client.Object{deployment(appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3})},
+			wait:     deployment(appsv1.DeploymentStatus{}),
+			timeout:  timeout,
+			progress: []readiness.Status{{Ready: true}},
+		},
+		{
+			name: "becomes ready",
+			objs: []client.Object{statefulSet(appsv1.StatefulSetStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 1})},
+			wait: statefulSet(appsv1.StatefulSetStatus{}),
+			update: func(c client.Client) error {
+				current := &appsv1.StatefulSet{}
+				if err := c.Get(context.Background(), client.ObjectKey{Name: "ingester", Namespace: "loki"}, current); err != nil {
+					return err
+				}
+				current.Status.ReadyReplicas = 3
+				return c.Update(context.Background(), current)
+			},
+			timeout:  time.Second,
+			progress: []readiness.Status{{Reason: "1 of 3 replicas ready"}, {Ready: true}},
+		},
+		{
+			name:     "timeout",
+			objs:     []client.Object{lokiStack(1, condition("Ready", "False", "PendingComponents", 1))},
+			wait:     readiness.LokiStack("lokistack-dev", "loki"),
+			timeout:  timeout,
+			err:      "LokiStack loki/lokistack-dev not ready: condition Ready is False: PendingComponents",
+			progress: []readiness.Status{{Reason: "condition Ready is False: PendingComponents"}},
+		},
+		{
+			name:     "missing",
+			wait:     deployment(appsv1.DeploymentStatus{}),
+			timeout:  timeout,
+			err:      "Deployment loki/querier not ready: not found",
+			progress: []readiness.Status{{Reason: "not found"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(tt.objs...).Build()
+
+			updated := make(chan error, 1)
+			if tt.update != nil {
+				go func() {
+					time.Sleep(3 * interval)
+					updated <- tt.update(c)
+				}()
+			}
+
+			var progress []readiness.Status
+			record := func(_ string, _ client.ObjectKey, status readiness.Status) {
+				progress = append(progress, status)
+			}
+
+			err := readiness.NewWaiter(c, interval, tt.timeout, record).Wait(context.Background(), tt.wait)
+
+			if tt.update != nil {
+				if uerr := <-updated; uerr != nil {
+					t.Fatalf("failed updating object: %v", uerr)
+				}
+			}
+
+			switch {
+			case tt.err == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.err != "" && (err == nil || !errors.Is(err, wait.ErrWaitTimeout) || !strings.Contains(err.Error(), tt.err)):
+				t.Fatalf("got error %v, want timeout containing %q", err, tt.err)
+			}
+
+			if !reflect.DeepEqual(progress, tt.progress) {
+				t.Errorf("got progress %+v, want %+v", progress, tt.progress)
+			}
+		})
+	}
+}
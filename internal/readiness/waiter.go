@@ -0,0 +1,100 @@
+package readiness
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ProgressFunc is called whenever the readiness of an object changes while
+// waiting for it.
+type ProgressFunc func(kind string, key client.ObjectKey, status Status)
+
+// LogProgress logs the progress.
+func LogProgress(kind string, key client.ObjectKey, status Status) {
+	if status.Ready {
+		log.Printf("%s %s is ready", kind, key)
+		return
+	}
+	log.Printf("waiting for %s %s: %s", kind, key, status.Reason)
+}
+
+type Waiter struct {
+	client   client.Client
+	interval time.Duration
+	timeout  time.Duration
+	progress ProgressFunc
+}
+
+// NewWaiter returns a waiter polling every interval for up to timeout. The
+// progress function is optional.
+func NewWaiter(c client.Client, interval, timeout time.Duration, progress ProgressFunc) *Waiter {
+	return &Waiter{client: c, interval: interval, timeout: timeout, progress: progress}
+}
+
+// Wait waits until the object is ready. A missing object is not ready yet.
+// On timeout the error contains the last reason the object was not ready.
+// The object passed in is not modified.
+func (w *Waiter) Wait(ctx context.Context, obj client.Object) error {
+	current, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return fmt.Errorf("unsupported object %T", obj)
+	}
+
+	kind := kindOf(obj)
+	key := client.ObjectKeyFromObject(obj)
+
+	var last Status
+	report := func(status Status) {
+		if status != last && w.progress != nil {
+			w.progress(kind, key, status)
+		}
+		last = status
+	}
+
+	err := wait.PollImmediateWithContext(ctx, w.interval, w.timeout, func(ctx context.Context) (bool, error) {
+		if err := w.client.Get(ctx, key, current); err != nil {
+			if apierrors.IsNotFound(err) {
+				report(notReady("not found"))
+				return false, nil
+			}
+			return false, err
+		}
+
+		status, err := Of(current)
+		if err != nil {
+			return false, err
+		}
+
+		report(status)
+		return status.Ready, nil
+	})
+	if err != nil {
+		if last.Reason != "" {
+			return fmt.Errorf("%s %s not ready: %s: %w", kind, key, last.Reason, err)
+		}
+		return fmt.Errorf("%s %s not ready: %w", kind, key, err)
+	}
+
+	return nil
+}
+
+func kindOf(obj client.Object) string {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return "Deployment"
+	case *appsv1.StatefulSet:
+		return "StatefulSet"
+	case *unstructured.Unstructured:
+		return o.GetKind()
+	default:
+		return fmt.Sprintf("%T", obj)
+	}
+}
@@ -0,0 +1,102 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/observatorium/loki-benchmarks/internal/metrics"
+
+	"github.com/onsi/gomega/gmeasure"
+)
+
+// Delta is the change of the median of a measurement per annotation between
+// two reports.
+type Delta struct {
+	Experiment string
+	Name       string
+	Annotation string
+	Units      string
+	Baseline   float64
+	Candidate  float64
+}
+
+// Change returns the relative change in percent. It is infinite if the
+// baseline is zero and the candidate is not.
+func (d Delta) Change() float64 {
+	if d.Baseline == d.Candidate {
+		return 0
+	}
+	if d.Baseline == 0 {
+		return math.Inf(1)
+	}
+	return (d.Candidate - d.Baseline) / math.Abs(d.Baseline) * 100
+}
+
+// Compare returns the deltas of all value measurements the experiments of
+// both reports have in common, matched by experiment and measurement name
+// and annotation.
+func Compare(baseline, candidate []gmeasure.Experiment) []Delta {
+	candidates := map[string]gmeasure.Experiment{}
+	for _, e := range candidate {
+		candidates[e.Name] = e
+	}
+
+	var deltas []Delta
+	for _, b := range baseline {
+		c, ok := candidates[b.Name]
+		if !ok {
+			continue
+		}
+
+		for _, m := range b.Measurements {
+			if m.Type != gmeasure.MeasurementTypeValue {
+				continue
+			}
+
+			idx := c.Measurements.IdxWithName(m.Name)
+			if idx == -1 {
+				continue
+			}
+
+			before := metrics.AnnotatedMedians(m)
+			after := metrics.AnnotatedMedians(c.Measurements[idx])
+
+			annotations := make([]string, 0, len(before))
+			for a := range before {
+				if _, ok := after[a]; ok {
+					annotations = append(annotations, a)
+				}
+			}
+			sort.Strings(annotations)
+
+			for _, a := range annotations {
+				deltas = append(deltas, Delta{
+					Experiment: b.Name,
+					Name:       m.Name,
+					Annotation: a,
+					Units:      m.Units,
+					Baseline:   before[a],
+					Candidate:  after[a],
+				})
+			}
+		}
+	}
+
+	return deltas
+}
+
+// WriteDeltas writes the deltas as a table.
+func WriteDeltas(w io.Writer, deltas []Delta) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "EXPERIMENT\tMEASUREMENT\tANNOTATION\tBASELINE\tCANDIDATE\tCHANGE")
+	for _, d := range deltas {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.4g %s\t%.4g %s\t%+.1f%%\n",
+			d.Experiment, d.Name, d.Annotation, d.Baseline, d.Units, d.Candidate, d.Units, d.Change())
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,104 @@
+package report
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/onsi/gomega/gmeasure"
+)
+
+func experiment(name string, measurements ...gmeasure.Measurement) gmeasure.Experiment {
+	return gmeasure.Experiment{Name: name, Measurements: measurements}
+}
+
+func values(name string, annotations []string, vs ...float64) gmeasure.Measurement {
+	return gmeasure.Measurement{
+		Type:        gmeasure.MeasurementTypeValue,
+		Name:        name,
+		Units:       "s",
+		Values:      vs,
+		Annotations: annotations,
+	}
+}
+
+func TestDeltaChange(t *testing.T) {
+	tests := []struct {
+		name      string
+		baseline  float64
+		candidate float64
+		want      float64
+	}{
+		{name: "unchanged", baseline: 2, candidate: 2, want: 0},
+		{name: "increased", baseline: 2, candidate: 3, want: 50},
+		{name: "decreased", baseline: 2, candidate: 1, want: -50},
+		{name: "negative baseline", baseline: -2, candidate: -1, want: 50},
+		{name: "both zero", baseline: 0, candidate: 0, want: 0},
+		{name: "zero baseline", baseline: 0, candidate: 1, want: math.Inf(1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Delta{Baseline: tt.baseline, Candidate: tt.candidate}.Change()
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name      string
+		baseline  []gmeasure.Experiment
+		candidate []gmeasure.Experiment
+		want      []Delta
+	}{
+		{
+			name:      "median per annotation",
+			baseline:  []gmeasure.Experiment{experiment("reads", values("latency", []string{"a", "a", "a", "b"}, 1, 2, 3, 4))},
+			candidate: []gmeasure.Experiment{experiment("reads", values("latency", []string{"b", "a"}, 8, 4))},
+			want: []Delta{
+				{Experiment: "reads", Name: "latency", Annotation: "a", Units: "s", Baseline: 2, Candidate: 4},
+				{Experiment: "reads", Name: "latency", Annotation: "b", Units: "s", Baseline: 4, Candidate: 8},
+			},
+		},
+		{
+			name:      "annotation missing in the candidate",
+			baseline:  []gmeasure.Experiment{experiment("reads", values("latency", []string{"a", "b"}, 1, 2))},
+			candidate: []gmeasure.Experiment{experiment("reads", values("latency", []string{"a"}, 3))},
+			want: []Delta{
+				{Experiment: "reads", Name: "latency", Annotation: "a", Units: "s", Baseline: 1, Candidate: 3},
+			},
+		},
+		{
+			name:      "values without annotations",
+			baseline:  []gmeasure.Experiment{experiment("reads", values("latency", nil, 1, 2))},
+			candidate: []gmeasure.Experiment{experiment("reads", values("latency", nil, 3, 4))},
+		},
+		{
+			name:      "measurement missing in the candidate",
+			baseline:  []gmeasure.Experiment{experiment("reads", values("latency", []string{"a"}, 1))},
+			candidate: []gmeasure.Experiment{experiment("reads", values("throughput", []string{"a"}, 1))},
+		},
+		{
+			name:      "experiment missing in the candidate",
+			baseline:  []gmeasure.Experiment{experiment("reads", values("latency", []string{"a"}, 1))},
+			candidate: []gmeasure.Experiment{experiment("writes", values("latency", []string{"a"}, 1))},
+		},
+		{
+			name:      "durations",
+			baseline:  []gmeasure.Experiment{experiment("reads", gmeasure.Measurement{Type: gmeasure.MeasurementTypeDuration, Name: "latency"})},
+			candidate: []gmeasure.Experiment{experiment("reads", gmeasure.Measurement{Type: gmeasure.MeasurementTypeDuration, Name: "latency"})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compare(tt.baseline, tt.candidate)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
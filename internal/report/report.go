@@ -0,0 +1,105 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/onsi/gomega/gmeasure"
+)
+
+const (
+	// ReportFile is the Ginkgo JSON report in a report directory.
+	ReportFile = "report.json"
+
+	// MeasurementsFile holds the measurements of all experiments of a
+	// report, with their values grouped by annotation.
+	MeasurementsFile = "measurements.json"
+)
+
+// Load returns the experiments reported by the benchmarks in the Ginkgo JSON
+// report. path is either the report or the directory containing it.
+func Load(path string) ([]gmeasure.Experiment, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, ReportFile)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading report: %w", err)
+	}
+
+	var reports []types.Report
+	if err := json.Unmarshal(data, &reports); err != nil {
+		return nil, fmt.Errorf("failed decoding report %s: %w", path, err)
+	}
+
+	var experiments []gmeasure.Experiment
+	for _, report := range reports {
+		for _, spec := range report.SpecReports {
+			for _, entry := range spec.ReportEntries {
+				if entry.Value.AsJSON == "" {
+					continue
+				}
+
+				var e gmeasure.Experiment
+				if err := json.Unmarshal([]byte(entry.Value.AsJSON), &e); err != nil {
+					return nil, fmt.Errorf("failed decoding report entry %s: %w", entry.Name, err)
+				}
+				experiments = append(experiments, e)
+			}
+		}
+	}
+
+	return experiments, nil
+}
+
+type annotatedMeasurement struct {
+	gmeasure.Measurement
+	AnnotatedValues map[string][]float64
+}
+
+type annotatedExperiment struct {
+	Measurements []annotatedMeasurement
+}
+
+// WriteMeasurements writes the measurements of the report in dir into the
+// measurements file next to it, with the values of every measurement also
+// grouped by annotation.
+func WriteMeasurements(dir string) error {
+	experiments, err := Load(dir)
+	if err != nil {
+		return err
+	}
+
+	out := make([]annotatedExperiment, 0, len(experiments))
+	for _, e := range experiments {
+		ae := annotatedExperiment{Measurements: make([]annotatedMeasurement, 0, len(e.Measurements))}
+
+		for _, m := range e.Measurements {
+			values := map[string][]float64{}
+			for i, a := range m.Annotations {
+				if i < len(m.Values) {
+					values[a] = append(values[a], m.Values[i])
+				}
+			}
+
+			ae.Measurements = append(ae.Measurements, annotatedMeasurement{Measurement: m, AnnotatedValues: values})
+		}
+
+		out = append(out, ae)
+	}
+
+	data, err := json.MarshalIndent(out, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed encoding measurements: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, MeasurementsFile), data, 0o644); err != nil {
+		return fmt.Errorf("failed writing measurements: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package ruler
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+)
+
+// variablePattern matches $name and ${name}.
+var variablePattern = regexp.MustCompile(`\$\{(\w+)\}|\$(\w+)`)
+
+// RuleSet describes the rules loaded by loki-rules. It is read from a file,
+// usually mounted from a ConfigMap.
+//
+// Groups rule groups of RulesPerGroup rules each are generated from the
+// Templates in turn. Variables maps template variables to label names, e.g.
+// host to host. Every $host or ${host} in an expression is replaced by a value
+// of the label, as returned by the labels API, so that the rules are spread
+// over the streams of the generator.
+type RuleSet struct {
+	Namespace     string            `yaml:"namespace"`
+	Groups        int               `yaml:"groups"`
+	RulesPerGroup int               `yaml:"rulesPerGroup"`
+	Interval      time.Duration     `yaml:"interval,omitempty"`
+	Templates     []Template        `yaml:"templates"`
+	Variables     map[string]string `yaml:"variables,omitempty"`
+}
+
+// Template is a recording rule if Record is set, otherwise an alerting rule.
+// The rule names are suffixed with the rule index.
+type Template struct {
+	Record string            `yaml:"record,omitempty"`
+	Alert  string            `yaml:"alert,omitempty"`
+	Expr   string            `yaml:"expr"`
+	For    time.Duration     `yaml:"for,omitempty"`
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+// Group is a rule group in the format of the Prometheus rule files, as
+// accepted by the ruler API and the rule files of the ruler.
+type Group struct {
+	Name     string         `yaml:"name"`
+	Interval model.Duration `yaml:"interval,omitempty"`
+	Rules    []Rule         `yaml:"rules"`
+}
+
+type Rule struct {
+	Record string            `yaml:"record,omitempty"`
+	Alert  string            `yaml:"alert,omitempty"`
+	Expr   string            `yaml:"expr"`
+	For    model.Duration    `yaml:"for,omitempty"`
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+func LoadRuleSet(path string) (*RuleSet, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading rule set: %w", err)
+	}
+
+	set := &RuleSet{}
+	if err := yaml.Unmarshal(b, set); err != nil {
+		return nil, fmt.Errorf("failed decoding rule set: %w", err)
+	}
+
+	if err := set.Validate(); err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+func (s *RuleSet) Validate() error {
+	if s.Namespace == "" {
+		return fmt.Errorf("rule set requires a namespace")
+	}
+
+	if s.Groups <= 0 || s.RulesPerGroup <= 0 {
+		return fmt.Errorf("rule set requires positive groups and rules per group")
+	}
+
+	if len(s.Templates) == 0 {
+		return fmt.Errorf("rule set contains no templates")
+	}
+
+	for i, t := range s.Templates {
+		if t.Expr == "" {
+			return fmt.Errorf("template %d: expr is required", i)
+		}
+		if (t.Record == "") == (t.Alert == "") {
+			return fmt.Errorf("template %d: exactly one of record and alert is required", i)
+		}
+	}
+
+	return nil
+}
+
+func (s *RuleSet) Marshal() (string, error) {
+	b, err := yaml.Marshal(s)
+	if err != nil {
+		return "", fmt.Errorf("failed encoding rule set: %w", err)
+	}
+	return string(b), nil
+}
+
+// Generate returns the rule groups with the variables filled in from values,
+// the label values by variable name. The n-th rule uses the n-th value of
+// every variable, wrapping around, so that the same rule set yields the
+// same rules for the same values.
+func (s *RuleSet) Generate(values map[string][]string) []Group {
+	groups := make([]Group, 0, s.Groups)
+
+	n := 0
+	for g := 0; g < s.Groups; g++ {
+		group := Group{
+			Name:     fmt.Sprintf("%s-%d", s.Namespace, g),
+			Interval: model.Duration(s.Interval),
+			Rules:    make([]Rule, 0, s.RulesPerGroup),
+		}
+
+		for r := 0; r < s.RulesPerGroup; r++ {
+			t := s.Templates[n%len(s.Templates)]
+
+			rule := Rule{
+				Expr:   expand(t.Expr, values, n),
+				For:    model.Duration(t.For),
+				Labels: t.Labels,
+			}
+			if t.Record != "" {
+				rule.Record = fmt.Sprintf("%s_%d", t.Record, n)
+			} else {
+				rule.Alert = fmt.Sprintf("%s%d", t.Alert, n)
+			}
+
+			group.Rules = append(group.Rules, rule)
+			n++
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// RuleFile returns the groups as rule file.
+func RuleFile(groups []Group) ([]byte, error) {
+	b, err := yaml.Marshal(struct {
+		Groups []Group `yaml:"groups"`
+	}{Groups: groups})
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding rule groups: %w", err)
+	}
+	return b, nil
+}
+
+// expand replaces the known variables of the expression by their n-th value.
+// Unknown variables, e.g. a $ anchor in a regular expression, are kept.
+func expand(expr string, values map[string][]string, n int) string {
+	return variablePattern.ReplaceAllStringFunc(expr, func(match string) string {
+		groups := variablePattern.FindStringSubmatch(match)
+		name := groups[1]
+		if name == "" {
+			name = groups[2]
+		}
+
+		vals, ok := values[name]
+		if !ok || len(vals) == 0 {
+			return match
+		}
+		return vals[n%len(vals)]
+	})
+}
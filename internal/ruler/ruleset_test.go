@@ -0,0 +1,145 @@
+package ruler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidate(t *testing.T) {
+	valid := func() RuleSet {
+		return RuleSet{
+			Namespace:     "benchmarks",
+			Groups:        1,
+			RulesPerGroup: 1,
+			Templates:     []Template{{Record: "r", Expr: `rate({job="a"}[1m])`}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(s *RuleSet)
+		err    string
+	}{
+		{name: "valid", modify: func(*RuleSet) {}},
+		{name: "missing namespace", modify: func(s *RuleSet) { s.Namespace = "" }, err: "rule set requires a namespace"},
+		{name: "no groups", modify: func(s *RuleSet) { s.Groups = 0 }, err: "rule set requires positive groups and rules per group"},
+		{name: "no rules", modify: func(s *RuleSet) { s.RulesPerGroup = 0 }, err: "rule set requires positive groups and rules per group"},
+		{name: "no templates", modify: func(s *RuleSet) { s.Templates = nil }, err: "rule set contains no templates"},
+		{name: "missing expr", modify: func(s *RuleSet) { s.Templates[0].Expr = "" }, err: "template 0: expr is required"},
+		{name: "record and alert", modify: func(s *RuleSet) { s.Templates[0].Alert = "A" }, err: "template 0: exactly one of record and alert is required"},
+		{name: "neither record nor alert", modify: func(s *RuleSet) { s.Templates[0].Record = "" }, err: "template 0: exactly one of record and alert is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.modify(&s)
+
+			err := s.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	s := &RuleSet{
+		Namespace:     "benchmarks",
+		Groups:        2,
+		RulesPerGroup: 2,
+		Interval:      time.Minute,
+		Templates: []Template{
+			{Record: "host:lines:rate1m", Expr: `sum(rate({host="$host"}[1m]))`},
+			{Alert: "HostErrors", Expr: `sum(rate({host="${host}"} |= "error" [1m])) > 1`, For: 5 * time.Minute, Labels: map[string]string{"severity": "info"}},
+		},
+	}
+
+	groups := s.Generate(map[string][]string{"host": {"a", "b", "c"}})
+
+	if len(groups) != 2 || groups[0].Name != "benchmarks-0" || groups[1].Name != "benchmarks-1" {
+		t.Fatalf("got groups %+v", groups)
+	}
+
+	var got []Rule
+	for _, g := range groups {
+		if time.Duration(g.Interval) != time.Minute {
+			t.Errorf("got interval %s of group %s", g.Interval, g.Name)
+		}
+		got = append(got, g.Rules...)
+	}
+
+	want := []Rule{
+		{Record: "host:lines:rate1m_0", Expr: `sum(rate({host="a"}[1m]))`},
+		{Alert: "HostErrors1", Expr: `sum(rate({host="b"} |= "error" [1m])) > 1`, For: model.Duration(5 * time.Minute), Labels: map[string]string{"severity": "info"}},
+		{Record: "host:lines:rate1m_2", Expr: `sum(rate({host="c"}[1m]))`},
+		{Alert: "HostErrors3", Expr: `sum(rate({host="a"} |= "error" [1m])) > 1`, For: model.Duration(5 * time.Minute), Labels: map[string]string{"severity": "info"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got rules %+v, want %+v", got, want)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	values := map[string][]string{"host": {"a", "b"}, "empty": {}}
+
+	tests := []struct {
+		name string
+		expr string
+		n    int
+		want string
+	}{
+		{name: "plain variable", expr: `{host="$host"}`, n: 0, want: `{host="a"}`},
+		{name: "wraps around", expr: `{host="${host}"}`, n: 3, want: `{host="b"}`},
+		{name: "unknown variable", expr: `{host="$other"}`, want: `{host="$other"}`},
+		{name: "variable without values", expr: `{host="$empty"}`, want: `{host="$empty"}`},
+		{name: "regexp anchor", expr: `{host=~"a$"}`, want: `{host=~"a$"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expand(tt.expr, values, tt.n); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleFile(t *testing.T) {
+	s := &RuleSet{
+		Namespace:     "benchmarks",
+		Groups:        1,
+		RulesPerGroup: 1,
+		Templates:     []Template{{Alert: "A", Expr: `1 > 0`, For: time.Minute}},
+	}
+
+	b, err := RuleFile(s.Generate(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var file map[string][]map[string]interface{}
+	if err := yaml.Unmarshal(b, &file); err != nil {
+		t.Fatalf("invalid rule file: %v", err)
+	}
+
+	group := file["groups"][0]
+	rule := group["rules"].([]interface{})[0].(map[string]interface{})
+	if group["name"] != "benchmarks-0" || rule["alert"] != "A0" || rule["for"] != "1m" {
+		t.Errorf("got rule file %s", b)
+	}
+	if _, ok := group["interval"]; ok {
+		t.Errorf("got interval in rule file %s", b)
+	}
+}
@@ -0,0 +1,105 @@
+package ruler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Store loads the rule groups of a namespace into the ruler and removes them
+// again.
+type Store interface {
+	Load(ctx context.Context, namespace string, groups []Group) error
+	Unload(ctx context.Context, namespace string) error
+}
+
+// APIStore loads the rules through the ruler API.
+type APIStore struct {
+	client *loki.Client
+}
+
+func NewAPIStore(client *loki.Client) *APIStore {
+	return &APIStore{client: client}
+}
+
+func (s *APIStore) Load(ctx context.Context, namespace string, groups []Group) error {
+	for _, group := range groups {
+		b, err := yaml.Marshal(group)
+		if err != nil {
+			return fmt.Errorf("failed encoding rule group %s: %w", group.Name, err)
+		}
+
+		if err := s.client.SetRuleGroup(ctx, namespace, b); err != nil {
+			return fmt.Errorf("failed loading rule group %s: %w", group.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *APIStore) Unload(ctx context.Context, namespace string) error {
+	if err := s.client.DeleteRuleNamespace(ctx, namespace); err != nil {
+		return fmt.Errorf("failed deleting rule namespace %s: %w", namespace, err)
+	}
+	return nil
+}
+
+// ConfigMapStore writes the rules as rule file <namespace>.yaml into a
+// ConfigMap, which the ruler mounts into the rules directory of the tenant.
+type ConfigMapStore struct {
+	client    client.Client
+	name      string
+	namespace string
+}
+
+func NewConfigMapStore(c client.Client, name, namespace string) *ConfigMapStore {
+	return &ConfigMapStore{client: c, name: name, namespace: namespace}
+}
+
+func (s *ConfigMapStore) Load(ctx context.Context, namespace string, groups []Group) error {
+	file, err := RuleFile(groups)
+	if err != nil {
+		return err
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.name,
+			Namespace: s.namespace,
+			Labels: map[string]string{
+				"app": "loki-benchmarks-rules",
+			},
+		},
+		Data: map[string]string{
+			namespace + ".yaml": string(file),
+		},
+	}
+
+	err = s.client.Create(ctx, cm)
+	if apierrors.IsAlreadyExists(err) {
+		err = s.client.Update(ctx, cm)
+	}
+	if err != nil {
+		return fmt.Errorf("failed writing rules ConfigMap %s: %w", s.name, err)
+	}
+
+	return nil
+}
+
+func (s *ConfigMapStore) Unload(ctx context.Context, _ string) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: s.name, Namespace: s.namespace},
+	}
+
+	if err := client.IgnoreNotFound(s.client.Delete(ctx, cm)); err != nil {
+		return fmt.Errorf("failed deleting rules ConfigMap %s: %w", s.name, err)
+	}
+	return nil
+}
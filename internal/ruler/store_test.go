@@ -0,0 +1,98 @@
+package ruler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/observatorium/loki-benchmarks/internal/loki"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func testGroups() []Group {
+	s := &RuleSet{
+		Namespace:     "benchmarks",
+		Groups:        2,
+		RulesPerGroup: 1,
+		Templates:     []Template{{Record: "r", Expr: `sum(rate({job="a"}[1m]))`}},
+	}
+	return s.Generate(nil)
+}
+
+func TestAPIStore(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+strings.SplitN(string(body), "\n", 2)[0])
+
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	client, err := loki.NewClient(srv.URL, "tenant", "", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewAPIStore(client)
+	if err := store.Load(context.Background(), "benchmarks", testGroups()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.Unload(context.Background(), "benchmarks"); err != nil {
+		t.Fatalf("unexpected error unloading a missing namespace: %v", err)
+	}
+
+	want := []string{
+		"POST /loki/api/v1/rules/benchmarks name: benchmarks-0",
+		"POST /loki/api/v1/rules/benchmarks name: benchmarks-1",
+		"DELETE /loki/api/v1/rules/benchmarks ",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got requests %q, want %q", requests, want)
+	}
+}
+
+func TestConfigMapStore(t *testing.T) {
+	c := fake.NewClientBuilder().Build()
+	store := NewConfigMapStore(c, "loki-rules", "loki")
+	key := types.NamespacedName{Name: "loki-rules", Namespace: "loki"}
+
+	// Loading twice replaces the rule file.
+	for _, groups := range [][]Group{testGroups(), testGroups()[:1]} {
+		if err := store.Load(context.Background(), "benchmarks", groups); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(context.Background(), key, cm); err != nil {
+		t.Fatal(err)
+	}
+
+	file := cm.Data["benchmarks.yaml"]
+	if !strings.Contains(file, "benchmarks-0") || strings.Contains(file, "benchmarks-1") {
+		t.Errorf("got rule file %s", file)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := store.Unload(context.Background(), "benchmarks"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if err := c.Get(context.Background(), key, cm); !apierrors.IsNotFound(err) {
+		t.Errorf("got %v, want the ConfigMap deleted", err)
+	}
+}
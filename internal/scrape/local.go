@@ -0,0 +1,257 @@
+package scrape
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// target is a scrape job of the Prometheus configuration template. The
+// placeholder is replaced with the port-forwards to the pods matching the
+// selector.
+type target struct {
+	name        string
+	selector    string
+	placeholder string
+	port        int
+}
+
+// microservicesTargets scrape one job per Loki component.
+var microservicesTargets = []target{
+	{"loki query frontend", "app.kubernetes.io/component=query-frontend", "LOKI_QUERY_FRONTEND_TARGETS", 3100},
+	{"loki distributor", "app.kubernetes.io/component=distributor", "LOKI_DISTRIBUTOR_TARGETS", 3100},
+	{"loki ingester", "app.kubernetes.io/component=ingester", "LOKI_INGESTER_TARGETS", 3100},
+	{"loki querier", "app.kubernetes.io/component=querier", "LOKI_QUERIER_TARGETS", 3100},
+	{"loki query scheduler", "app.kubernetes.io/component=query-scheduler", "LOKI_QUERY_SCHEDULER_TARGETS", 3100},
+	{"loki compactor", "app.kubernetes.io/component=compactor", "LOKI_COMPACTOR_TARGETS", 3100},
+	{"loki ruler", "app.kubernetes.io/component=ruler", "LOKI_RULER_TARGETS", 3100},
+}
+
+// simpleScalableTargets scrape the read, write and backend targets the
+// components are mapped to by config.Metrics.ResolveJobs.
+var simpleScalableTargets = []target{
+	{"loki read", "app.kubernetes.io/component=read", "LOKI_READ_TARGETS", 3100},
+	{"loki write", "app.kubernetes.io/component=write", "LOKI_WRITE_TARGETS", 3100},
+	{"loki backend", "app.kubernetes.io/component=backend", "LOKI_BACKEND_TARGETS", 3100},
+}
+
+// monolithicTargets scrape the single binary running every component.
+var monolithicTargets = []target{
+	{"loki single binary", "app.kubernetes.io/component=single-binary", "LOKI_SINGLE_BINARY_TARGETS", 3100},
+}
+
+var cadvisorTarget = target{"cadvisor ingesters", "", "CADVISOR_INGESTERS_TARGETS", 8080}
+
+// targets returns the scrape jobs of the deployment mode.
+func targets(mode string) ([]target, error) {
+	var ts []target
+	switch mode {
+	case "", config.DeploymentModeMicroservices:
+		ts = microservicesTargets
+	case config.DeploymentModeSimpleScalable:
+		ts = simpleScalableTargets
+	case config.DeploymentModeMonolithic:
+		ts = monolithicTargets
+	default:
+		return nil, fmt.Errorf("unsupported deployment mode: %s", mode)
+	}
+
+	return append(append([]target{}, ts...), cadvisorTarget), nil
+}
+
+// emptyTargets returns an empty value for the placeholders of every mode,
+// so the jobs of the other modes render without targets.
+func emptyTargets() map[string]string {
+	values := map[string]string{}
+	for _, ts := range [][]target{microservicesTargets, simpleScalableTargets, monolithicTargets, {cadvisorTarget}} {
+		for _, t := range ts {
+			values[t.placeholder] = ""
+		}
+	}
+
+	return values
+}
+
+const (
+	ingesterSelector       = "app.kubernetes.io/component=ingester"
+	ingesterContainer      = "observatorium-loki-ingester"
+	ingesterPodPlaceholder = "CADVISOR_INGESTERS_TARGETS_PODS"
+)
+
+// LocalOptions configure a Prometheus running next to the benchmarks.
+type LocalOptions struct {
+	Namespace        string
+	PrometheusBinary string
+	TemplateFile     string
+	ConfigFile       string
+
+	// DeploymentMode selects the scrape jobs, by default one per component.
+	DeploymentMode string
+}
+
+// Local scrapes the Loki components in a namespace with a local Prometheus
+// through port-forwards to every pod.
+type Local struct {
+	processes []*exec.Cmd
+	tsdbDir   string
+}
+
+// StartLocal forwards the ports of the Loki components, renders the
+// Prometheus configuration and starts Prometheus. The processes run until
+// Stop or until ctx is done.
+func StartLocal(ctx context.Context, c client.Client, opts LocalOptions) (*Local, error) {
+	l := &Local{}
+
+	values, err := l.forwardPorts(ctx, c, opts.Namespace, opts.DeploymentMode)
+	if err != nil {
+		l.Stop()
+		return nil, err
+	}
+
+	values[ingesterPodPlaceholder], err = ingesterContainerRegex(ctx, c, opts.Namespace)
+	if err != nil {
+		l.Stop()
+		return nil, err
+	}
+
+	tmpl, err := os.ReadFile(opts.TemplateFile)
+	if err != nil {
+		l.Stop()
+		return nil, fmt.Errorf("failed reading prometheus configuration template: %w", err)
+	}
+
+	if err := os.WriteFile(opts.ConfigFile, RenderConfig(tmpl, values), 0o644); err != nil {
+		l.Stop()
+		return nil, fmt.Errorf("failed writing prometheus configuration: %w", err)
+	}
+
+	l.tsdbDir, err = os.MkdirTemp("", "loki-benchmarks-prometheus")
+	if err != nil {
+		l.Stop()
+		return nil, fmt.Errorf("failed creating prometheus storage: %w", err)
+	}
+
+	err = l.start(ctx, opts.PrometheusBinary, "--log.level=warn", "--config.file="+opts.ConfigFile, "--storage.tsdb.path="+l.tsdbDir)
+	if err != nil {
+		l.Stop()
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Stop stops Prometheus and the port-forwards.
+func (l *Local) Stop() {
+	for _, cmd := range l.processes {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}
+	l.processes = nil
+
+	if l.tsdbDir != "" {
+		_ = os.RemoveAll(l.tsdbDir)
+	}
+}
+
+// forwardPorts forwards a local port to the port of every pod of every
+// target of the mode and returns the local addresses per placeholder.
+func (l *Local) forwardPorts(ctx context.Context, c client.Client, namespace, mode string) (map[string]string, error) {
+	ts, err := targets(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	values := emptyTargets()
+
+	counter := 0
+	for _, t := range ts {
+		selector, err := labels.Parse(t.selector)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing selector of %s: %w", t.name, err)
+		}
+
+		pods := &corev1.PodList{}
+		err = c.List(ctx, pods, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector})
+		if err != nil {
+			return nil, fmt.Errorf("failed listing %s pods: %w", t.name, err)
+		}
+
+		addrs := make([]string, 0, len(pods.Items))
+		for _, pod := range pods.Items {
+			local := t.port + counter
+			counter++
+
+			log.Printf("forwarding port %d to %s pod %s", local, t.name, pod.Name)
+			err := l.start(ctx, "kubectl", "-n", namespace, "port-forward", "pod/"+pod.Name, fmt.Sprintf("%d:%d", local, t.port))
+			if err != nil {
+				return nil, err
+			}
+
+			addrs = append(addrs, fmt.Sprintf("'localhost:%d'", local))
+		}
+
+		values[t.placeholder] = strings.Join(addrs, ",")
+	}
+
+	return values, nil
+}
+
+func (l *Local) start(ctx context.Context, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed starting %s: %w", name, err)
+	}
+
+	l.processes = append(l.processes, cmd)
+	return nil
+}
+
+// ingesterContainerRegex matches the cAdvisor cgroup ids of the ingester
+// containers.
+func ingesterContainerRegex(ctx context.Context, c client.Client, namespace string) (string, error) {
+	selector, err := labels.Parse(ingesterSelector)
+	if err != nil {
+		return "", err
+	}
+
+	pods := &corev1.PodList{}
+	err = c.List(ctx, pods, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return "", fmt.Errorf("failed listing ingester pods: %w", err)
+	}
+
+	var ids []string
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != ingesterContainer {
+				continue
+			}
+			ids = append(ids, fmt.Sprintf(".*crio-%s.*", strings.TrimPrefix(status.ContainerID, "cri-o://")))
+		}
+	}
+
+	return strings.Join(ids, "|"), nil
+}
+
+// RenderConfig replaces every {{placeholder}} of the template with its
+// value.
+func RenderConfig(tmpl []byte, values map[string]string) []byte {
+	pairs := make([]string, 0, 2*len(values))
+	for placeholder, value := range values {
+		pairs = append(pairs, "{{"+placeholder+"}}", value)
+	}
+
+	return []byte(strings.NewReplacer(pairs...).Replace(string(tmpl)))
+}
@@ -0,0 +1,143 @@
+package scrape
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/observatorium/loki-benchmarks/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestRenderConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		tmpl   string
+		values map[string]string
+		want   string
+	}{
+		{
+			name:   "single placeholder",
+			tmpl:   "targets: [{{LOKI_QUERIER_TARGETS}}]",
+			values: map[string]string{"LOKI_QUERIER_TARGETS": "'localhost:3100'"},
+			want:   "targets: ['localhost:3100']",
+		},
+		{
+			name:   "repeated placeholder",
+			tmpl:   "{{A}} {{A}}",
+			values: map[string]string{"A": "x"},
+			want:   "x x",
+		},
+		{
+			name:   "placeholder prefixing another",
+			tmpl:   "{{CADVISOR_INGESTERS_TARGETS}} {{CADVISOR_INGESTERS_TARGETS_PODS}}",
+			values: map[string]string{"CADVISOR_INGESTERS_TARGETS": "'localhost:8080'", "CADVISOR_INGESTERS_TARGETS_PODS": "ingester-0|ingester-1"},
+			want:   "'localhost:8080' ingester-0|ingester-1",
+		},
+		{
+			name:   "empty value",
+			tmpl:   "targets: [{{LOKI_RULER_TARGETS}}]",
+			values: map[string]string{"LOKI_RULER_TARGETS": ""},
+			want:   "targets: []",
+		},
+		{
+			name:   "unknown placeholder",
+			tmpl:   "targets: [{{UNKNOWN}}]",
+			values: map[string]string{"LOKI_QUERIER_TARGETS": "'localhost:3100'"},
+			want:   "targets: [{{UNKNOWN}}]",
+		},
+		{
+			name: "without values",
+			tmpl: "targets: [{{LOKI_QUERIER_TARGETS}}]",
+			want: "targets: [{{LOKI_QUERIER_TARGETS}}]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(RenderConfig([]byte(tt.tmpl), tt.values))
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	tmpl, err := os.ReadFile("../../config/prometheus/config.template")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		mode string
+		want []string
+	}{
+		{
+			name: "microservices mode",
+			mode: config.DeploymentModeMicroservices,
+			want: []string{"loki-query-frontend", "loki-distributor", "loki-ingester", "loki-querier", "loki-query-scheduler", "loki-compactor", "loki-ruler", "cadvisor_ingesters"},
+		},
+		{
+			name: "simple scalable mode",
+			mode: config.DeploymentModeSimpleScalable,
+			want: []string{"loki-read", "loki-write", "loki-backend", "cadvisor_ingesters"},
+		},
+		{
+			name: "monolithic mode",
+			mode: config.DeploymentModeMonolithic,
+			want: []string{"loki-single-binary", "cadvisor_ingesters"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, err := targets(tt.mode)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			values := emptyTargets()
+			for _, target := range ts {
+				values[target.placeholder] = "'localhost:3100'"
+			}
+			values[ingesterPodPlaceholder] = ".*crio-0.*"
+
+			out := RenderConfig(tmpl, values)
+			if strings.Contains(string(out), "{{") {
+				t.Fatalf("placeholders left in\n%s", out)
+			}
+
+			rendered := struct {
+				ScrapeConfigs []struct {
+					JobName       string `yaml:"job_name"`
+					StaticConfigs []struct {
+						Targets []string `yaml:"targets"`
+					} `yaml:"static_configs"`
+				} `yaml:"scrape_configs"`
+			}{}
+			if err := yaml.Unmarshal(out, &rendered); err != nil {
+				t.Fatalf("invalid configuration: %v", err)
+			}
+
+			var got []string
+			for _, sc := range rendered.ScrapeConfigs {
+				if len(sc.StaticConfigs) > 0 && len(sc.StaticConfigs[0].Targets) > 0 {
+					got = append(got, sc.JobName)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got jobs with targets %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetsUnsupportedMode(t *testing.T) {
+	if _, err := targets("distributed"); err == nil || err.Error() != "unsupported deployment mode: distributed" {
+		t.Errorf("got error %v", err)
+	}
+}
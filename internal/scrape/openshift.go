@@ -0,0 +1,52 @@
+package scrape
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	thanosQuerierNamespace = "openshift-monitoring"
+	thanosQuerierRoute     = "thanos-querier"
+
+	userWorkloadNamespace   = "openshift-user-workload-monitoring"
+	userWorkloadTokenPrefix = "prometheus-user-workload-token"
+)
+
+var routeGVK = schema.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"}
+
+// OpenShift returns the URL of the OpenShift Thanos querier and the bearer
+// token of the user workload Prometheus to query it with.
+func OpenShift(ctx context.Context, c client.Client) (url, token string, err error) {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(routeGVK)
+
+	key := client.ObjectKey{Name: thanosQuerierRoute, Namespace: thanosQuerierNamespace}
+	if err := c.Get(ctx, key, route); err != nil {
+		return "", "", fmt.Errorf("failed getting thanos querier route: %w", err)
+	}
+
+	host, _, err := unstructured.NestedString(route.Object, "spec", "host")
+	if err != nil || host == "" {
+		return "", "", fmt.Errorf("thanos querier route has no host")
+	}
+
+	secrets := &corev1.SecretList{}
+	if err := c.List(ctx, secrets, client.InNamespace(userWorkloadNamespace)); err != nil {
+		return "", "", fmt.Errorf("failed listing user workload monitoring secrets: %w", err)
+	}
+
+	for _, secret := range secrets.Items {
+		if strings.HasPrefix(secret.Name, userWorkloadTokenPrefix) {
+			return "https://" + host, string(secret.Data[corev1.ServiceAccountTokenKey]), nil
+		}
+	}
+
+	return "", "", fmt.Errorf("no %s secret found", userWorkloadTokenPrefix)
+}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RestartPods deletes the pods matching the labels and waits until the same
+// number of new pods is ready, e.g. to flush the memcached caches of Loki.
+func RestartPods(c client.Client, namespace string, labels map[string]string, retry, timeout time.Duration) error {
+	opts := []client.ListOption{client.InNamespace(namespace), client.MatchingLabels(labels)}
+
+	pods := &corev1.PodList{}
+	if err := c.List(context.TODO(), pods, opts...); err != nil {
+		return err
+	}
+	if len(pods.Items) == 0 {
+		return fmt.Errorf("no pods found in %s matching %v", namespace, labels)
+	}
+
+	deleted := map[types.UID]bool{}
+	for i := range pods.Items {
+		if err := c.Delete(context.TODO(), &pods.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		deleted[pods.Items[i].UID] = true
+	}
+
+	return wait.Poll(retry, timeout, func() (done bool, err error) {
+		current := &corev1.PodList{}
+		if err := c.List(context.TODO(), current, opts...); err != nil {
+			return false, err
+		}
+
+		ready := 0
+		for _, pod := range current.Items {
+			if deleted[pod.UID] {
+				return false, nil
+			}
+			if IsPodReady(&pod) {
+				ready++
+			}
+		}
+
+		return ready >= len(deleted), nil
+	})
+}
+
+// restartedAtAnnotation is the pod template annotation kubectl rollout
+// restart sets.
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// RolloutRestart replaces the pods of the Deployment or StatefulSet one by
+// one like kubectl rollout restart, by annotating the pod template. It does
+// not wait for the rollout.
+func RolloutRestart(c client.Client, o client.Object) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := c.Get(context.TODO(), client.ObjectKeyFromObject(o), o); err != nil {
+			return err
+		}
+
+		var template *corev1.PodTemplateSpec
+		switch obj := o.(type) {
+		case *appsv1.Deployment:
+			template = &obj.Spec.Template
+		case *appsv1.StatefulSet:
+			template = &obj.Spec.Template
+		default:
+			return fmt.Errorf("unsupported object %T", o)
+		}
+
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[restartedAtAnnotation] = time.Now().Format(time.RFC3339)
+
+		return c.Update(context.TODO(), o)
+	})
+}
+
+// ScaleDeployment sets the replicas of the Deployment and waits until they
+// are ready, or until all pods are gone when scaled to zero.
+func ScaleDeployment(c client.Client, o client.Object, replicas int32, retryInterval, timeout time.Duration) error {
+	dpl := &appsv1.Deployment{}
+	key := client.ObjectKeyFromObject(o)
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := c.Get(context.TODO(), key, dpl); err != nil {
+			return err
+		}
+
+		dpl.Spec.Replicas = &replicas
+		return c.Update(context.TODO(), dpl)
+	})
+	if err != nil {
+		return err
+	}
+
+	if replicas > 0 {
+		return WaitForReadyDeployment(c, dpl, retryInterval, timeout)
+	}
+
+	return wait.Poll(retryInterval, timeout, func() (done bool, err error) {
+		if err := c.Get(context.TODO(), key, dpl); err != nil {
+			return false, err
+		}
+		return dpl.Status.Replicas == 0, nil
+	})
+}
+
+// IsPodReady reports whether the pod has the Ready condition.
+func IsPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
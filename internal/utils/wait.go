@@ -2,21 +2,30 @@ package utils
 
 import (
 	"context"
+	"fmt"
 	"time"
 
-	appsv1 "k8s.io/api/apps/v1"
+	"github.com/observatorium/loki-benchmarks/internal/readiness"
+
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// WaitForReadyDeployment waits until the Deployment or StatefulSet has all
+// replicas updated and available, or ready respectively.
 func WaitForReadyDeployment(c client.Client, o client.Object, retry, timeout time.Duration) error {
+	return readiness.NewWaiter(c, retry, timeout, nil).Wait(context.TODO(), o)
+}
+
+func WaitForCompletedJob(c client.Client, o client.Object, retry, timeout time.Duration) error {
 	return wait.Poll(retry, timeout, func() (done bool, err error) {
-		dpl := &appsv1.Deployment{}
+		job := &batchv1.Job{}
 		key := client.ObjectKeyFromObject(o)
 
-		err = c.Get(context.TODO(), key, dpl)
+		err = c.Get(context.TODO(), key, job)
 		if err != nil {
 			if errors.IsNotFound(err) {
 				return false, nil
@@ -24,12 +33,31 @@ func WaitForReadyDeployment(c client.Client, o client.Object, retry, timeout tim
 			return false, err
 		}
 
-		for _, condition := range dpl.Status.Conditions {
-			if condition.Type == appsv1.DeploymentAvailable {
-				return condition.Status == corev1.ConditionTrue, nil
+		for _, condition := range job.Status.Conditions {
+			if condition.Status != corev1.ConditionTrue {
+				continue
+			}
+
+			switch condition.Type {
+			case batchv1.JobComplete:
+				return true, nil
+			case batchv1.JobFailed:
+				return false, fmt.Errorf("job %s failed: %s", key, condition.Message)
 			}
 		}
 
 		return false, nil
 	})
 }
+
+// WaitForDeletedObject waits until the object is gone, e.g. until all pods
+// of a deployment deleted in the foreground terminated.
+func WaitForDeletedObject(c client.Client, o client.Object, retry, timeout time.Duration) error {
+	return wait.Poll(retry, timeout, func() (done bool, err error) {
+		err = c.Get(context.TODO(), client.ObjectKeyFromObject(o), o)
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+}